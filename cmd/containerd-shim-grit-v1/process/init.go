@@ -202,8 +202,9 @@ func (p *Init) createCheckpointedState(r *CreateConfig, pidFile *pidFile) error
 	}
 
 	p.initState = &createdCheckpointState{
-		p:    p,
-		opts: opts,
+		p:           p,
+		opts:        opts,
+		traceParent: r.TraceParent,
 	}
 	return nil
 }
@@ -32,8 +32,13 @@ import (
 	google_protobuf "github.com/containerd/containerd/v2/pkg/protobuf/types"
 	runc "github.com/containerd/go-runc"
 	"github.com/containerd/log"
+	"go.opentelemetry.io/otel"
+
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
+var tracer = otel.Tracer("github.com/kaito-project/grit/cmd/containerd-shim-grit-v1/process")
+
 type initState interface {
 	Start(context.Context) error
 	Delete(context.Context) error
@@ -116,8 +121,9 @@ func (s *createdState) Status(ctx context.Context) (string, error) {
 }
 
 type createdCheckpointState struct {
-	p    *Init
-	opts *runc.RestoreOpts
+	p           *Init
+	opts        *runc.RestoreOpts
+	traceParent string
 }
 
 func (s *createdCheckpointState) transition(name string) error {
@@ -150,7 +156,11 @@ func (s *createdCheckpointState) Checkpoint(ctx context.Context, r *CheckpointCo
 	return errors.New("cannot checkpoint a task in created state")
 }
 
-func (s *createdCheckpointState) Start(ctx context.Context) error {
+func (s *createdCheckpointState) Start(ctx context.Context) (retErr error) {
+	// spans of restoring container are added into the trace of Restore.
+	ctx, span := tracer.Start(tracing.ContextWithTraceParent(ctx, s.traceParent), "restore container")
+	defer func() { tracing.EndSpan(span, retErr) }()
+
 	p := s.p
 	sio := p.stdio
 
@@ -222,6 +232,9 @@ func (s *createdCheckpointState) Start(ctx context.Context) error {
 		}
 	}
 
+	// kubelet and sandbox paths in checkpoint are remapped to the paths of the new pod
+	_, remapSpan := tracer.Start(ctx, "remap mount paths")
+
 	// Find the mountpoints image file (suffix varies, e.g., mountpoints-12.img, mountpoints-13.img)
 	var mountpointsFile string
 	if files, err := os.ReadDir(checkpointPath); err == nil {
@@ -427,6 +440,7 @@ func (s *createdCheckpointState) Start(ctx context.Context) error {
 	} else {
 		log.G(ctx).Warnf("GPU restore: crit decode failed or no new pod UID: %v", critErr)
 	}
+	remapSpan.End()
 
 	// Write CRIU config file
 	criuConfigPath := p.Bundle + "/criu-gpu.conf"
@@ -482,7 +496,9 @@ mntns-compat-mode
 	cmd := exec.CommandContext(ctx, "/usr/bin/runc", runcArgs...)
 	cmd.Dir = p.Bundle
 
+	_, restoreSpan := tracer.Start(ctx, "runc restore")
 	output, err := cmd.CombinedOutput()
+	tracing.EndSpan(restoreSpan, err)
 	if err != nil {
 		log.G(ctx).Errorf("GPU restore: runc restore failed: %v, output: %s", err, string(output))
 		return p.runtimeError(err, "OCI runtime restore failed")
@@ -41,6 +41,8 @@ type CreateConfig struct {
 	Checkpoint       string
 	ParentCheckpoint string
 	Options          *google_protobuf.Any
+	// TraceParent is the W3C traceparent of Restore, spans of restoring container are added into its trace.
+	TraceParent string
 }
 
 // ExecConfig holds exec creation configuration
@@ -2,13 +2,23 @@ package runc
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path"
+	"slices"
+	"strings"
 
 	crmetadata "github.com/checkpoint-restore/checkpointctl/lib"
+
+	"github.com/kaito-project/grit/pkg/metadata"
 )
 
 type CheckpointOpts struct {
+	// $CheckpointDataDir/
+	// ├── manifest.json
+	// ├── download-state
+	// └── $ContainerName/
+	CheckpointDataDir string
 	// $CheckpointBaseDir/
 	// ├── checkpoint/
 	// │   ├── pages-1.img
@@ -17,6 +27,8 @@ type CheckpointOpts struct {
 	// ├── config.dump
 	// └── spec.dump
 	CheckpointBaseDir string
+	// TraceParent is the W3C traceparent of Restore, spans of restoring container are added into its trace.
+	TraceParent string
 }
 
 func (c *CheckpointOpts) GetCheckpointPath() string {
@@ -27,10 +39,55 @@ func (c *CheckpointOpts) GetRootFsDiffTar() string {
 	return path.Join(c.CheckpointBaseDir, crmetadata.RootFsDiffTar)
 }
 
+// ValidateDownloadState checks that checkpointed data has been downloaded and verified against the integrity manifest
+// by grit agent, restoring from partially copied or corrupted data would crash criu in the middle of restoration.
+func (c *CheckpointOpts) ValidateDownloadState() error {
+	return metadata.ValidateDownloadState(c.CheckpointDataDir)
+}
+
+// ValidateParentImages checks the chain of parent images for incremental checkpoint. criu links images dir to the
+// images dir of parent checkpoint with a symlink named parent, and restore will fail if any parent images are missing.
+func (c *CheckpointOpts) ValidateParentImages() error {
+	imagesDir := c.GetCheckpointPath()
+	for depth := 0; ; depth++ {
+		if depth > maxParentImagesDepth {
+			return fmt.Errorf("parent images chain of %s is too long", c.GetCheckpointPath())
+		}
+
+		parentLink := path.Join(imagesDir, parentImagesLink)
+		target, err := os.Readlink(parentLink)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to read parent images link %s: %w", parentLink, err)
+		}
+
+		if !path.IsAbs(target) {
+			target = path.Join(imagesDir, target)
+		}
+		if _, err := os.Stat(target); err != nil {
+			return fmt.Errorf("parent images %s of %s is not available: %w", target, imagesDir, err)
+		}
+		imagesDir = target
+	}
+}
+
+const (
+	// parentImagesLink is the name of symlink created by criu for linking to parent images dir.
+	parentImagesLink = "parent"
+	// maxParentImagesDepth is used for preventing endless loop of parent images chain.
+	maxParentImagesDepth = 128
+)
+
 const (
 	AnnotationGRITCheckpoint = "grit.dev/checkpoint"
 	AnnotationContainerType  = "io.kubernetes.cri.container-type"
 	AnnotationContainerName  = "io.kubernetes.cri.container-name"
+	// AnnotationGRITCheckpointContainers is the checkpointed containers joined with comma, other containers of the
+	// restoration pod are started normally.
+	AnnotationGRITCheckpointContainers = "grit.dev/checkpoint-containers"
+	// AnnotationGRITTraceParent is the W3C traceparent of Restore, it's set on restoration pod by grit manager.
+	AnnotationGRITTraceParent = "grit.dev/trace-parent"
 )
 
 // spec is a shallow version of [oci.Spec] containing only the
@@ -72,7 +129,21 @@ func ReadCheckpointOpts(bundle string) (*CheckpointOpts, error) {
 		return nil, nil
 	}
 	containerName := s.Annotations[AnnotationContainerName]
+	if !isCheckpointedContainer(s.Annotations[AnnotationGRITCheckpointContainers], containerName) {
+		return nil, nil
+	}
 	return &CheckpointOpts{
+		CheckpointDataDir: checkpointPath,
 		CheckpointBaseDir: path.Join(checkpointPath, containerName),
+		TraceParent:       s.Annotations[AnnotationGRITTraceParent],
 	}, nil
 }
+
+// isCheckpointedContainer returns whether the container is in the checkpointed containers list, and empty list means
+// all containers are checkpointed.
+func isCheckpointedContainer(containers, containerName string) bool {
+	if containers == "" {
+		return true
+	}
+	return slices.Contains(strings.Split(containers, ","), containerName)
+}
@@ -43,10 +43,15 @@ import (
 	"github.com/containerd/log"
 	"github.com/containerd/typeurl/v2"
 	"github.com/pelletier/go-toml/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/kaito-project/grit/cmd/containerd-shim-grit-v1/process"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
+var tracer = otel.Tracer("github.com/kaito-project/grit/cmd/containerd-shim-grit-v1/runc")
+
 // NewContainer returns a new runc container
 func NewContainer(ctx context.Context, platform stdio.Platform, r *task.CreateTaskRequest) (_ *Container, retErr error) {
 	ns, err := namespaces.NamespaceRequired(ctx)
@@ -62,8 +67,21 @@ func NewContainer(ctx context.Context, platform stdio.Platform, r *task.CreateTa
 
 	ckptOpts, err := ReadCheckpointOpts(r.Bundle)
 	if ckptOpts != nil {
+		// spans of restoring container are added into the trace of Restore.
+		var span trace.Span
+		ctx, span = tracer.Start(tracing.ContextWithTraceParent(ctx, ckptOpts.TraceParent), "create restored container")
+		defer func() { tracing.EndSpan(span, retErr) }()
+
 		checkpointPath := ckptOpts.GetCheckpointPath()
 		if _, err := os.Stat(checkpointPath); err == nil {
+			if err := ckptOpts.ValidateDownloadState(); err != nil {
+				log.G(ctx).WithError(err).Error("unverified checkpointed data")
+				return nil, fmt.Errorf("refuse to restore from unverified checkpoint %s: %w", checkpointPath, err)
+			}
+			if err := ckptOpts.ValidateParentImages(); err != nil {
+				log.G(ctx).WithError(err).Error("invalid parent images of incremental checkpoint")
+				return nil, fmt.Errorf("invalid parent images of incremental checkpoint %s: %w", checkpointPath, err)
+			}
 			r.Checkpoint = checkpointPath
 		} else if os.IsNotExist(err) {
 			log.G(ctx).Warnf("Checkpoint path %s does not exist, skip restoration", r.Checkpoint)
@@ -107,6 +125,9 @@ func NewContainer(ctx context.Context, platform stdio.Platform, r *task.CreateTa
 		ParentCheckpoint: r.ParentCheckpoint,
 		Options:          r.Options,
 	}
+	if ckptOpts != nil {
+		config.TraceParent = ckptOpts.TraceParent
+	}
 
 	if err := WriteOptions(r.Bundle, opts); err != nil {
 		return nil, err
@@ -143,29 +164,8 @@ func NewContainer(ctx context.Context, platform stdio.Platform, r *task.CreateTa
 
 		_, err = os.Stat(rootfsDiff)
 		if err == nil {
-			rootfsDiffTar, err := os.Open(rootfsDiff)
-			if err != nil {
-				return nil, fmt.Errorf("failed to open rootfs-diff archive %s for import: %w", rootfsDiffTar.Name(), err)
-			}
-			defer func(f *os.File) {
-				if err := f.Close(); err != nil {
-					log.G(ctx).Errorf("Unable to close file %s: %q", f.Name(), err)
-				}
-			}(rootfsDiffTar)
-
-			decompressed, err := compression.DecompressStream(rootfsDiffTar)
-			if err != nil {
-				return nil, fmt.Errorf("failed to decompress archive %s for import: %w", rootfsDiffTar.Name(), err)
-			}
-
-			_, err = archive.Apply(
-				ctx,
-				rootfs,
-				decompressed,
-			)
-
-			if err != nil {
-				return nil, fmt.Errorf("unpacking of rootfs-diff archive %s into %s failed: %w", rootfsDiffTar.Name(), rootfs, err)
+			if err := applyRootFsDiff(ctx, rootfsDiff, rootfs); err != nil {
+				return nil, err
 			}
 			log.G(ctx).Debugf("Unpacked checkpoint in %s", rootfs)
 		}
@@ -203,6 +203,32 @@ func NewContainer(ctx context.Context, platform stdio.Platform, r *task.CreateTa
 	return container, nil
 }
 
+// applyRootFsDiff unpacks rootfs-diff archive of checkpointed container into rootfs, it needs to happen before 'Start()'.
+func applyRootFsDiff(ctx context.Context, rootfsDiff, rootfs string) (retErr error) {
+	ctx, span := tracer.Start(ctx, "apply rootfs diff")
+	defer func() { tracing.EndSpan(span, retErr) }()
+
+	rootfsDiffTar, err := os.Open(rootfsDiff)
+	if err != nil {
+		return fmt.Errorf("failed to open rootfs-diff archive %s for import: %w", rootfsDiff, err)
+	}
+	defer func(f *os.File) {
+		if err := f.Close(); err != nil {
+			log.G(ctx).Errorf("Unable to close file %s: %q", f.Name(), err)
+		}
+	}(rootfsDiffTar)
+
+	decompressed, err := compression.DecompressStream(rootfsDiffTar)
+	if err != nil {
+		return fmt.Errorf("failed to decompress archive %s for import: %w", rootfsDiffTar.Name(), err)
+	}
+
+	if _, err := archive.Apply(ctx, rootfs, decompressed); err != nil {
+		return fmt.Errorf("unpacking of rootfs-diff archive %s into %s failed: %w", rootfsDiffTar.Name(), rootfs, err)
+	}
+	return nil
+}
+
 func readRuntimeOptions(r *task.CreateTaskRequest) (*options.Options, error) {
 	opts := &options.Options{}
 	if r.Options == nil {
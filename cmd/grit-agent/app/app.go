@@ -9,6 +9,9 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/client-go/kubernetes/scheme"
 	cliflag "k8s.io/component-base/cli/flag"
 	"k8s.io/component-base/cli/globalflag"
@@ -19,10 +22,17 @@ import (
 	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
 	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
 	"github.com/kaito-project/grit/pkg/gritagent/checkpoint"
+	"github.com/kaito-project/grit/pkg/gritagent/cleanup"
+	"github.com/kaito-project/grit/pkg/gritagent/inspect"
 	"github.com/kaito-project/grit/pkg/gritagent/restore"
 	"github.com/kaito-project/grit/pkg/injections"
+	"github.com/kaito-project/grit/pkg/metadata"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
+// TracerName is the name of tracer for spans of grit agent.
+const TracerName = "github.com/kaito-project/grit/pkg/gritagent"
+
 func init() {
 	v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
 }
@@ -38,6 +48,11 @@ func NewGritAgentCommand() *cobra.Command {
 
 			if err := Run(opts); err != nil {
 				fmt.Fprintf(os.Stderr, "run grit-agent failed: %v\n", err)
+				// grit agent job is failed at once by this exit code, because retrying can't recover a permanent error.
+				if metadata.IsPermanent(err) {
+					klog.Flush()
+					os.Exit(metadata.PermanentFailureExitCode)
+				}
 				return err
 			}
 			return nil
@@ -64,9 +79,27 @@ func Run(opts *options.GritAgentOptions) error {
 		handler = checkpoint.RunCheckpoint
 	case options.ActionRestore:
 		handler = restore.RunRestore
+	case options.ActionCleanup:
+		handler = cleanup.RunCleanup
+	case options.ActionInspect:
+		handler = inspect.RunInspect
+	case options.ActionRestoreLog:
+		handler = inspect.RunRestoreLog
 	default:
 		return fmt.Errorf("unknown action %s", opts.Action)
 	}
 
-	return handler(ctx, opts)
+	// grit manager passes the OTLP endpoint and the traceparent of checkpoint or restore through env vars, so spans of
+	// grit agent are added into the trace of checkpoint or restore.
+	shutdownTracing, err := tracing.Setup(ctx, "grit-agent", os.Getenv(tracing.EndpointEnv))
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing, %w", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	ctx, span := otel.Tracer(TracerName).Start(tracing.ContextWithTraceParent(ctx, os.Getenv(tracing.TraceParentEnv)), "grit-agent "+opts.Action,
+		trace.WithAttributes(attribute.String("grit.pod", opts.TargetPodNamespace+"/"+opts.TargetPodName)))
+	err = handler(ctx, opts)
+	tracing.EndSpan(span, err)
+	return err
 }
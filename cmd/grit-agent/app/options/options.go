@@ -5,8 +5,11 @@ package options
 
 import (
 	"os"
+	"time"
 
 	"github.com/spf13/pflag"
+
+	"github.com/kaito-project/grit/pkg/metadata"
 )
 
 type GritAgentOptions struct {
@@ -16,10 +19,41 @@ type GritAgentOptions struct {
 	Action          string
 	SrcDir          string
 	DstDir          string
+	// CompareSrcDir is the storage directory of another checkpoint of the same pod, inspect action prints the changes
+	// from that checkpoint instead of the report of src-dir.
+	CompareSrcDir string
+	// GroupMembers are all member checkpoints of checkpoint group, and the agent waits for all members frozen before dumping.
+	GroupMembers       []string
+	GroupFreezeTimeout time.Duration
+	// Deadline is a RFC3339 time for emergency checkpoint, grit agent stops and reports DeadlineExceeded at the deadline.
+	Deadline string
+	// TerminationMessagePath is the file where grit agent writes its report for grit manager.
+	TerminationMessagePath string
 
+	StorageOptions
 	RuntimeCheckpointOptions
 }
 
+// StorageOptions is used for accessing storage of checkpointed data. for pvc storage, the storage volume is mounted into
+// agent container. for s3 storage, src-dir or dst-dir is the common prefix of object keys in the bucket. for registry
+// storage, src-dir or dst-dir is the repository of checkpoint artifact.
+type StorageOptions struct {
+	StorageType      string
+	S3Endpoint       string
+	S3Bucket         string
+	S3Region         string
+	S3Insecure       bool
+	RegistryInsecure bool
+	RegistryConfig   string
+	// CompressionLevel is the zstd level for packing checkpointed data into a compressed stream, 0 means compression is disabled.
+	CompressionLevel       int
+	CompressionConcurrency int
+	// EncryptionKeyDir contains AES-256 keys named by key id, and EncryptionKeyID is the key for encrypting checkpointed
+	// data. encrypted data records its key id, so only EncryptionKeyDir is needed for decrypting.
+	EncryptionKeyDir string
+	EncryptionKeyID  string
+}
+
 type RuntimeCheckpointOptions struct {
 	TargetPodNamespace string
 	TargetPodName      string
@@ -27,18 +61,39 @@ type RuntimeCheckpointOptions struct {
 	RuntimeEndpoint    string
 	KubeletLogPath     string
 	HostWorkPath       string
+	// ParentCheckpoints is the chain of parent checkpoints for incremental checkpoint, from the direct parent to the first full checkpoint.
+	// checkpointed data of parent checkpoint is stored in the sibling directory of host work path, src-dir and dst-dir.
+	ParentCheckpoints []string
+	// Containers is the names of containers for checkpointing, all running containers of the pod are checkpointed if it's empty.
+	Containers []string
+	// LeaveRunning resumes processes of containers after they're dumped, so the workload keeps running after checkpoint.
+	LeaveRunning bool
 }
 
 const (
 	ActionCheckpoint = "checkpoint"
 	ActionRestore    = "restore"
+	ActionCleanup    = "cleanup"
+	ActionInspect    = "inspect"
+	// ActionRestoreLog prints criu restore logs which are left in checkpointed data on the restored node.
+	ActionRestoreLog = "restore-log"
+
+	StorageTypePVC = "pvc"
+	StorageTypeS3  = "s3"
+	// StorageTypeRegistry publishes checkpointed data as an OCI artifact into container registry.
+	StorageTypeRegistry = "registry"
 )
 
 func NewGritAgentOptions() *GritAgentOptions {
 	return &GritAgentOptions{
-		Version:         false,
-		KubeClientQPS:   50,
-		KubeClientBurst: 100,
+		Version:                false,
+		KubeClientQPS:          50,
+		KubeClientBurst:        100,
+		GroupFreezeTimeout:     5 * time.Minute,
+		TerminationMessagePath: metadata.TerminationMessagePath,
+		StorageOptions: StorageOptions{
+			StorageType: StorageTypePVC,
+		},
 	}
 }
 
@@ -46,9 +101,26 @@ func (o *GritAgentOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.BoolVar(&o.Version, "version", o.Version, "print the version information, and then exit")
 	fs.IntVar(&o.KubeClientQPS, "kube-client-qps", o.KubeClientQPS, "the rate of qps to kube-apiserver.")
 	fs.IntVar(&o.KubeClientBurst, "kube-client-burst", o.KubeClientBurst, "the max allowed burst of queries to the kube-apiserver.")
-	fs.StringVar(&o.Action, "action", os.Getenv("ACTION"), "the action to be performed. Valid values are: 'checkpoint', 'restore'.")
+	fs.StringVar(&o.Action, "action", os.Getenv("ACTION"), "the action to be performed. Valid values are: 'checkpoint', 'restore', 'cleanup', 'inspect', 'restore-log'.")
 	fs.StringVar(&o.SrcDir, "src-dir", o.SrcDir, "the source directory in agent container for C/R data.")
 	fs.StringVar(&o.DstDir, "dst-dir", o.DstDir, "the destination directory in agent container for C/R data.")
+	fs.StringVar(&o.CompareSrcDir, "compare-src-dir", o.CompareSrcDir, "the source directory of another checkpoint of the same pod, which inspected checkpoint is compared with.")
+	fs.StringSliceVar(&o.GroupMembers, "group-members", o.GroupMembers, "all member checkpoints of checkpoint group, member pods are frozen together before dumping.")
+	fs.DurationVar(&o.GroupFreezeTimeout, "group-freeze-timeout", o.GroupFreezeTimeout, "the timeout of waiting all members of checkpoint group frozen.")
+	fs.StringVar(&o.Deadline, "deadline", o.Deadline, "the RFC3339 time before which checkpoint should be completed, empty means no deadline.")
+	fs.StringVar(&o.TerminationMessagePath, "termination-message-path", o.TerminationMessagePath, "the file where grit agent writes its report for grit manager.")
+
+	fs.StringVar(&o.StorageType, "storage-type", o.StorageType, "the type of storage for checkpointed data. Valid values are: 'pvc', 's3', 'registry'.")
+	fs.StringVar(&o.S3Endpoint, "s3-endpoint", o.S3Endpoint, "the endpoint of S3-compatible object storage.")
+	fs.StringVar(&o.S3Bucket, "s3-bucket", o.S3Bucket, "the bucket of object storage for checkpointed data.")
+	fs.StringVar(&o.S3Region, "s3-region", o.S3Region, "the region of the bucket.")
+	fs.BoolVar(&o.S3Insecure, "s3-insecure", o.S3Insecure, "access object storage through http instead of https.")
+	fs.BoolVar(&o.RegistryInsecure, "registry-insecure", o.RegistryInsecure, "access container registry through http instead of https.")
+	fs.StringVar(&o.RegistryConfig, "registry-config", o.RegistryConfig, "the docker config file which contains credentials of container registry.")
+	fs.IntVar(&o.CompressionLevel, "compression-level", o.CompressionLevel, "the zstd level(1-22) for compressing checkpointed data, 0 means compression is disabled.")
+	fs.IntVar(&o.CompressionConcurrency, "compression-concurrency", o.CompressionConcurrency, "the number of threads for compressing checkpointed data, 0 means the number of cpus.")
+	fs.StringVar(&o.EncryptionKeyDir, "encryption-key-dir", o.EncryptionKeyDir, "the directory which contains AES-256 keys named by key id for encrypting and decrypting checkpointed data.")
+	fs.StringVar(&o.EncryptionKeyID, "encryption-key-id", o.EncryptionKeyID, "the id of key in encryption-key-dir for encrypting checkpointed data, empty means encryption is disabled.")
 
 	fs.StringVar(&o.TargetPodNamespace, "target-pod-namespace", os.Getenv("TARGET_NAMESPACE"), "the namespace of the target pod.")
 	fs.StringVar(&o.TargetPodName, "target-pod-name", os.Getenv("TARGET_NAME"), "the name of the target pod.")
@@ -56,4 +128,7 @@ func (o *GritAgentOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.RuntimeEndpoint, "runtime-endpoint", "/run/containerd/containerd.sock", "the endpoint of the container runtime.")
 	fs.StringVar(&o.KubeletLogPath, "kubelet-log-path", "/var/log/pods", "the path of kubelet log.")
 	fs.StringVar(&o.HostWorkPath, "host-work-path", o.HostWorkPath, "the work path on the host.")
+	fs.StringSliceVar(&o.Containers, "containers", o.Containers, "the names of containers for checkpointing, all running containers of the pod are checkpointed if it's not specified.")
+	fs.BoolVar(&o.LeaveRunning, "leave-running", o.LeaveRunning, "resume containers after they're checkpointed, otherwise containers are stopped by criu dump.")
+	fs.StringSliceVar(&o.ParentCheckpoints, "parent-checkpoints", o.ParentCheckpoints, "the chain of parent checkpoints for incremental checkpoint, from the direct parent to the first full checkpoint.")
 }
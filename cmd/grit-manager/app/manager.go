@@ -29,6 +29,7 @@ import (
 	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	runtimewebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
@@ -37,9 +38,11 @@ import (
 	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+	"github.com/kaito-project/grit/pkg/gritmanager/metrics"
 	"github.com/kaito-project/grit/pkg/gritmanager/webhooks"
 	"github.com/kaito-project/grit/pkg/injections"
 	"github.com/kaito-project/grit/pkg/util/profile"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
 const (
@@ -167,16 +170,30 @@ func Run(opts *options.GritManagerOptions) error {
 	lo.Must0(mgr.AddHealthzCheck("healthz", healthz.Ping))
 	lo.Must0(mgr.AddReadyzCheck("readyz", healthz.Ping))
 
+	// spans of checkpoints and restores are exported to the OTLP endpoint when tracing is enabled.
+	shutdownTracing, err := tracing.Setup(ctx, GritManager, opts.TracingEndpoint)
+	if err != nil {
+		klog.Errorf("failed to setup tracing, %v", err)
+		return err
+	}
+	defer shutdownTracing(context.Background())
+
 	// initialize girt agent manager
-	agentManager := agentmanager.NewAgentManager(opts.WorkingNamespace, configmapLister)
+	agentManager := agentmanager.NewAgentManager(opts.WorkingNamespace, configmapLister, opts.TracingEndpoint)
 	clk := clock.RealClock{}
 
+	// pods are listed by node name in controllers, so index them before the cache is started.
+	lo.Must0(util.IndexPodNodeName(ctx, mgr.GetFieldIndexer()))
+
 	// initialize controllers
 	controllers := controllers.NewControllers(mgr, clk, opts, agentManager)
 	for _, c := range controllers {
 		lo.Must0(c.Register(ctx, mgr))
 	}
 
+	// in-flight checkpoints and restores are counted from the cache when metrics are scraped.
+	crmetrics.Registry.MustRegister(metrics.NewInFlightCollector(mgr.GetClient()))
+
 	// initialize webhooks
 	webhooks := webhooks.NewWebhooks(mgr, clk, agentManager)
 	for _, c := range webhooks {
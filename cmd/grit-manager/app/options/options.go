@@ -24,6 +24,15 @@ type GritManagerOptions struct {
 	WebhookSecretName  string
 	WebhookServiceName string
 	ExpirationDuration time.Duration
+	// PreemptionTaints and PreemptionConditions are set on nodes by node termination handler of cloud provider when
+	// nodes(like spot instances) will be reclaimed soon, and opted-in pods on these nodes are checkpointed at once.
+	PreemptionTaints     []string
+	PreemptionConditions []string
+	// PreemptionCheckpointDeadline is the time which grit agent can spend on emergency checkpoint before the node is reclaimed.
+	PreemptionCheckpointDeadline time.Duration
+	// TracingEndpoint is the OTLP gRPC endpoint which grit-manager and grit agent export spans of checkpoints and
+	// restores to, like http://otel-collector.observability:4317. tracing is disabled when it's empty.
+	TracingEndpoint string
 }
 
 func NewGritManagerOptions() *GritManagerOptions {
@@ -45,6 +54,11 @@ func NewGritManagerOptions() *GritManagerOptions {
 		WebhookSecretName:  "grit-manager-webhook-certs",
 		WebhookServiceName: "grit-manager-webhook-svc",
 		ExpirationDuration: 10 * 364 * 24 * time.Hour, // 10 years
+		PreemptionTaints: []string{
+			"aws-node-termination-handler/spot-itn",
+			"cloud.google.com/impending-node-termination",
+		},
+		PreemptionCheckpointDeadline: 90 * time.Second,
 	}
 }
 
@@ -61,4 +75,8 @@ func (o *GritManagerOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.WebhookSecretName, "webhook-secret-name", o.WebhookSecretName, "the secret which used for storing certificates for grit webhook")
 	fs.StringVar(&o.WebhookServiceName, "webhook-service-name", o.WebhookServiceName, "the service which used for accessing grit webhook")
 	fs.DurationVar(&o.ExpirationDuration, "cert-duration", o.ExpirationDuration, "the expiration duration of webhook server certificates")
+	fs.StringSliceVar(&o.PreemptionTaints, "preemption-taints", o.PreemptionTaints, "the keys of taints which are added on nodes that will be preempted, opted-in pods on these nodes are checkpointed at once.")
+	fs.StringSliceVar(&o.PreemptionConditions, "preemption-conditions", o.PreemptionConditions, "the types of node conditions which are true on nodes that will be preempted, opted-in pods on these nodes are checkpointed at once.")
+	fs.DurationVar(&o.PreemptionCheckpointDeadline, "preemption-checkpoint-deadline", o.PreemptionCheckpointDeadline, "the deadline of emergency checkpoints which are triggered by node preemption.")
+	fs.StringVar(&o.TracingEndpoint, "tracing-endpoint", o.TracingEndpoint, "the OTLP gRPC endpoint(like http://otel-collector:4317) for exporting traces of checkpoints and restores, tracing is disabled if it's empty.")
 }
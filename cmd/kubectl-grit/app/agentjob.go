@@ -0,0 +1,102 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
+)
+
+// agentManager renders grit agent jobs from the template in grit-agent-config, in the same way as grit manager does.
+func (o *Options) agentManager(ctx context.Context, gritNamespace string) (*agentmanager.AgentManager, error) {
+	cm, err := o.KubeClient.CoreV1().ConfigMaps(gritNamespace).Get(ctx, agentmanager.GritAgentConfigMapName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grit agent config, %w", err)
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(cm); err != nil {
+		return nil, err
+	}
+	return agentmanager.NewAgentManager(gritNamespace, corev1listers.NewConfigMapLister(indexer), ""), nil
+}
+
+// runAgentJob creates grit agent job, prints its logs into out until it exits, and returns an error if the job fails.
+func (o *Options) runAgentJob(ctx context.Context, out io.Writer, job *batchv1.Job) error {
+	if err := o.Client.Create(ctx, job); err != nil {
+		return err
+	}
+	defer func() {
+		// the job is removed even if the command is interrupted, otherwise it's removed after its ttl.
+		_ = o.Client.Delete(context.Background(), job, client.PropagationPolicy(metav1.DeletePropagationBackground))
+	}()
+
+	pod, err := o.waitForJobPod(ctx, job.Name)
+	if err != nil {
+		return err
+	}
+	stream, err := o.KubeClient.CoreV1().Pods(o.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get logs of pod %s, %w", pod.Name, err)
+	}
+	defer stream.Close()
+	if _, err := io.Copy(out, stream); err != nil {
+		return err
+	}
+
+	// logs are ended when grit agent exits, and the job is failed if grit agent fails.
+	return wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		if err := o.Client.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
+			return false, err
+		}
+		for _, cond := range job.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				return false, fmt.Errorf("grit agent job %s failed(%s): %s", job.Name, cond.Reason, cond.Message)
+			}
+		}
+		return false, nil
+	})
+}
+
+// waitForJobPod waits until the pod of job is started, so its logs can be followed.
+func (o *Options) waitForJobPod(ctx context.Context, jobName string) (*corev1.Pod, error) {
+	var pod *corev1.Pod
+	err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		pods, err := o.KubeClient.CoreV1().Pods(o.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set{batchv1.JobNameLabel: jobName}).String(),
+		})
+		if err != nil {
+			return false, err
+		}
+		for i := range pods.Items {
+			if pods.Items[i].Status.Phase != corev1.PodPending {
+				pod = &pods.Items[i]
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for pod of grit agent job %s, %w", jobName, err)
+	}
+	return pod, nil
+}
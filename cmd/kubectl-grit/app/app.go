@@ -0,0 +1,83 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/injections"
+)
+
+func init() {
+	v1alpha1.SchemeBuilder.AddToScheme(scheme.Scheme)
+}
+
+// Options is shared by all subcommands, clients and namespace are resolved from kubeconfig before a subcommand runs.
+type Options struct {
+	loadingRules *clientcmd.ClientConfigLoadingRules
+	overrides    *clientcmd.ConfigOverrides
+
+	Client     client.Client
+	KubeClient kubernetes.Interface
+	Namespace  string
+}
+
+func NewKubectlGritCommand() *cobra.Command {
+	o := &Options{
+		loadingRules: clientcmd.NewDefaultClientConfigLoadingRules(),
+		overrides:    &clientcmd.ConfigOverrides{},
+	}
+
+	cmd := &cobra.Command{
+		Use:          "kubectl-grit",
+		Short:        "Checkpoint, restore and migrate GRIT pods through kaito.sh/v1alpha1 resources",
+		Version:      injections.VersionInfo(),
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.Complete()
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&o.loadingRules.ExplicitPath, "kubeconfig", "", "path to the kubeconfig file.")
+	clientcmd.BindOverrideFlags(o.overrides, cmd.PersistentFlags(), clientcmd.RecommendedConfigOverrideFlags(""))
+
+	cmd.AddCommand(
+		newCheckpointCommand(o),
+		newRestoreCommand(o),
+		newMigrateCommand(o),
+		newListCommand(o),
+		newDescribeCommand(o),
+		newLogsCommand(o),
+		newInspectCommand(o),
+	)
+	return cmd
+}
+
+// Complete builds clients from kubeconfig and flags, and namespace is the namespace of current context if it's not specified.
+func (o *Options) Complete() error {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(o.loadingRules, o.overrides)
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return err
+	}
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return err
+	}
+	cfg.UserAgent = "kubectl-grit"
+
+	if o.Client, err = client.New(cfg, client.Options{Scheme: scheme.Scheme}); err != nil {
+		return err
+	}
+	if o.KubeClient, err = kubernetes.NewForConfig(cfg); err != nil {
+		return err
+	}
+	o.Namespace = namespace
+	return nil
+}
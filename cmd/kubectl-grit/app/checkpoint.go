@@ -0,0 +1,113 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+type checkpointOptions struct {
+	*Options
+	storageOptions
+	waitOptions
+
+	name       string
+	containers []string
+	mode       string
+	parent     string
+}
+
+func newCheckpointCommand(o *Options) *cobra.Command {
+	opts := &checkpointOptions{
+		Options: o,
+		mode:    string(v1alpha1.CheckpointModeStop),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "checkpoint POD",
+		Short: "Checkpoint a running pod",
+		Example: `  # checkpoint pod into a pvc, and wait until it's checkpointed
+  kubectl grit checkpoint my-pod --pvc grit-data
+
+  # take a snapshot of pod which keeps running, and push checkpointed data into a registry
+  kubectl grit checkpoint my-pod --mode Snapshot --registry registry.example.com/grit`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd.Context(), cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&opts.name, "name", opts.name, "the name of Checkpoint, it's generated from pod name if not specified.")
+	fs.StringSliceVar(&opts.containers, "containers", opts.containers, "the names of containers for checkpointing, all running containers are checkpointed if not specified.")
+	fs.StringVar(&opts.mode, "mode", opts.mode, "Stop stops the workload after it's dumped, Snapshot keeps it running.")
+	fs.StringVar(&opts.parent, "parent", opts.parent, "the parent Checkpoint for incremental checkpoint, it should be checkpointed in Snapshot mode.")
+	opts.storageOptions.addFlags(fs)
+	opts.waitOptions.addFlags(fs)
+	return cmd
+}
+
+func (o *checkpointOptions) run(ctx context.Context, out io.Writer, podName string) error {
+	ckpt, err := o.newCheckpoint(podName)
+	if err != nil {
+		return err
+	}
+	if err := o.Client.Create(ctx, ckpt); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "checkpoint/%s created\n", ckpt.Name)
+
+	if !o.wait {
+		return nil
+	}
+	if err := waitForPhase(ctx, o.Client, out, ckpt, &o.waitOptions); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "checkpointed data is stored in %s\n", ckpt.Status.StorageLocation.String())
+	return nil
+}
+
+func (o *checkpointOptions) newCheckpoint(podName string) (*v1alpha1.Checkpoint, error) {
+	mode := v1alpha1.CheckpointMode(o.mode)
+	if mode != v1alpha1.CheckpointModeStop && mode != v1alpha1.CheckpointModeSnapshot {
+		return nil, fmt.Errorf("invalid mode %s, valid values are %s and %s", o.mode, v1alpha1.CheckpointModeStop, v1alpha1.CheckpointModeSnapshot)
+	}
+	volumeClaim, objectStorage, registry, err := o.storage()
+	if err != nil {
+		return nil, err
+	}
+	encryption, err := o.encryption()
+	if err != nil {
+		return nil, err
+	}
+
+	ckpt := &v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: o.Namespace,
+			Name:      o.name,
+		},
+		Spec: v1alpha1.CheckpointSpec{
+			PodName:              podName,
+			Containers:           o.containers,
+			Mode:                 mode,
+			VolumeClaim:          volumeClaim,
+			ObjectStorage:        objectStorage,
+			Registry:             registry,
+			ParentCheckpointName: o.parent,
+			Compression:          o.compression(),
+			Encryption:           encryption,
+		},
+	}
+	if len(o.name) == 0 {
+		ckpt.GenerateName = podName + "-"
+	}
+	return ckpt, nil
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+type describeOptions struct {
+	*Options
+}
+
+func newDescribeCommand(o *Options) *cobra.Command {
+	opts := &describeOptions{
+		Options: o,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "describe checkpoint|restore|migration NAME",
+		Short: "Show phase, conditions, data location and sizes of a Checkpoint, Restore or Migration",
+		Example: `  # show details of a checkpoint
+  kubectl grit describe checkpoint my-ckpt`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, err := resourceKind(args[0])
+			if err != nil {
+				return err
+			}
+			return opts.run(cmd.Context(), cmd.OutOrStdout(), kind, args[1])
+		},
+	}
+	return cmd
+}
+
+func (o *describeOptions) run(ctx context.Context, out io.Writer, kind, name string) error {
+	key := client.ObjectKey{Namespace: o.Namespace, Name: name}
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	switch kind {
+	case "checkpoint":
+		var ckpt v1alpha1.Checkpoint
+		if err := o.Client.Get(ctx, key, &ckpt); err != nil {
+			return err
+		}
+		describeCheckpoint(w, &ckpt)
+	case "restore":
+		var restore v1alpha1.Restore
+		if err := o.Client.Get(ctx, key, &restore); err != nil {
+			return err
+		}
+		describeRestore(w, &restore)
+	case "migration":
+		var migration v1alpha1.Migration
+		if err := o.Client.Get(ctx, key, &migration); err != nil {
+			return err
+		}
+		describeMigration(w, &migration)
+	}
+	return w.Flush()
+}
+
+func describeCheckpoint(w io.Writer, ckpt *v1alpha1.Checkpoint) {
+	describeMeta(w, &ckpt.ObjectMeta)
+	field(w, "Pod", ckpt.Spec.PodName)
+	field(w, "Containers", strings.Join(ckpt.Spec.Containers, ","))
+	field(w, "Mode", string(ckpt.Spec.Mode))
+	field(w, "Parent Checkpoints", strings.Join(ckpt.Status.ParentCheckpoints, ","))
+	if ckpt.Spec.Compression != nil {
+		field(w, "Compression Level", fmt.Sprint(ckpt.Spec.Compression.Level))
+	}
+	if ckpt.Spec.Encryption != nil {
+		field(w, "Encryption", fmt.Sprintf("%s/%s", ckpt.Spec.Encryption.SecretName, ckpt.Spec.Encryption.KeyID))
+	}
+	field(w, "Node", ckpt.Status.NodeName)
+	field(w, "Pod UID", ckpt.Status.PodUID)
+	field(w, "Phase", string(ckpt.Status.Phase))
+	field(w, "Data Location", ckpt.Status.StorageLocation.String())
+	field(w, "Data Size", dataSize(ckpt.Status.DataSize))
+	describeConditions(w, ckpt.Status.Conditions)
+}
+
+func describeRestore(w io.Writer, restore *v1alpha1.Restore) {
+	describeMeta(w, &restore.ObjectMeta)
+	field(w, "Checkpoint", restore.Spec.CheckpointName)
+	if len(restore.Spec.OwnerRef.Name) != 0 {
+		field(w, "Owner", fmt.Sprintf("%s/%s", restore.Spec.OwnerRef.Kind, restore.Spec.OwnerRef.Name))
+	}
+	if restore.Spec.Selector != nil {
+		field(w, "Selector", metav1.FormatLabelSelector(restore.Spec.Selector))
+	}
+	field(w, "Target Node Name", restore.Spec.TargetNodeName)
+	field(w, "Node Selector", formatMap(restore.Spec.NodeSelector))
+	field(w, "Target Pod", restore.Status.TargetPod)
+	field(w, "Node", restore.Status.NodeName)
+	field(w, "Phase", string(restore.Status.Phase))
+	describeConditions(w, restore.Status.Conditions)
+}
+
+func describeMigration(w io.Writer, migration *v1alpha1.Migration) {
+	describeMeta(w, &migration.ObjectMeta)
+	field(w, "Pod", migration.Spec.PodName)
+	field(w, "Pod Removal Policy", string(migration.Spec.PodRemovalPolicy))
+	field(w, "Target Node Name", migration.Spec.TargetNodeName)
+	field(w, "Node Selector", formatMap(migration.Spec.NodeSelector))
+	field(w, "Source Node", migration.Status.SourceNodeName)
+	if migration.Status.OwnerRef != nil {
+		field(w, "Owner", fmt.Sprintf("%s/%s", migration.Status.OwnerRef.Kind, migration.Status.OwnerRef.Name))
+	}
+	field(w, "Checkpoint", migration.Status.CheckpointName)
+	field(w, "Restore", migration.Status.RestoreName)
+	field(w, "Target Pod", migration.Status.TargetPod)
+	field(w, "Target Node", migration.Status.TargetNodeName)
+	field(w, "Retries", fmt.Sprint(migration.Status.Retries))
+	if migration.Status.Downtime != nil {
+		field(w, "Downtime", migration.Status.Downtime.Duration.Round(time.Millisecond).String())
+	}
+	field(w, "Phase", string(migration.Status.Phase))
+	describeConditions(w, migration.Status.Conditions)
+}
+
+func describeMeta(w io.Writer, meta *metav1.ObjectMeta) {
+	field(w, "Name", meta.Name)
+	field(w, "Namespace", meta.Namespace)
+	field(w, "Created", fmt.Sprintf("%s (%s ago)", meta.CreationTimestamp.UTC().Format(time.RFC3339), age(meta.CreationTimestamp)))
+}
+
+func describeConditions(w io.Writer, conds []metav1.Condition) {
+	fmt.Fprintln(w, "Conditions:")
+	if len(conds) == 0 {
+		fmt.Fprintln(w, "  <none>")
+		return
+	}
+	fmt.Fprintln(w, "  TYPE\tSTATUS\tLAST TRANSITION\tREASON\tMESSAGE")
+	for _, cond := range conds {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", cond.Type, cond.Status, cond.LastTransitionTime.UTC().Format(time.RFC3339), cond.Reason, cond.Message)
+	}
+}
+
+// field prints a key value line, and empty value is printed as <none>.
+func field(w io.Writer, key, value string) {
+	if len(value) == 0 {
+		value = "<none>"
+	}
+	fmt.Fprintf(w, "%s:\t%s\n", key, value)
+}
+
+func formatMap(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
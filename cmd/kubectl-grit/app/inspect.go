@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+type inspectOptions struct {
+	*Options
+
+	diff          string
+	gritNamespace string
+	timeout       time.Duration
+}
+
+func newInspectCommand(o *Options) *cobra.Command {
+	opts := &inspectOptions{
+		Options:       o,
+		gritNamespace: "kaito-workspace",
+		timeout:       10 * time.Minute,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "inspect CHECKPOINT",
+		Short: "Print process tree, memory pages, opened files, sockets, mounts, rootfs diff and log tail of a checkpoint",
+		Long: `Inspect runs a grit agent job which downloads checkpointed data from the storage and prints its content.
+With --diff, changes from another checkpoint of the same pod are printed instead, like growth of memory pages and
+processes, files, sockets and mounts which are added or removed.`,
+		Example: `  # print the content of a checkpoint
+  kubectl grit inspect my-ckpt
+
+  # show why the checkpoint is bigger than the previous one
+  kubectl grit inspect my-ckpt-2 --diff my-ckpt-1`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd.Context(), cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&opts.diff, "diff", opts.diff, "another checkpoint of the same pod, which the checkpoint is compared with.")
+	fs.StringVar(&opts.gritNamespace, "grit-namespace", opts.gritNamespace, "the namespace where grit manager and grit-agent-config are deployed.")
+	fs.DurationVar(&opts.timeout, "timeout", opts.timeout, "the time to wait for inspection.")
+	return cmd
+}
+
+func (o *inspectOptions) run(ctx context.Context, out io.Writer, checkpointName string) error {
+	ckpt, err := o.checkpointedCheckpoint(ctx, checkpointName)
+	if err != nil {
+		return err
+	}
+	var compare *v1alpha1.Checkpoint
+	if len(o.diff) != 0 {
+		if compare, err = o.checkpointedCheckpoint(ctx, o.diff); err != nil {
+			return err
+		}
+		if compare.Spec.PodName != ckpt.Spec.PodName {
+			return fmt.Errorf("checkpoint/%s and checkpoint/%s are not checkpoints of the same pod", ckpt.Name, compare.Name)
+		}
+	}
+
+	manager, err := o.agentManager(ctx, o.gritNamespace)
+	if err != nil {
+		return err
+	}
+	job, err := manager.GenerateGritAgentInspectJob(ctx, ckpt, compare, fmt.Sprintf("grit-inspect-%s-%s", ckpt.Name, utilrand.String(5)))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	return o.runAgentJob(ctx, out, job)
+}
+
+func (o *inspectOptions) checkpointedCheckpoint(ctx context.Context, name string) (*v1alpha1.Checkpoint, error) {
+	var ckpt v1alpha1.Checkpoint
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: name}, &ckpt); err != nil {
+		return nil, err
+	}
+	if ckpt.Status.Phase != v1alpha1.Checkpointed {
+		return nil, fmt.Errorf("checkpoint/%s is %s, only checkpointed checkpoint can be inspected", ckpt.Name, ckpt.Status.Phase)
+	}
+	return &ckpt, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+type listOptions struct {
+	*Options
+
+	allNamespaces bool
+}
+
+func newListCommand(o *Options) *cobra.Command {
+	opts := &listOptions{
+		Options: o,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list [checkpoints|restores|migrations]",
+		Short: "List Checkpoints, Restores and Migrations",
+		Example: `  # list all checkpoints, restores and migrations in current namespace
+  kubectl grit list
+
+  # list checkpoints in all namespaces
+  kubectl grit list checkpoints -A`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{"checkpoints", "restores", "migrations"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kinds := []string{"checkpoints", "restores", "migrations"}
+			if len(args) == 1 {
+				kind, err := resourceKind(args[0])
+				if err != nil {
+					return err
+				}
+				kinds = []string{kind + "s"}
+			}
+			return opts.run(cmd.Context(), cmd.OutOrStdout(), kinds)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&opts.allNamespaces, "all-namespaces", "A", opts.allNamespaces, "list resources across all namespaces.")
+	return cmd
+}
+
+// resourceKind returns the singular kind for the resource name specified by user, plurals and short names are accepted.
+func resourceKind(name string) (string, error) {
+	switch name {
+	case "checkpoint", "checkpoints", "ckpt":
+		return "checkpoint", nil
+	case "restore", "restores":
+		return "restore", nil
+	case "migration", "migrations":
+		return "migration", nil
+	}
+	return "", fmt.Errorf("unknown resource %s, valid resources are checkpoint, restore and migration", name)
+}
+
+func (o *listOptions) run(ctx context.Context, out io.Writer, kinds []string) error {
+	var opts []client.ListOption
+	if !o.allNamespaces {
+		opts = append(opts, client.InNamespace(o.Namespace))
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	for i, kind := range kinds {
+		if i != 0 {
+			fmt.Fprintln(w)
+		}
+		var err error
+		switch kind {
+		case "checkpoints":
+			err = o.listCheckpoints(ctx, w, opts)
+		case "restores":
+			err = o.listRestores(ctx, w, opts)
+		case "migrations":
+			err = o.listMigrations(ctx, w, opts)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (o *listOptions) listCheckpoints(ctx context.Context, w io.Writer, opts []client.ListOption) error {
+	var list v1alpha1.CheckpointList
+	if err := o.Client.List(ctx, &list, opts...); err != nil {
+		return err
+	}
+	o.printHeader(w, "CHECKPOINT", "POD", "PHASE", "NODE", "SIZE", "LOCATION", "AGE")
+	for i := range list.Items {
+		ckpt := &list.Items[i]
+		o.printRow(w, ckpt, ckpt.Name, ckpt.Spec.PodName, string(ckpt.Status.Phase), ckpt.Status.NodeName,
+			dataSize(ckpt.Status.DataSize), ckpt.Status.StorageLocation.String(), age(ckpt.CreationTimestamp))
+	}
+	return nil
+}
+
+func (o *listOptions) listRestores(ctx context.Context, w io.Writer, opts []client.ListOption) error {
+	var list v1alpha1.RestoreList
+	if err := o.Client.List(ctx, &list, opts...); err != nil {
+		return err
+	}
+	o.printHeader(w, "RESTORE", "CHECKPOINT", "PHASE", "TARGET-POD", "NODE", "AGE")
+	for i := range list.Items {
+		restore := &list.Items[i]
+		o.printRow(w, restore, restore.Name, restore.Spec.CheckpointName, string(restore.Status.Phase), restore.Status.TargetPod,
+			restore.Status.NodeName, age(restore.CreationTimestamp))
+	}
+	return nil
+}
+
+func (o *listOptions) listMigrations(ctx context.Context, w io.Writer, opts []client.ListOption) error {
+	var list v1alpha1.MigrationList
+	if err := o.Client.List(ctx, &list, opts...); err != nil {
+		return err
+	}
+	o.printHeader(w, "MIGRATION", "POD", "PHASE", "TARGET-POD", "TARGET-NODE", "DOWNTIME", "AGE")
+	for i := range list.Items {
+		migration := &list.Items[i]
+		downtime := ""
+		if migration.Status.Downtime != nil {
+			downtime = migration.Status.Downtime.Duration.Round(time.Millisecond).String()
+		}
+		o.printRow(w, migration, migration.Name, migration.Spec.PodName, string(migration.Status.Phase), migration.Status.TargetPod,
+			migration.Status.TargetNodeName, downtime, age(migration.CreationTimestamp))
+	}
+	return nil
+}
+
+func (o *listOptions) printHeader(w io.Writer, columns ...string) {
+	if o.allNamespaces {
+		fmt.Fprint(w, "NAMESPACE\t")
+	}
+	o.printColumns(w, columns)
+}
+
+func (o *listOptions) printRow(w io.Writer, obj client.Object, columns ...string) {
+	if o.allNamespaces {
+		fmt.Fprintf(w, "%s\t", obj.GetNamespace())
+	}
+	o.printColumns(w, columns)
+}
+
+func (o *listOptions) printColumns(w io.Writer, columns []string) {
+	for i, column := range columns {
+		if len(column) == 0 {
+			column = "<none>"
+		}
+		if i != 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, column)
+	}
+	fmt.Fprintln(w)
+}
+
+// dataSize formats the size of checkpointed data, compressed size is appended when data is compressed.
+func dataSize(size *v1alpha1.DataSize) string {
+	if size == nil {
+		return ""
+	}
+	s := resource.NewQuantity(size.Uncompressed, resource.BinarySI).String()
+	if size.Compressed != 0 {
+		s = fmt.Sprintf("%s(%s)", s, resource.NewQuantity(size.Compressed, resource.BinarySI).String())
+	}
+	return s
+}
+
+func age(t metav1.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
@@ -0,0 +1,206 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type logsOptions struct {
+	*Options
+
+	follow        bool
+	gritNamespace string
+	timeout       time.Duration
+}
+
+func newLogsCommand(o *Options) *cobra.Command {
+	opts := &logsOptions{
+		Options:       o,
+		gritNamespace: "kaito-workspace",
+		timeout:       5 * time.Minute,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "logs checkpoint|restore|migration NAME",
+		Short: "Print logs of grit agent and criu for a Checkpoint, Restore or Migration",
+		Long: `Print logs of grit agent job, the tail of criu dump log is included in agent logs when criu dump fails.
+For Restore, the tail of criu restore log is printed by a grit agent job on the restored node, which is rendered from
+grit-agent-config and removed when the command exits, and warning events of restoration pod are printed too.
+Grit agent job is removed after checkpoint or restore succeeds, so its logs are only available while it's running or after it fails.`,
+		Example: `  # print logs of a failed checkpoint
+  kubectl grit logs checkpoint my-ckpt
+
+  # stream logs of grit agent while checkpoint is running
+  kubectl grit logs checkpoint my-ckpt -f`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, err := resourceKind(args[0])
+			if err != nil {
+				return err
+			}
+			return opts.run(cmd.Context(), cmd.OutOrStdout(), kind, args[1])
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.BoolVarP(&opts.follow, "follow", "f", opts.follow, "stream logs of grit agent.")
+	fs.StringVar(&opts.gritNamespace, "grit-namespace", opts.gritNamespace, "the namespace where grit manager and grit-agent-config are deployed.")
+	fs.DurationVar(&opts.timeout, "timeout", opts.timeout, "the time to wait for printing criu restore log.")
+	return cmd
+}
+
+func (o *logsOptions) run(ctx context.Context, out io.Writer, kind, name string) error {
+	key := client.ObjectKey{Namespace: o.Namespace, Name: name}
+	switch kind {
+	case "checkpoint":
+		var ckpt v1alpha1.Checkpoint
+		if err := o.Client.Get(ctx, key, &ckpt); err != nil {
+			return err
+		}
+		return o.checkpointLogs(ctx, out, &ckpt)
+	case "restore":
+		var restore v1alpha1.Restore
+		if err := o.Client.Get(ctx, key, &restore); err != nil {
+			return err
+		}
+		return o.restoreLogs(ctx, out, &restore)
+	case "migration":
+		var migration v1alpha1.Migration
+		if err := o.Client.Get(ctx, key, &migration); err != nil {
+			return err
+		}
+		// checkpoint and restore of migration are printed in order, so logs are not streamed.
+		o.follow = false
+		if len(migration.Status.CheckpointName) != 0 {
+			var ckpt v1alpha1.Checkpoint
+			if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: migration.Status.CheckpointName}, &ckpt); client.IgnoreNotFound(err) != nil {
+				return err
+			} else if err == nil {
+				if err := o.checkpointLogs(ctx, out, &ckpt); err != nil {
+					return err
+				}
+			}
+		}
+		if len(migration.Status.RestoreName) != 0 {
+			var restore v1alpha1.Restore
+			if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: migration.Status.RestoreName}, &restore); client.IgnoreNotFound(err) != nil {
+				return err
+			} else if err == nil {
+				return o.restoreLogs(ctx, out, &restore)
+			}
+		}
+	}
+	return nil
+}
+
+func (o *logsOptions) checkpointLogs(ctx context.Context, out io.Writer, ckpt *v1alpha1.Checkpoint) error {
+	fmt.Fprintf(out, "==> checkpoint/%s %s\n", ckpt.Name, ckpt.Status.Phase)
+	return o.agentLogs(ctx, out, util.GritAgentJobName(ckpt, nil))
+}
+
+func (o *logsOptions) restoreLogs(ctx context.Context, out io.Writer, restore *v1alpha1.Restore) error {
+	fmt.Fprintf(out, "==> restore/%s %s\n", restore.Name, restore.Status.Phase)
+	if err := o.agentLogs(ctx, out, util.GritAgentJobName(nil, restore)); err != nil {
+		return err
+	}
+	if len(restore.Status.TargetPod) == 0 {
+		return nil
+	}
+	if len(restore.Status.NodeName) != 0 {
+		if err := o.criuRestoreLogs(ctx, out, restore); err != nil {
+			fmt.Fprintf(out, "failed to print criu restore log, %v\n", err)
+		}
+	}
+	return o.podWarnings(ctx, out, restore.Status.TargetPod)
+}
+
+// criuRestoreLogs prints the tail of criu restore logs, which are left in checkpointed data on the restored node by the
+// shim, through a grit agent job on that node.
+func (o *logsOptions) criuRestoreLogs(ctx context.Context, out io.Writer, restore *v1alpha1.Restore) error {
+	manager, err := o.agentManager(ctx, o.gritNamespace)
+	if err != nil {
+		return err
+	}
+	job, err := manager.GenerateGritAgentRestoreLogJob(ctx, restore, fmt.Sprintf("grit-restore-log-%s-%s", restore.Name, utilrand.String(5)))
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "--> criu restore log on node/%s\n", restore.Status.NodeName)
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	return o.runAgentJob(ctx, out, job)
+}
+
+// agentLogs prints logs of all pods of grit agent job, pods are printed in the order of creation.
+func (o *logsOptions) agentLogs(ctx context.Context, out io.Writer, jobName string) error {
+	pods, err := o.KubeClient.CoreV1().Pods(o.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{batchv1.JobNameLabel: jobName}).String(),
+	})
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		fmt.Fprintf(out, "no pods of grit agent job %s, the job is removed after it succeeds\n", jobName)
+		return nil
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].CreationTimestamp.Before(&pods.Items[j].CreationTimestamp)
+	})
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		fmt.Fprintf(out, "--> pod/%s %s\n", pod.Name, pod.Status.Phase)
+		if pod.Status.Phase == corev1.PodPending {
+			continue
+		}
+		stream, err := o.KubeClient.CoreV1().Pods(o.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: o.follow}).Stream(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get logs of pod %s, %w", pod.Name, err)
+		}
+		_, err = io.Copy(out, stream)
+		stream.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// podWarnings prints warning events of restoration pod, errors of criu restore are reported by containerd as events of
+// the pod when containers fail to start.
+func (o *logsOptions) podWarnings(ctx context.Context, out io.Writer, podName string) error {
+	events, err := o.KubeClient.CoreV1().Events(o.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.Set{
+			"involvedObject.kind": "Pod",
+			"involvedObject.name": podName,
+			"type":                corev1.EventTypeWarning,
+		}.AsSelector().String(),
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "--> warning events of pod/%s\n", podName)
+	for _, event := range events.Items {
+		fmt.Fprintf(out, "%s\t%s\t%s\n", event.LastTimestamp.UTC().Format(time.RFC3339), event.Reason, event.Message)
+	}
+	return nil
+}
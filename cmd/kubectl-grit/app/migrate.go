@@ -0,0 +1,102 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+type migrateOptions struct {
+	*Options
+	storageOptions
+	waitOptions
+
+	name             string
+	node             string
+	nodeSelector     map[string]string
+	podRemovalPolicy string
+}
+
+func newMigrateCommand(o *Options) *cobra.Command {
+	opts := &migrateOptions{
+		Options:          o,
+		podRemovalPolicy: string(v1alpha1.PodRemovalEvict),
+	}
+
+	cmd := &cobra.Command{
+		Use:   "migrate POD",
+		Short: "Migrate a pod to another node by checkpointing it and restoring the pod recreated by its owner",
+		Example: `  # migrate pod to node-2 through a pvc which is accessible on both nodes
+  kubectl grit migrate my-pod --node node-2 --pvc grit-data`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd.Context(), cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&opts.name, "name", opts.name, "the name of Migration, it's generated from pod name if not specified.")
+	fs.StringVar(&opts.node, "node", opts.node, "the node which pod is migrated to.")
+	fs.StringToStringVar(&opts.nodeSelector, "node-selector", opts.nodeSelector, "the labels of nodes which pod is migrated to.")
+	fs.StringVar(&opts.podRemovalPolicy, "pod-removal-policy", opts.podRemovalPolicy, "how checkpointed pod is removed, Evict respects PodDisruptionBudget and Delete doesn't.")
+	opts.storageOptions.addFlags(fs)
+	opts.waitOptions.addFlags(fs)
+	return cmd
+}
+
+func (o *migrateOptions) run(ctx context.Context, out io.Writer, podName string) error {
+	policy := v1alpha1.PodRemovalPolicy(o.podRemovalPolicy)
+	if policy != v1alpha1.PodRemovalEvict && policy != v1alpha1.PodRemovalDelete {
+		return fmt.Errorf("invalid pod removal policy %s, valid values are %s and %s", o.podRemovalPolicy, v1alpha1.PodRemovalEvict, v1alpha1.PodRemovalDelete)
+	}
+	volumeClaim, objectStorage, registry, err := o.storage()
+	if err != nil {
+		return err
+	}
+	encryption, err := o.encryption()
+	if err != nil {
+		return err
+	}
+
+	migration := &v1alpha1.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: o.Namespace,
+			Name:      o.name,
+		},
+		Spec: v1alpha1.MigrationSpec{
+			PodName:          podName,
+			VolumeClaim:      volumeClaim,
+			ObjectStorage:    objectStorage,
+			Registry:         registry,
+			Compression:      o.compression(),
+			Encryption:       encryption,
+			TargetNodeName:   o.node,
+			NodeSelector:     o.nodeSelector,
+			PodRemovalPolicy: policy,
+		},
+	}
+	if len(o.name) == 0 {
+		migration.GenerateName = podName + "-"
+	}
+	if err := o.Client.Create(ctx, migration); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "migration/%s created\n", migration.Name)
+
+	if !o.wait {
+		return nil
+	}
+	if err := waitForPhase(ctx, o.Client, out, migration, &o.waitOptions); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "pod/%s is migrated to pod/%s on node %s\n", podName, migration.Status.TargetPod, migration.Status.TargetNodeName)
+	return nil
+}
@@ -0,0 +1,163 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+type restoreOptions struct {
+	*Options
+	waitOptions
+
+	name         string
+	toOwner      bool
+	selector     string
+	node         string
+	nodeSelector map[string]string
+}
+
+func newRestoreCommand(o *Options) *cobra.Command {
+	opts := &restoreOptions{
+		Options: o,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "restore CHECKPOINT",
+		Short: "Restore a checkpoint into the next pod created by the owner of checkpointed pod, or a pod selected by labels",
+		Example: `  # restore checkpoint into the pod recreated by the Deployment or StatefulSet of checkpointed pod
+  kubectl grit restore my-ckpt --to-owner
+
+  # restore checkpoint into a standalone pod with labels app=demo on node-2
+  kubectl grit restore my-ckpt --selector app=demo --node node-2`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd.Context(), cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	fs := cmd.Flags()
+	fs.StringVar(&opts.name, "name", opts.name, "the name of Restore, it's generated from checkpoint name if not specified.")
+	fs.BoolVar(&opts.toOwner, "to-owner", opts.toOwner, "restore into the pod created by the controller owner of checkpointed pod.")
+	fs.StringVarP(&opts.selector, "selector", "l", opts.selector, "restore into the pod matched by this label selector.")
+	fs.StringVar(&opts.node, "node", opts.node, "the node which restoration pod is constrained to.")
+	fs.StringToStringVar(&opts.nodeSelector, "node-selector", opts.nodeSelector, "the labels of nodes which restoration pod is constrained to.")
+	opts.waitOptions.addFlags(fs)
+	return cmd
+}
+
+func (o *restoreOptions) run(ctx context.Context, out io.Writer, checkpointName string) error {
+	if o.toOwner == (len(o.selector) != 0) {
+		return fmt.Errorf("only one of --to-owner and --selector should be specified")
+	}
+
+	var ckpt v1alpha1.Checkpoint
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: o.Namespace, Name: checkpointName}, &ckpt); err != nil {
+		return err
+	}
+	if ckpt.Status.Phase != v1alpha1.Checkpointed {
+		return fmt.Errorf("checkpoint/%s is %s, only checkpointed checkpoint can be restored", ckpt.Name, ckpt.Status.Phase)
+	}
+
+	restore := &v1alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: o.Namespace,
+			Name:      o.name,
+		},
+		Spec: v1alpha1.RestoreSpec{
+			CheckpointName: ckpt.Name,
+			TargetNodeName: o.node,
+			NodeSelector:   o.nodeSelector,
+		},
+	}
+	if len(o.name) == 0 {
+		restore.GenerateName = ckpt.Name + "-"
+	}
+	if o.toOwner {
+		ownerRef, err := o.checkpointedPodOwner(ctx, &ckpt)
+		if err != nil {
+			return err
+		}
+		restore.Spec.OwnerRef = *ownerRef
+	} else {
+		selector, err := metav1.ParseToLabelSelector(o.selector)
+		if err != nil {
+			return fmt.Errorf("invalid selector %s, %w", o.selector, err)
+		}
+		restore.Spec.Selector = selector
+	}
+
+	if err := o.Client.Create(ctx, restore); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "restore/%s created\n", restore.Name)
+
+	if !o.wait {
+		return nil
+	}
+	if err := waitForPhase(ctx, o.Client, out, restore, &o.waitOptions); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "pod/%s is restored on node %s\n", restore.Status.TargetPod, restore.Status.NodeName)
+	return nil
+}
+
+// checkpointedPodOwner returns the controller owner of checkpointed pod. the pod maybe has been removed after checkpoint,
+// then the owner is looked up by the owner uid recorded in Checkpoint.
+func (o *restoreOptions) checkpointedPodOwner(ctx context.Context, ckpt *v1alpha1.Checkpoint) (*metav1.OwnerReference, error) {
+	if len(ckpt.Status.PodOwnerUID) == 0 {
+		return nil, fmt.Errorf("checkpointed pod of checkpoint/%s has no owner, use --selector instead", ckpt.Name)
+	}
+
+	var pod corev1.Pod
+	if err := o.Client.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.PodName}, &pod); client.IgnoreNotFound(err) != nil {
+		return nil, err
+	} else if err == nil {
+		if ownerRef := metav1.GetControllerOf(&pod); ownerRef != nil && string(ownerRef.UID) == ckpt.Status.PodOwnerUID {
+			return ownerRef, nil
+		}
+	}
+
+	for _, list := range []client.ObjectList{&appsv1.ReplicaSetList{}, &appsv1.StatefulSetList{}, &batchv1.JobList{}} {
+		if err := o.Client.List(ctx, list, client.InNamespace(ckpt.Namespace)); err != nil {
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok || string(obj.GetUID()) != ckpt.Status.PodOwnerUID {
+				continue
+			}
+			gvk, err := o.Client.GroupVersionKindFor(obj)
+			if err != nil {
+				return nil, err
+			}
+			return &metav1.OwnerReference{
+				APIVersion:         gvk.GroupVersion().String(),
+				Kind:               gvk.Kind,
+				Name:               obj.GetName(),
+				UID:                obj.GetUID(),
+				Controller:         ptr.To(true),
+				BlockOwnerDeletion: ptr.To(true),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("owner(%s) of checkpointed pod is not found, use --selector instead", ckpt.Status.PodOwnerUID)
+}
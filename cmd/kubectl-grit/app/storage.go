@@ -0,0 +1,98 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+// storageOptions are flags for the storage of checkpointed data, they're shared by checkpoint and migrate commands.
+type storageOptions struct {
+	volumeClaim      string
+	s3Endpoint       string
+	s3Bucket         string
+	s3Prefix         string
+	s3Region         string
+	s3Insecure       bool
+	s3Secret         string
+	registry         string
+	registryInsecure bool
+	registrySecret   string
+	compressionLevel int32
+	encryptionSecret string
+	encryptionKeyID  string
+}
+
+func (o *storageOptions) addFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.volumeClaim, "pvc", o.volumeClaim, "the pvc for storing checkpointed data.")
+	fs.StringVar(&o.s3Endpoint, "s3-endpoint", o.s3Endpoint, "the endpoint of S3-compatible object storage for storing checkpointed data.")
+	fs.StringVar(&o.s3Bucket, "s3-bucket", o.s3Bucket, "the bucket of object storage.")
+	fs.StringVar(&o.s3Prefix, "s3-prefix", o.s3Prefix, "the prefix of object keys in the bucket.")
+	fs.StringVar(&o.s3Region, "s3-region", o.s3Region, "the region of the bucket.")
+	fs.BoolVar(&o.s3Insecure, "s3-insecure", o.s3Insecure, "access object storage through http instead of https.")
+	fs.StringVar(&o.s3Secret, "s3-secret", o.s3Secret, "the secret which contains access-key-id and secret-access-key of object storage.")
+	fs.StringVar(&o.registry, "registry", o.registry, "the repository of container registry for pushing checkpointed data, like registry.example.com/grit.")
+	fs.BoolVar(&o.registryInsecure, "registry-insecure", o.registryInsecure, "access container registry through http instead of https.")
+	fs.StringVar(&o.registrySecret, "registry-secret", o.registrySecret, "the kubernetes.io/dockerconfigjson secret for accessing container registry.")
+	fs.Int32Var(&o.compressionLevel, "compression-level", o.compressionLevel, "the zstd level(1-22) for compressing checkpointed data, 0 means compression is disabled.")
+	fs.StringVar(&o.encryptionSecret, "encryption-secret", o.encryptionSecret, "the secret which contains AES-256 keys for encrypting checkpointed data.")
+	fs.StringVar(&o.encryptionKeyID, "encryption-key-id", o.encryptionKeyID, "the key in encryption secret for encrypting checkpointed data.")
+}
+
+// storage returns the storage specified by flags, only one of pvc, object storage and registry can be specified.
+func (o *storageOptions) storage() (*corev1.PersistentVolumeClaimVolumeSource, *v1alpha1.ObjectStorageSource, *v1alpha1.RegistrySource, error) {
+	var (
+		volumeClaim   *corev1.PersistentVolumeClaimVolumeSource
+		objectStorage *v1alpha1.ObjectStorageSource
+		registry      *v1alpha1.RegistrySource
+	)
+	if len(o.volumeClaim) != 0 {
+		volumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: o.volumeClaim}
+	}
+	if len(o.s3Endpoint) != 0 || len(o.s3Bucket) != 0 {
+		objectStorage = &v1alpha1.ObjectStorageSource{
+			Endpoint:              o.s3Endpoint,
+			Bucket:                o.s3Bucket,
+			Prefix:                o.s3Prefix,
+			Region:                o.s3Region,
+			Insecure:              o.s3Insecure,
+			CredentialsSecretName: o.s3Secret,
+		}
+	}
+	if len(o.registry) != 0 {
+		registry = &v1alpha1.RegistrySource{
+			Repository:            o.registry,
+			Insecure:              o.registryInsecure,
+			CredentialsSecretName: o.registrySecret,
+		}
+	}
+
+	if err := util.ValidateStorage(volumeClaim, objectStorage, registry); err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid storage flags, %w", err)
+	}
+	return volumeClaim, objectStorage, registry, nil
+}
+
+func (o *storageOptions) compression() *v1alpha1.Compression {
+	if o.compressionLevel == 0 {
+		return nil
+	}
+	return &v1alpha1.Compression{Level: o.compressionLevel}
+}
+
+func (o *storageOptions) encryption() (*v1alpha1.Encryption, error) {
+	if len(o.encryptionSecret) == 0 && len(o.encryptionKeyID) == 0 {
+		return nil, nil
+	}
+	if len(o.encryptionSecret) == 0 || len(o.encryptionKeyID) == 0 {
+		return nil, fmt.Errorf("both --encryption-secret and --encryption-key-id should be specified")
+	}
+	return &v1alpha1.Encryption{SecretName: o.encryptionSecret, KeyID: o.encryptionKeyID}, nil
+}
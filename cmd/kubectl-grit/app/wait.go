@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+// waitOptions are flags for waiting the created resource to reach a terminal phase.
+type waitOptions struct {
+	wait     bool
+	timeout  time.Duration
+	interval time.Duration
+}
+
+func (o *waitOptions) addFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.wait, "wait", true, "wait until the created resource reaches a terminal phase, and phase changes are printed.")
+	fs.DurationVar(&o.timeout, "timeout", 30*time.Minute, "the time to wait for a terminal phase.")
+	o.interval = 2 * time.Second
+}
+
+// phaseStatus is the phase of Checkpoint, Restore or Migration with the reason and message of the condition of the phase.
+type phaseStatus struct {
+	kind     string
+	phase    string
+	reason   string
+	message  string
+	terminal bool
+	failed   bool
+}
+
+func statusOf(obj client.Object) phaseStatus {
+	var (
+		s     phaseStatus
+		conds []metav1.Condition
+	)
+	switch o := obj.(type) {
+	case *v1alpha1.Checkpoint:
+		s.kind, s.phase, conds = "checkpoint", string(o.Status.Phase), o.Status.Conditions
+		s.failed = o.Status.Phase == v1alpha1.CheckpointFailed || o.Status.Phase == v1alpha1.CheckpointCancelled
+		s.terminal = s.failed || o.Status.Phase == v1alpha1.Checkpointed
+	case *v1alpha1.Restore:
+		s.kind, s.phase, conds = "restore", string(o.Status.Phase), o.Status.Conditions
+		s.failed = o.Status.Phase == v1alpha1.RestoreFailed
+		s.terminal = s.failed || o.Status.Phase == v1alpha1.Restored
+	case *v1alpha1.Migration:
+		s.kind, s.phase, conds = "migration", string(o.Status.Phase), o.Status.Conditions
+		s.failed = o.Status.Phase == v1alpha1.MigrationFailed
+		s.terminal = s.failed || o.Status.Phase == v1alpha1.Migrated
+	}
+	if cond := meta.FindStatusCondition(conds, s.phase); cond != nil {
+		s.reason, s.message = cond.Reason, cond.Message
+	}
+	return s
+}
+
+// waitForPhase polls obj until it reaches a terminal phase, and each new phase is printed into out. an error is returned
+// when obj ends in a failed phase.
+func waitForPhase(ctx context.Context, c client.Client, out io.Writer, obj client.Object, o *waitOptions) error {
+	key := client.ObjectKeyFromObject(obj)
+	var last string
+	err := wait.PollUntilContextTimeout(ctx, o.interval, o.timeout, true, func(ctx context.Context) (bool, error) {
+		if err := c.Get(ctx, key, obj); err != nil {
+			return false, err
+		}
+		s := statusOf(obj)
+		if len(s.phase) != 0 && s.phase != last {
+			fmt.Fprintf(out, "%s/%s %s: %s\n", s.kind, key.Name, s.phase, s.message)
+			last = s.phase
+		}
+		return s.terminal, nil
+	})
+	s := statusOf(obj)
+	if err != nil {
+		return fmt.Errorf("failed to wait for %s/%s, %w", s.kind, key.Name, err)
+	}
+	if s.failed {
+		return fmt.Errorf("%s/%s is %s(%s): %s", s.kind, key.Name, s.phase, s.reason, s.message)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package app
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+func TestStatusOf(t *testing.T) {
+	testcases := map[string]struct {
+		obj      client.Object
+		expected phaseStatus
+	}{
+		"checkpoint is in progress": {
+			obj: &v1alpha1.Checkpoint{Status: v1alpha1.CheckpointStatus{Phase: v1alpha1.Checkpointing}},
+			expected: phaseStatus{
+				kind:  "checkpoint",
+				phase: string(v1alpha1.Checkpointing),
+			},
+		},
+		"checkpoint failed with condition": {
+			obj: &v1alpha1.Checkpoint{Status: v1alpha1.CheckpointStatus{
+				Phase: v1alpha1.CheckpointFailed,
+				Conditions: []metav1.Condition{
+					{Type: string(v1alpha1.CheckpointFailed), Status: metav1.ConditionTrue, Reason: "AgentFailed", Message: "criu dump failed"},
+				},
+			}},
+			expected: phaseStatus{
+				kind:     "checkpoint",
+				phase:    string(v1alpha1.CheckpointFailed),
+				reason:   "AgentFailed",
+				message:  "criu dump failed",
+				terminal: true,
+				failed:   true,
+			},
+		},
+		"restore is restored": {
+			obj: &v1alpha1.Restore{Status: v1alpha1.RestoreStatus{Phase: v1alpha1.Restored}},
+			expected: phaseStatus{
+				kind:     "restore",
+				phase:    string(v1alpha1.Restored),
+				terminal: true,
+			},
+		},
+		"migration failed": {
+			obj: &v1alpha1.Migration{Status: v1alpha1.MigrationStatus{Phase: v1alpha1.MigrationFailed}},
+			expected: phaseStatus{
+				kind:     "migration",
+				phase:    string(v1alpha1.MigrationFailed),
+				terminal: true,
+				failed:   true,
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := statusOf(tc.obj); got != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
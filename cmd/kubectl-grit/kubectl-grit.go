@@ -0,0 +1,20 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package main
+
+import (
+	"os"
+
+	"k8s.io/component-base/cli"
+
+	"github.com/kaito-project/grit/cmd/kubectl-grit/app"
+)
+
+func main() {
+	// kubectl-grit is a cli tool, so errors are printed by cobra instead of being logged.
+	command := app.NewKubectlGritCommand()
+	if err := cli.RunNoErrOutput(command); err != nil {
+		os.Exit(1)
+	}
+}
@@ -4,6 +4,8 @@
 package v1alpha1
 
 import (
+	"fmt"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -11,29 +13,240 @@ import (
 type CheckpointPhase string
 
 const (
-	CheckpointCreated       CheckpointPhase = "Created"
-	CheckpointPending       CheckpointPhase = "Pending"
-	Checkpointing           CheckpointPhase = "Checkpointing"
-	Checkpointed            CheckpointPhase = "Checkpointed"
+	CheckpointCreated CheckpointPhase = "Created"
+	CheckpointPending CheckpointPhase = "Pending"
+	Checkpointing     CheckpointPhase = "Checkpointing"
+	Checkpointed      CheckpointPhase = "Checkpointed"
+	CheckpointFailed  CheckpointPhase = "Failed"
+	// Deprecated: AutoMigrationSubmitting and AutoMigrationSubmitted are not set by grit-manager anymore, because pod is
+	// migrated by Migration resource. they're kept for Checkpoints created by earlier versions.
 	AutoMigrationSubmitting CheckpointPhase = "Submitting"
 	AutoMigrationSubmitted  CheckpointPhase = "Submitted"
-	CheckpointFailed        CheckpointPhase = "Failed"
+	// CheckpointCancelled means checkpoint is aborted by Cancel or deletion before it's checkpointed.
+	CheckpointCancelled CheckpointPhase = "Cancelled"
+)
+
+type CheckpointMode string
+
+const (
+	// CheckpointModeStop stops the workload after it's dumped, the pod is expected to be removed and restored from
+	// checkpointed data, like migration.
+	CheckpointModeStop CheckpointMode = "Stop"
+	// CheckpointModeSnapshot resumes the workload after it's dumped, so checkpoint can be used as a backup of a long-running
+	// workload, like a training job.
+	CheckpointModeSnapshot CheckpointMode = "Snapshot"
 )
 
+// CheckpointRolledBackCondition is the condition type which reports whether changes made on the pod for checkpointing
+// (like locked cuda state and private mounts) have been undone after checkpoint fails.
+const CheckpointRolledBackCondition = "RolledBack"
+
 type CheckpointSpec struct {
 	// PodName is used to specify pod for checkpointing. only pod in the same namespace of Checkpoint will be selected.
 	// +required
 	PodName string `json:"podName"`
+	// Containers is used to specify names of containers in the pod for checkpointing, and other containers(like logging
+	// and metrics sidecars) are not checkpointed and will be started fresh when restoring. all containers are checkpointed
+	// if it's not specified.
+	// +optional
+	Containers []string `json:"containers,omitempty"`
+	// Mode is used to specify whether the workload is stopped after it's checkpointed, Stop or Snapshot. Snapshot resumes
+	// the workload(including its cuda state) after criu dump, and it can't be used by Migration. default value is Stop.
+	// +kubebuilder:validation:Enum=Stop;Snapshot
+	// +kubebuilder:default=Stop
+	// +optional
+	Mode CheckpointMode `json:"mode,omitempty"`
 	// VolumeClaim is used to specify cloud storage for storing checkpoint data and share data across nodes.
 	// End user should ensure related pvc/pv resource exist and ready before creating Checkpoint resource.
+	// only one of VolumeClaim, ObjectStorage and Registry can be specified.
 	// +optional
 	VolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"volumeClaim,omitempty"`
-	// AutoMigration is used for migrating pod across nodes automatically. If true is set, related Restore resource will be created automatically, then checkpointed pod will be deleted by grit-manager, and a new pod will be created automatically by the pod owner(like Deployment and Job). this new pod will be selected as restoration pod and checkpointed data will be used for restoring new pod.
-	// This field can be set to true for the following two cases:
-	// 1. owner reference of pod is Deployment or Job.
-	// 2. VolumeClaim field is specified as a cloud storage, this means checkpointed data can be shared across nodes.
+	// AutoMigration was used for migrating pod across nodes automatically after it's checkpointed.
+	// Deprecated: AutoMigration is ignored, create a Migration resource for migrating pod instead. it will be removed in
+	// the next API version.
 	// +optional
 	AutoMigration bool `json:"autoMigration,omitempty"`
+	// ObjectStorage is used to specify a S3-compatible object storage for storing checkpoint data, grit agent uploads
+	// and downloads checkpoint data directly without mounting any volume.
+	// only one of VolumeClaim, ObjectStorage and Registry can be specified.
+	// +optional
+	ObjectStorage *ObjectStorageSource `json:"objectStorage,omitempty"`
+	// Registry is used to specify a container registry, checkpoint data is pushed as an OCI artifact into the registry
+	// by grit agent, and pulled by reference when restoring.
+	// only one of VolumeClaim, ObjectStorage and Registry can be specified.
+	// +optional
+	Registry *RegistrySource `json:"registry,omitempty"`
+	// ParentCheckpointName is used to specify a checkpointed Checkpoint of the same pod as parent, then only memory pages
+	// dirtied since the parent checkpoint will be dumped. Parent Checkpoint should use the same storage and Snapshot mode,
+	// and it can't be deleted until this Checkpoint is deleted.
+	// +optional
+	ParentCheckpointName string `json:"parentCheckpointName,omitempty"`
+	// Compression is used for packing checkpointed data into a zstd compressed stream before it's transferred into storage,
+	// and the stream is unpacked when restoring. it's useful for slow storage, because memory pages are mostly zero.
+	// +optional
+	Compression *Compression `json:"compression,omitempty"`
+	// Encryption is used for encrypting checkpointed data by grit agent before it's transferred into storage, and the data
+	// is only decrypted into the host path of the node where the pod is restored.
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
+	// RetentionPolicy is used for pruning checkpointed data automatically. Checkpoint will be deleted by grit-manager
+	// when it's out of the retention policy, and checkpointed data will be removed from storage volume and nodes.
+	// it's not supported for Registry, because checkpoint artifacts are kept in registry when Checkpoint is deleted.
+	// +optional
+	RetentionPolicy *RetentionPolicy `json:"retentionPolicy,omitempty"`
+	// ActiveDeadlineSeconds is the duration in seconds since Checkpoint is created, within which checkpoint should be
+	// completed including all retries. Checkpoint fails with DeadlineExceeded reason when it's exceeded, and grit agent
+	// job is removed, so a stuck criu dump or data transfer will not leave Checkpoint in Checkpointing state forever.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// BackoffLimit is the number of retries of grit agent when it fails on transient errors, like a flaky storage. retries
+	// are delayed with exponential backoff, and permanent errors(like criu dump failures) fail the Checkpoint at once.
+	// default value is 3.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+	// Cancel is used for aborting an in-progress checkpoint, grit agent job is removed and the pod is rolled back to
+	// running(like unlocking cuda state), then Checkpoint ends in Cancelled phase. the pod can only be kept running when
+	// checkpoint is cancelled before criu dump starts. Cancel can't be reverted once it's set.
+	// +optional
+	Cancel bool `json:"cancel,omitempty"`
+}
+
+type Compression struct {
+	// Level is the zstd compression level from 1(fastest) to 22(best compression). default value is 3.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=22
+	// +kubebuilder:default=3
+	// +optional
+	Level int32 `json:"level,omitempty"`
+	// Concurrency is the number of threads used by zstd encoder. default value is the number of cpus of the node.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
+}
+
+type Encryption struct {
+	// SecretName is the name of secret in the same namespace of Checkpoint, each entry of the secret is a 32 bytes AES-256
+	// key named by its key id. key id is recorded in encrypted data, so keys can be rotated by adding a new key into the
+	// secret and switching KeyID, and old keys should be kept in the secret until checkpoints encrypted by them are deleted.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	SecretName string `json:"secretName"`
+	// KeyID is the key in the secret which is used for encrypting checkpointed data.
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +kubebuilder:validation:MaxLength=253
+	// +required
+	KeyID string `json:"keyID"`
+}
+
+type RetentionPolicy struct {
+	// KeepLast is used to specify how many checkpointed Checkpoints are kept for the same pod owner(like Deployment and Job),
+	// or the same pod if pod has no owner. older Checkpoints will be deleted.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	KeepLast *int32 `json:"keepLast,omitempty"`
+	// TTLSecondsAfterCheckpointed is used to specify the lifetime of Checkpoint after it's checkpointed.
+	// Checkpoint will be deleted when ttl expired.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	TTLSecondsAfterCheckpointed *int32 `json:"ttlSecondsAfterCheckpointed,omitempty"`
+}
+
+type ObjectStorageSource struct {
+	// Endpoint is the address of S3-compatible object storage, like s3.amazonaws.com or minio.minio:9000.
+	// +required
+	Endpoint string `json:"endpoint"`
+	// Bucket is used for storing checkpoint data, and it should exist before creating Checkpoint resource.
+	// +required
+	Bucket string `json:"bucket"`
+	// Prefix is prepended to object keys, checkpoint data is stored under <prefix>/<namespace>/<checkpoint name>.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+	// Region of the bucket.
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Insecure is used to access object storage through http instead of https.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+	// CredentialsSecretName is the name of secret in the namespace of Checkpoint, the secret should contain access-key-id
+	// and secret-access-key. credentials of the node(like IAM role) are used if it's not specified.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+type RegistrySource struct {
+	// Repository is the base repository of checkpoint artifacts, like registry.kube-system:5000/grit. each Checkpoint is
+	// pushed into its own repository <repository>/<namespace>/<checkpoint name> with tag latest.
+	// +required
+	Repository string `json:"repository"`
+	// Insecure is used to access registry through http instead of https.
+	// +optional
+	Insecure bool `json:"insecure,omitempty"`
+	// CredentialsSecretName is the name of kubernetes.io/dockerconfigjson secret in the namespace of Checkpoint,
+	// it's used for pushing and pulling checkpoint artifact.
+	// +optional
+	CredentialsSecretName string `json:"credentialsSecretName,omitempty"`
+}
+
+type DataSize struct {
+	// Uncompressed is the total size in bytes of checkpointed data files.
+	Uncompressed int64 `json:"uncompressed"`
+	// Compressed is the size in bytes of compressed data in the storage, it's only set when compression is enabled.
+	// +optional
+	Compressed int64 `json:"compressed,omitempty"`
+}
+
+// StorageLocation is the location where checkpointed data is stored, only one of fields is set.
+type StorageLocation struct {
+	// PersistentVolumeClaim is set when checkpointed data is stored in the storage volume.
+	// +optional
+	PersistentVolumeClaim *PersistentVolumeClaimLocation `json:"persistentVolumeClaim,omitempty"`
+	// ObjectStorage is set when checkpointed data is stored in the object storage.
+	// +optional
+	ObjectStorage *ObjectStorageLocation `json:"objectStorage,omitempty"`
+	// Registry is set when checkpointed data is pushed into container registry.
+	// +optional
+	Registry *RegistryLocation `json:"registry,omitempty"`
+}
+
+type PersistentVolumeClaimLocation struct {
+	// ClaimName is the name of pvc.
+	ClaimName string `json:"claimName"`
+	// VolumeName is the name of pv which is bound to pvc.
+	VolumeName string `json:"volumeName"`
+	// Path is the directory in the storage volume.
+	Path string `json:"path"`
+}
+
+type ObjectStorageLocation struct {
+	// Endpoint is the address of object storage.
+	Endpoint string `json:"endpoint"`
+	// Bucket is the name of bucket.
+	Bucket string `json:"bucket"`
+	// Key is the common prefix of all objects of checkpointed data.
+	Key string `json:"key"`
+}
+
+type RegistryLocation struct {
+	// Reference is the reference of checkpoint artifact, like registry.kube-system:5000/grit/default/ckpt:latest.
+	Reference string `json:"reference"`
+}
+
+// String returns the url of storage location, like pvc://<volume name>/<path>, s3://<bucket>/<key> or oci://<reference>.
+func (l *StorageLocation) String() string {
+	switch {
+	case l == nil:
+		return ""
+	case l.PersistentVolumeClaim != nil:
+		return fmt.Sprintf("pvc://%s/%s", l.PersistentVolumeClaim.VolumeName, l.PersistentVolumeClaim.Path)
+	case l.ObjectStorage != nil:
+		return fmt.Sprintf("s3://%s/%s", l.ObjectStorage.Bucket, l.ObjectStorage.Key)
+	case l.Registry != nil:
+		return fmt.Sprintf("oci://%s", l.Registry.Reference)
+	}
+	return ""
 }
 
 type CheckpointStatus struct {
@@ -44,18 +257,34 @@ type CheckpointStatus struct {
 	// Checkpointed data can be used to restore for pod with same hash value.
 	// +optional
 	PodSpecHash string `json:"podSpecHash,omitempty"`
+	// PodIdentity is the identity of checkpointed pod within its owner, like the ordinal of StatefulSet pod or the
+	// completion index of Indexed Job pod. Checkpointed data can be used to restore for pod with the same identity.
+	// +optional
+	PodIdentity map[string]string `json:"podIdentity,omitempty"`
 	// PodUid is used for storing pod uid which will be used to construct log path of pod.
 	// +optional
 	PodUID string `json:"podUID,omitempty"`
-	// state machine of Checkpoint Phase: Created -->Pending --> Checkpointing --> Checkpointed --> Submitting --> Submitted or Failed.
+	// PodOwnerUID is used for storing uid of the controller owner of checkpointed pod, and it's used for grouping Checkpoints of
+	// the same workload in retention policy.
+	// +optional
+	PodOwnerUID string `json:"podOwnerUID,omitempty"`
+	// ParentCheckpoints is the chain of parent Checkpoints from the direct parent to the first full checkpoint.
+	// checkpointed data of all these Checkpoints is needed for restoring pod.
+	// +optional
+	ParentCheckpoints []string `json:"parentCheckpoints,omitempty"`
+	// state machine of Checkpoint Phase: Created -->Pending --> Checkpointing --> Checkpointed or Failed, and Cancelled when it's cancelled before checkpointed.
+	// use Migration resource for migrating checkpointed pod to another node.
 	// +optional
 	Phase CheckpointPhase `json:"phase,omitempty"`
 	// current state of pod checkpoint
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
-	// checkpointed data is stored under this path in the storage volume. and the data in this path will be used for restoring pod.
+	// DataSize is the size of checkpointed data, it's reported by grit agent after checkpointed data is transferred.
+	// +optional
+	DataSize *DataSize `json:"dataSize,omitempty"`
+	// StorageLocation is the location where checkpointed data is stored, and the data in this location will be used for restoring pod.
 	// +optional
-	DataPath string `json:"dataPath,omitempty"`
+	StorageLocation *StorageLocation `json:"storageLocation,omitempty"`
 }
 
 // Checkpoint is the Schema for the Checkpoints API
@@ -66,7 +295,12 @@ type CheckpointStatus struct {
 // +kubebuilder:printcolumn:name="Pod",type="string",JSONPath=".spec.podName",description="The pod will be checkpointed"
 // +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The phase of checkpoint action"
 // +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".status.nodeName",description="The node where pod is located"
-// +kubebuilder:printcolumn:name="Storage",type="string",JSONPath=".status.dataPath",description="Checkpointed data is stored here"
+// +kubebuilder:printcolumn:name="Parent",type="string",JSONPath=".spec.parentCheckpointName",description="The parent checkpoint of incremental checkpoint",priority=1
+// +kubebuilder:printcolumn:name="Size",type="integer",JSONPath=".status.dataSize.uncompressed",description="The size in bytes of checkpointed data",priority=1
+// +kubebuilder:printcolumn:name="Compressed",type="integer",JSONPath=".status.dataSize.compressed",description="The size in bytes of compressed data",priority=1
+// +kubebuilder:printcolumn:name="Volume",type="string",JSONPath=".status.storageLocation.persistentVolumeClaim.volumeName",description="Checkpointed data is stored in this volume"
+// +kubebuilder:printcolumn:name="Artifact",type="string",JSONPath=".status.storageLocation.registry.reference",description="Checkpointed data is pushed as this artifact",priority=1
+// +kubebuilder:printcolumn:name="Bucket",type="string",JSONPath=".status.storageLocation.objectStorage.bucket",description="Checkpointed data is stored in this bucket"
 type Checkpoint struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
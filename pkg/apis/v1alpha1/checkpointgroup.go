@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type CheckpointGroupPhase string
+
+const (
+	CheckpointGroupCreated       CheckpointGroupPhase = "Created"
+	CheckpointGroupCheckpointing CheckpointGroupPhase = "Checkpointing"
+	CheckpointGroupCheckpointed  CheckpointGroupPhase = "Checkpointed"
+	CheckpointGroupFailed        CheckpointGroupPhase = "Failed"
+)
+
+type CheckpointGroupSpec struct {
+	// OwnerRef is used for selecting pods for checkpointing, like all pods of a Job.
+	// Both OwnerRef and Selector are used for selecting pods, and you can choose to use either one of them.
+	// Pod will be selected when it has owner reference which equal to this owner reference.
+	// +optional
+	OwnerRef *metav1.OwnerReference `json:"ownerRef,omitempty"`
+	// Selector is also used for selecting pods for checkpointing.
+	// only running pods in the same namespace of CheckpointGroup will be selected.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Mode is used to specify whether member pods are stopped after they're checkpointed, Stop or Snapshot, and it will
+	// be set into each member Checkpoint. default value is Stop.
+	// +kubebuilder:validation:Enum=Stop;Snapshot
+	// +kubebuilder:default=Stop
+	// +optional
+	Mode CheckpointMode `json:"mode,omitempty"`
+	// VolumeClaim is used to specify cloud storage for storing checkpoint data, and it will be set into each member Checkpoint.
+	// Storage volume should be shared across nodes, because it's also used for synchronizing members before dumping.
+	// only one of VolumeClaim and ObjectStorage can be specified.
+	// +optional
+	VolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"volumeClaim,omitempty"`
+	// ObjectStorage is used to specify object storage for storing checkpoint data, and it will be set into each member Checkpoint.
+	// +optional
+	ObjectStorage *ObjectStorageSource `json:"objectStorage,omitempty"`
+	// Compression is used for compressing checkpoint data, and it will be set into each member Checkpoint.
+	// +optional
+	Compression *Compression `json:"compression,omitempty"`
+	// Encryption is used for encrypting checkpoint data, and it will be set into each member Checkpoint.
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
+	// FreezeTimeoutSeconds is the duration for waiting all member pods frozen. member pod will be unfrozen and
+	// its checkpoint will fail if other members are not frozen in time. default value is 300 seconds.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FreezeTimeoutSeconds *int32 `json:"freezeTimeoutSeconds,omitempty"`
+}
+
+type CheckpointGroupMember struct {
+	// PodName is the name of member pod.
+	PodName string `json:"podName"`
+	// CheckpointName is the name of Checkpoint which is created for member pod.
+	CheckpointName string `json:"checkpointName"`
+	// Phase is the phase of member Checkpoint.
+	// +optional
+	Phase CheckpointPhase `json:"phase,omitempty"`
+}
+
+type CheckpointGroupStatus struct {
+	// Members are pods selected when CheckpointGroup is created, and the member list will not be changed after that.
+	// +optional
+	Members []CheckpointGroupMember `json:"members,omitempty"`
+	// state machine of CheckpointGroup Phase: Created --> Checkpointing --> Checkpointed or Failed.
+	// CheckpointGroup is Checkpointed only when all member Checkpoints are checkpointed.
+	// +optional
+	Phase CheckpointGroupPhase `json:"phase,omitempty"`
+	// current state of checkpoint group
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CheckpointGroup is the Schema for the CheckpointGroups API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=checkpointgroups,scope=Namespaced,categories=girt,shortName={ckptgroup}
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The phase of checkpoint group"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type CheckpointGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CheckpointGroupSpec   `json:"spec"`
+	Status CheckpointGroupStatus `json:"status,omitempty"`
+}
+
+// CheckpointGroupList contains a list of CheckpointGroup
+// +kubebuilder:object:root=true
+type CheckpointGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CheckpointGroup `json:"items"`
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// CheckpointScheduleScheduled is the condition type which records the result of the last scheduled run.
+	CheckpointScheduleScheduled = "Scheduled"
+)
+
+type CheckpointScheduleSpec struct {
+	// Schedule is a cron expression in the standard five fields format, like "0 */2 * * *".
+	// a Checkpoint resource will be created for the selected pod at each scheduled time.
+	// +required
+	Schedule string `json:"schedule"`
+	// Suspend is used for stopping creating new Checkpoint resources. Checkpoint which has been created will not be affected.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+	// OwnerRef is used for selecting the pod for checkpointing.
+	// Both OwnerRef and Selector are used for selecting pod, and you can choose to use either one of them.
+	// Pod will be selected when it has owner reference which equal to this owner reference.
+	// +optional
+	OwnerRef *metav1.OwnerReference `json:"ownerRef,omitempty"`
+	// Selector is also used for selecting the pod for checkpointing.
+	// only running pod in the same namespace of CheckpointSchedule will be selected, and only one pod should be matched.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// VolumeClaim is used to specify cloud storage for storing checkpoint data, and it will be set into each created Checkpoint.
+	// +optional
+	VolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"volumeClaim,omitempty"`
+	// ObjectStorage is used to specify object storage for storing checkpoint data, and it will be set into each created Checkpoint.
+	// only one of VolumeClaim, ObjectStorage and Registry can be specified.
+	// +optional
+	ObjectStorage *ObjectStorageSource `json:"objectStorage,omitempty"`
+	// Registry is used to specify container registry for pushing checkpoint data, and it will be set into each created Checkpoint.
+	// +optional
+	Registry *RegistrySource `json:"registry,omitempty"`
+	// Compression is used for compressing checkpoint data, and it will be set into each created Checkpoint.
+	// +optional
+	Compression *Compression `json:"compression,omitempty"`
+	// Encryption is used for encrypting checkpoint data, and it will be set into each created Checkpoint.
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
+	// RetentionPolicy is set into each created Checkpoint, and it's used for pruning old Checkpoints created by this schedule.
+	// it's not supported for Registry, because checkpoint artifacts are kept in registry when Checkpoint is deleted.
+	// +optional
+	RetentionPolicy *RetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+type CheckpointScheduleStatus struct {
+	// LastScheduleTime is the last time when a run was scheduled, no matter the run is skipped or not.
+	// +optional
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// Generation is increased by one every time a Checkpoint is created, and Checkpoint is named as <schedule name>-<generation>.
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+	// Active is the name of Checkpoint which is still in progress. new run will be skipped until this Checkpoint completed.
+	// +optional
+	Active string `json:"active,omitempty"`
+	// LastSuccessfulCheckpoint is the name of the latest Checkpoint which has completed checkpoint process.
+	// +optional
+	LastSuccessfulCheckpoint string `json:"lastSuccessfulCheckpoint,omitempty"`
+	// LastSuccessfulTime is the time when LastSuccessfulCheckpoint is checkpointed.
+	// +optional
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+	// current state of checkpoint schedule
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CheckpointSchedule is the Schema for the CheckpointSchedules API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=checkpointschedules,scope=Namespaced,categories=girt,shortName={ckptsched}
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="The cron expression of schedule"
+// +kubebuilder:printcolumn:name="Suspend",type="boolean",JSONPath=".spec.suspend",description="Whether the schedule is suspended"
+// +kubebuilder:printcolumn:name="Active",type="string",JSONPath=".status.active",description="The checkpoint in progress"
+// +kubebuilder:printcolumn:name="LastSuccessful",type="string",JSONPath=".status.lastSuccessfulCheckpoint",description="The latest checkpointed checkpoint"
+// +kubebuilder:printcolumn:name="LastSchedule",type="date",JSONPath=".status.lastScheduleTime",description="The last time a run was scheduled"
+type CheckpointSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CheckpointScheduleSpec   `json:"spec"`
+	Status CheckpointScheduleStatus `json:"status,omitempty"`
+}
+
+// CheckpointScheduleList contains a list of CheckpointSchedule
+// +kubebuilder:object:root=true
+type CheckpointScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CheckpointSchedule `json:"items"`
+}
@@ -11,8 +11,69 @@ const (
 	// annotations for restoration pod
 	CheckpointDataPathLabel = "grit.dev/checkpoint"
 	RestoreNameLabel        = "grit.dev/restore-name"
+	// CheckpointContainersAnnotation is the checkpointed containers joined with comma, it's also set on restore resource.
+	// containers out of the list are started normally by grit shim. all containers are restored if it's not specified.
+	CheckpointContainersAnnotation = "grit.dev/checkpoint-containers"
 
 	// annotations for restore resource
 	PodSpecHashLabel            = "grit.dev/pod-spec-hash"
 	RestorationPodSelectedLabel = "grit.dev/pod-selected"
+	// PodIdentityAnnotation is the json encoded identity of checkpointed pod within its owner.
+	PodIdentityAnnotation = "grit.dev/pod-identity"
+
+	// label for checkpoint created by checkpoint schedule
+	CheckpointScheduleLabel = "grit.dev/checkpoint-schedule"
+
+	// label for checkpoint created by checkpoint group, and label for restore created by restore group
+	CheckpointGroupLabel = "grit.dev/checkpoint-group"
+	RestoreGroupLabel    = "grit.dev/restore-group"
+	// annotation for checkpoint created by checkpoint group, all member checkpoints are joined with comma.
+	CheckpointGroupMembersAnnotation = "grit.dev/checkpoint-group-members"
+	// annotation for checkpoint created by checkpoint group, it specifies the timeout of waiting all members frozen.
+	CheckpointGroupFreezeTimeoutAnnotation = "grit.dev/checkpoint-group-freeze-timeout"
+
+	// label for checkpoint and restore created by migration
+	MigrationLabel = "grit.dev/migration"
+	// label for migration created by node migration
+	NodeMigrationLabel = "grit.dev/node-migration"
+	// CheckpointBeforeEvictionAnnotation opts pod in checkpointing before eviction, its value is a json encoded
+	// MigrationSpec without podName, like {"volumeClaim":{"claimName":"checkpoint-pvc"}}.
+	CheckpointBeforeEvictionAnnotation = "grit.dev/checkpoint-before-eviction"
+	// label for migration created before eviction, its value is the uid of evicted pod.
+	EvictedPodUIDLabel = "grit.dev/evicted-pod-uid"
+	// CheckpointOnPreemptionAnnotation opts pod in emergency checkpoint when its node will be preempted, its value is a
+	// json encoded CheckpointSpec without podName, like {"volumeClaim":{"claimName":"checkpoint-pvc"}}.
+	CheckpointOnPreemptionAnnotation = "grit.dev/checkpoint-on-preemption"
+	// label for emergency checkpoint created by node preemption, its value is the name of preempted node. grit agent of
+	// emergency checkpoint runs with a higher priority than routine checkpoints.
+	EmergencyCheckpointLabel = "grit.dev/emergency-checkpoint"
+	// annotation for checkpoint which should be completed before the deadline, its value is a RFC3339 time.
+	CheckpointDeadlineAnnotation = "grit.dev/checkpoint-deadline"
+
+	// CheckpointRequestAnnotation requests a checkpoint of pod, a new Checkpoint is created for each new token value
+	// by the template in CheckpointTemplateAnnotation, which is a json encoded CheckpointSpec without podName.
+	CheckpointRequestAnnotation  = "grit.dev/checkpoint-request"
+	CheckpointTemplateAnnotation = "grit.dev/checkpoint-template"
+	// annotations written back into pod for the observed checkpoint request, they are the token of request, the name,
+	// phase and data path of Checkpoint created for the request.
+	CheckpointRequestObservedAnnotation   = "grit.dev/checkpoint-request-observed"
+	CheckpointRequestCheckpointAnnotation = "grit.dev/checkpoint-request-checkpoint"
+	CheckpointRequestPhaseAnnotation      = "grit.dev/checkpoint-request-phase"
+	CheckpointRequestDataPathAnnotation   = "grit.dev/checkpoint-request-data-path"
+	// label for checkpoint created by checkpoint request, its value is the name of pod.
+	CheckpointRequestPodLabel = "grit.dev/checkpoint-request-pod"
+
+	// finalizer for removing checkpointed data when checkpoint is deleted
+	CheckpointDataFinalizer = "grit.dev/checkpoint-data"
+	// finalizer for keeping checkpoint agent pod until its report is read after checkpoint is cancelled
+	GritAgentReportFinalizer = "grit.dev/agent-report"
+	// label for grit agent job which is used for cleaning up checkpointed data
+	CheckpointCleanupLabel = "grit.dev/cleanup-checkpoint"
+	// TraceParentAnnotation is the W3C traceparent of the trace of Checkpoint or Restore, it's also set on restoration
+	// pod, so containerd shim adds spans of restoring containers into the trace of Restore.
+	TraceParentAnnotation = "grit.dev/trace-parent"
+
+	// keys of credentials secret for object storage
+	ObjectStorageAccessKeyIDKey     = "access-key-id"
+	ObjectStorageSecretAccessKeyKey = "secret-access-key"
 )
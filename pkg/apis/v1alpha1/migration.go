@@ -0,0 +1,127 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type MigrationPhase string
+
+const (
+	MigrationCreated       MigrationPhase = "Created"
+	MigrationCheckpointing MigrationPhase = "Checkpointing"
+	MigrationRemovingPod   MigrationPhase = "RemovingPod"
+	MigrationRestoring     MigrationPhase = "Restoring"
+	Migrated               MigrationPhase = "Migrated"
+	MigrationFailed        MigrationPhase = "Failed"
+)
+
+type PodRemovalPolicy string
+
+const (
+	// PodRemovalDelete deletes checkpointed pod directly.
+	PodRemovalDelete PodRemovalPolicy = "Delete"
+	// PodRemovalEvict evicts checkpointed pod through eviction api, so PodDisruptionBudget is respected.
+	PodRemovalEvict PodRemovalPolicy = "Evict"
+)
+
+type MigrationSpec struct {
+	// PodName is used to specify pod for migrating. only pod in the same namespace of Migration will be selected.
+	// the pod should have a controller owner(like Deployment and Job), because a new pod is recreated by the owner after
+	// checkpointed pod is removed, and the new pod will be selected as restoration pod.
+	// +required
+	PodName string `json:"podName"`
+	// VolumeClaim is used to specify cloud storage for storing checkpoint data and share data across nodes.
+	// only one of VolumeClaim, ObjectStorage and Registry can be specified.
+	// +optional
+	VolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"volumeClaim,omitempty"`
+	// ObjectStorage is used to specify object storage for storing checkpoint data.
+	// +optional
+	ObjectStorage *ObjectStorageSource `json:"objectStorage,omitempty"`
+	// Registry is used to specify container registry for pushing checkpoint data.
+	// +optional
+	Registry *RegistrySource `json:"registry,omitempty"`
+	// Compression is used for compressing checkpoint data, and it will be set into Checkpoint created by Migration.
+	// +optional
+	Compression *Compression `json:"compression,omitempty"`
+	// Encryption is used for encrypting checkpoint data, and it will be set into Checkpoint created by Migration.
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
+	// TargetNodeName is used to specify the node where restoration pod should be located.
+	// +optional
+	TargetNodeName string `json:"targetNodeName,omitempty"`
+	// NodeSelector is used to constrain restoration pod to nodes with these labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// PodRemovalPolicy is used to specify how checkpointed pod is removed, Delete or Evict. default value is Delete.
+	// +kubebuilder:validation:Enum=Delete;Evict
+	// +kubebuilder:default=Delete
+	// +optional
+	PodRemovalPolicy PodRemovalPolicy `json:"podRemovalPolicy,omitempty"`
+	// BackoffLimit is the number of retries for failed steps before marking Migration failed. default value is 3.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+type MigrationStatus struct {
+	// SourceNodeName is the node where checkpointed pod is located.
+	// +optional
+	SourceNodeName string `json:"sourceNodeName,omitempty"`
+	// OwnerRef is the controller owner of checkpointed pod, and it's used for selecting restoration pod.
+	// +optional
+	OwnerRef *metav1.OwnerReference `json:"ownerRef,omitempty"`
+	// CheckpointName is the name of Checkpoint created by Migration for the current attempt.
+	// +optional
+	CheckpointName string `json:"checkpointName,omitempty"`
+	// RestoreName is the name of Restore created by Migration for the current attempt.
+	// +optional
+	RestoreName string `json:"restoreName,omitempty"`
+	// TargetPod is the restoration pod.
+	// +optional
+	TargetPod string `json:"targetPod,omitempty"`
+	// TargetNodeName is the node where restoration pod is located.
+	// +optional
+	TargetNodeName string `json:"targetNodeName,omitempty"`
+	// Retries is the number of retries which have been made for failed steps.
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+	// Downtime is the duration from the start of checkpointing pod to restoration pod running.
+	// +optional
+	Downtime *metav1.Duration `json:"downtime,omitempty"`
+	// state machine of Migration Phase: Created --> Checkpointing --> RemovingPod --> Restoring --> Migrated or Failed.
+	// +optional
+	Phase MigrationPhase `json:"phase,omitempty"`
+	// current state of migration, each step has a condition with the same type of phase.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// Migration is the Schema for the Migrations API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=migrations,scope=Namespaced,categories=girt,shortName={mig}
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Pod",type="string",JSONPath=".spec.podName",description="The pod will be migrated"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The phase of migration"
+// +kubebuilder:printcolumn:name="Source",type="string",JSONPath=".status.sourceNodeName",description="The node where pod is checkpointed"
+// +kubebuilder:printcolumn:name="Target",type="string",JSONPath=".status.targetNodeName",description="The node where pod is restored"
+// +kubebuilder:printcolumn:name="Downtime",type="string",JSONPath=".status.downtime",description="The downtime of pod"
+type Migration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MigrationSpec   `json:"spec"`
+	Status MigrationStatus `json:"status,omitempty"`
+}
+
+// MigrationList contains a list of Migration
+// +kubebuilder:object:root=true
+type MigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Migration `json:"items"`
+}
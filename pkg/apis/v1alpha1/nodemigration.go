@@ -0,0 +1,154 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type NodeMigrationPhase string
+
+const (
+	NodeMigrationCreated   NodeMigrationPhase = "Created"
+	NodeMigrationMigrating NodeMigrationPhase = "Migrating"
+	NodeMigrationCompleted NodeMigrationPhase = "Completed"
+	NodeMigrationFailed    NodeMigrationPhase = "Failed"
+)
+
+type NodePolicy string
+
+const (
+	// NodePolicyKeepCordoned keeps node cordoned after all pods are migrated, like the node will be drained or upgraded.
+	NodePolicyKeepCordoned NodePolicy = "KeepCordoned"
+	// NodePolicyUncordon uncordons node after all pods are migrated, but node is not uncordoned if it has been cordoned
+	// before NodeMigration starts.
+	NodePolicyUncordon NodePolicy = "Uncordon"
+)
+
+type PodMigrationPhase string
+
+const (
+	// PodMigrationPending means pod is waiting for a Migration because of the parallelism limit.
+	PodMigrationPending   PodMigrationPhase = "Pending"
+	PodMigrationMigrating PodMigrationPhase = "Migrating"
+	PodMigrationMigrated  PodMigrationPhase = "Migrated"
+	PodMigrationFailed    PodMigrationPhase = "Failed"
+	// PodMigrationSkipped means pod is selected but can't be migrated, like pod without controller owner.
+	PodMigrationSkipped PodMigrationPhase = "Skipped"
+)
+
+type NodeMigrationSpec struct {
+	// NodeName is the node whose pods will be migrated to other nodes, and the node is cordoned during migration.
+	// +required
+	NodeName string `json:"nodeName"`
+	// RuntimeClassName is used for selecting GRIT enabled pods on the node, only pods with this runtime class are
+	// migrated. default value is grit.
+	// +kubebuilder:default=grit
+	// +optional
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+	// Selector is a label query over pods on the node, only matched pods are migrated.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// Parallelism is the maximum number of pods which are migrated at the same time. default value is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	Parallelism int32 `json:"parallelism,omitempty"`
+	// NodePolicy is used to specify whether node is uncordoned after migration, KeepCordoned or Uncordon. default value is KeepCordoned.
+	// +kubebuilder:validation:Enum=KeepCordoned;Uncordon
+	// +kubebuilder:default=KeepCordoned
+	// +optional
+	NodePolicy NodePolicy `json:"nodePolicy,omitempty"`
+	// VolumeClaim is used to specify cloud storage for storing checkpoint data and share data across nodes.
+	// the claim is looked up in the namespace of each pod. only one of VolumeClaim, ObjectStorage and Registry can be specified.
+	// +optional
+	VolumeClaim *corev1.PersistentVolumeClaimVolumeSource `json:"volumeClaim,omitempty"`
+	// ObjectStorage is used to specify object storage for storing checkpoint data.
+	// +optional
+	ObjectStorage *ObjectStorageSource `json:"objectStorage,omitempty"`
+	// Registry is used to specify container registry for pushing checkpoint data.
+	// +optional
+	Registry *RegistrySource `json:"registry,omitempty"`
+	// Compression is used for compressing checkpoint data, and it will be set into Migration created for each pod.
+	// +optional
+	Compression *Compression `json:"compression,omitempty"`
+	// Encryption is used for encrypting checkpoint data, and it will be set into Migration created for each pod.
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
+	// NodeSelector is used to constrain restoration pods to nodes with these labels.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// PodRemovalPolicy is used to specify how checkpointed pods are removed, Delete or Evict. default value is Evict.
+	// +kubebuilder:validation:Enum=Delete;Evict
+	// +kubebuilder:default=Evict
+	// +optional
+	PodRemovalPolicy PodRemovalPolicy `json:"podRemovalPolicy,omitempty"`
+	// BackoffLimit is the number of retries for failed steps of each Migration. default value is 3.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
+
+// PodMigrationStatus is the migration progress of a pod on the node.
+type PodMigrationStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// MigrationName is the name of Migration created for the pod.
+	// +optional
+	MigrationName string `json:"migrationName,omitempty"`
+	// +optional
+	Phase PodMigrationPhase `json:"phase,omitempty"`
+	// Reason and Message explain why pod is skipped or failed to migrate.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+type NodeMigrationStatus struct {
+	// Cordoned is true when node is cordoned by NodeMigration, and only this kind of node is uncordoned by NodePolicy.
+	// +optional
+	Cordoned bool `json:"cordoned,omitempty"`
+	// Pods is the migration progress of pods on the node.
+	// +optional
+	Pods []PodMigrationStatus `json:"pods,omitempty"`
+	// MigratedPods is the number of pods which have been migrated.
+	// +optional
+	MigratedPods int32 `json:"migratedPods,omitempty"`
+	// FailedPods is the number of pods which are skipped or failed to migrate.
+	// +optional
+	FailedPods int32 `json:"failedPods,omitempty"`
+	// state machine of NodeMigration Phase: Created --> Migrating --> Completed or Failed.
+	// +optional
+	Phase NodeMigrationPhase `json:"phase,omitempty"`
+	// current state of node migration, each step has a condition with the same type of phase.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// NodeMigration is the Schema for the NodeMigrations API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=nodemigrations,scope=Cluster,categories=girt,shortName={nmig}
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Node",type="string",JSONPath=".spec.nodeName",description="The node whose pods will be migrated"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The phase of node migration"
+// +kubebuilder:printcolumn:name="Migrated",type="integer",JSONPath=".status.migratedPods",description="The number of migrated pods"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedPods",description="The number of pods which can't be migrated"
+type NodeMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NodeMigrationSpec   `json:"spec"`
+	Status NodeMigrationStatus `json:"status,omitempty"`
+}
+
+// NodeMigrationList contains a list of NodeMigration
+// +kubebuilder:object:root=true
+type NodeMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NodeMigration `json:"items"`
+}
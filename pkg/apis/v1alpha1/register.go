@@ -21,6 +21,16 @@ var (
 			&CheckpointList{},
 			&Restore{},
 			&RestoreList{},
+			&CheckpointSchedule{},
+			&CheckpointScheduleList{},
+			&CheckpointGroup{},
+			&CheckpointGroupList{},
+			&RestoreGroup{},
+			&RestoreGroupList{},
+			&Migration{},
+			&MigrationList{},
+			&NodeMigration{},
+			&NodeMigrationList{},
 		)
 		metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 		return nil
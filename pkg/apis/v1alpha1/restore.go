@@ -34,6 +34,24 @@ type RestoreSpec struct {
 	// and recommend to use selector for standalone pod.
 	// +optional
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+	// TargetNodeName is used to constrain restoration pod to the specified node, node affinity will be added into the pod.
+	// +optional
+	TargetNodeName string `json:"targetNodeName,omitempty"`
+	// NodeSelector is used to constrain restoration pod to nodes with these labels, it will be merged into node selector of the pod.
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// ActiveDeadlineSeconds is the duration in seconds since Restore is created, within which restoration pod should be
+	// restored and running. Restore fails with DeadlineExceeded reason when it's exceeded, and grit agent job is removed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+	// BackoffLimit is the number of retries of grit agent when it fails on transient errors, like a flaky storage. retries
+	// are delayed with exponential backoff, and permanent errors(like corrupted checkpointed data) fail the Restore at once.
+	// default value is 3.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
 }
 
 type RestoreStatus struct {
@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type RestoreGroupPhase string
+
+const (
+	RestoreGroupCreated   RestoreGroupPhase = "Created"
+	RestoreGroupRestoring RestoreGroupPhase = "Restoring"
+	RestoreGroupRestored  RestoreGroupPhase = "Restored"
+	RestoreGroupFailed    RestoreGroupPhase = "Failed"
+)
+
+type RestoreGroupSpec struct {
+	// CheckpointGroupName is used to specify CheckpointGroup resource. only CheckpointGroup in the same namespace of
+	// RestoreGroup will be selected, and a Restore will be created for each member Checkpoint.
+	// +required
+	CheckpointGroupName string `json:"checkpointGroupName"`
+	// OwnerRef is used for selecting restoration pods, and it will be set into each member Restore.
+	// +optional
+	OwnerRef metav1.OwnerReference `json:"ownerRef,omitempty"`
+	// Selector is also used for selecting restoration pods, and it will be set into each member Restore.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+type RestoreGroupMember struct {
+	// CheckpointName is the name of member Checkpoint in CheckpointGroup.
+	CheckpointName string `json:"checkpointName"`
+	// RestoreName is the name of Restore which is created for member Checkpoint.
+	RestoreName string `json:"restoreName"`
+	// TargetPod is the pod selected for restoring member Checkpoint.
+	// +optional
+	TargetPod string `json:"targetPod,omitempty"`
+	// Phase is the phase of member Restore.
+	// +optional
+	Phase RestorePhase `json:"phase,omitempty"`
+}
+
+type RestoreGroupStatus struct {
+	// +optional
+	Members []RestoreGroupMember `json:"members,omitempty"`
+	// state machine of RestoreGroup Phase: Created --> Restoring --> Restored or Failed.
+	// RestoreGroup is Restored only when all member Restores are restored.
+	// +optional
+	Phase RestoreGroupPhase `json:"phase,omitempty"`
+	// current state of restore group
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// RestoreGroup is the Schema for the RestoreGroups API
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=restoregroups,scope=Namespaced,categories=girt,shortName={rtgroup}
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="CheckpointGroup",type="string",JSONPath=".spec.checkpointGroupName",description="The checkpoint group will be used for restoring"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="The phase of restore group"
+type RestoreGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RestoreGroupSpec   `json:"spec"`
+	Status RestoreGroupStatus `json:"status,omitempty"`
+}
+
+// RestoreGroupList contains a list of RestoreGroup
+// +kubebuilder:object:root=true
+type RestoreGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RestoreGroup `json:"items"`
+}
@@ -5,17 +5,182 @@ package checkpoint
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	crmetadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
 	"github.com/kaito-project/grit/pkg/gritagent/copy"
+	"github.com/kaito-project/grit/pkg/gritagent/storage"
+	"github.com/kaito-project/grit/pkg/metadata"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
+var tracer = otel.Tracer("github.com/kaito-project/grit/pkg/gritagent/checkpoint")
+
 func RunCheckpoint(ctx context.Context, opts *options.GritAgentOptions) error {
+	runCtx := ctx
+	if len(opts.Deadline) != 0 {
+		deadline, err := time.Parse(time.RFC3339, opts.Deadline)
+		if err != nil {
+			return fmt.Errorf("invalid deadline %s, %w", opts.Deadline, err)
+		}
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+
+	err := runCheckpoint(runCtx, opts)
+	if err == nil {
+		return nil
+	}
+
+	// failure is reported to grit manager, so checkpoint fails with a clear reason instead of a general agent failure,
+	// and whether the pod has been rolled back is recorded in its condition.
+	report := &metadata.AgentReport{}
+	var rolledBackErr *RolledBackError
+	if errors.As(err, &rolledBackErr) {
+		report.RolledBack = rolledBackErr.RolledBack()
+		report.StoppedContainers = rolledBackErr.StoppedContainers
+		if rolledBackErr.RollbackErr != nil {
+			report.RollbackError = rolledBackErr.RollbackErr.Error()
+		}
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		// grit agent is terminated when checkpoint is cancelled, the pod has been rolled back by now, and half-written
+		// data is removed, so it will not be mistaken for checkpointed data.
+		log.FromContext(ctx).Info("checkpoint is cancelled, remove half-written data", "dir", opts.HostWorkPath)
+		if removeErr := removeDirContents(opts.HostWorkPath); removeErr != nil {
+			log.FromContext(ctx).Error(removeErr, "failed to remove half-written data", "dir", opts.HostWorkPath)
+		}
+		err = fmt.Errorf("checkpoint is cancelled, %w", err)
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		report.DeadlineExceeded = true
+		err = metadata.Permanent(fmt.Errorf("checkpoint is not completed before deadline %s, %w", opts.Deadline, err))
+	}
+
+	if reportErr := metadata.WriteAgentReport(opts.TerminationMessagePath, report); reportErr != nil {
+		log.FromContext(ctx).Error(reportErr, "failed to write report of grit agent")
+	}
+	return err
+}
+
+func runCheckpoint(ctx context.Context, opts *options.GritAgentOptions) error {
+	store, err := storage.NewStorage(&opts.StorageOptions)
+	if err != nil {
+		return err
+	}
+
 	// execute checkpoint
-	if err := RuntimeCheckpointPod(ctx, &opts.RuntimeCheckpointOptions); err != nil {
+	var barrier func(context.Context) error
+	if len(opts.GroupMembers) != 0 {
+		// sentinel of the previous attempt is cleared before the pod is frozen, and it's cleared again if this attempt
+		// fails, because other members are still waiting for this member frozen together with them.
+		if err := thawGroupMember(ctx, opts, store); err != nil {
+			return err
+		}
+		barrier = func(ctx context.Context) error {
+			return waitForGroupFrozen(ctx, opts, store)
+		}
+	}
+	dumpStart := time.Now()
+	if err := RuntimeCheckpointPod(ctx, &opts.RuntimeCheckpointOptions, barrier); err != nil {
+		if barrier != nil {
+			if thawErr := thawGroupMember(context.WithoutCancel(ctx), opts, store); thawErr != nil {
+				log.FromContext(ctx).Error(thawErr, "failed to clear sentinel of checkpoint group member")
+			}
+		}
+		return err
+	}
+	dumpSeconds := time.Since(dumpStart).Seconds()
+
+	// generate integrity manifest which is used for verifying checkpointed data before restore
+	if err := metadata.WriteManifest(opts.SrcDir); err != nil {
 		return err
 	}
 
 	// transfer checkpointed data to cloud storage
-	return copy.TransferData(ctx, opts.SrcDir, opts.DstDir)
+	transferStart := time.Now()
+	transferCtx, span := tracer.Start(ctx, "transfer")
+	storedSize, err := store.Upload(transferCtx, opts.SrcDir, opts.DstDir)
+	span.SetAttributes(attribute.String("grit.storage_type", opts.StorageType), attribute.Int64("grit.transferred_bytes", storedSize))
+	tracing.EndSpan(span, err)
+	if err != nil {
+		return err
+	}
+
+	// report data size and durations to grit manager through termination message
+	size, err := copy.DirSize(opts.SrcDir)
+	if err != nil {
+		return err
+	}
+	report := &metadata.AgentReport{
+		UncompressedSize: size,
+		TransferredSize:  storedSize,
+		DumpSeconds:      dumpSeconds,
+		TransferSeconds:  time.Since(transferStart).Seconds(),
+	}
+	if opts.CompressionLevel != 0 {
+		report.CompressedSize = storedSize
+	}
+	if report.DumpedSize, report.RootfsDiffSize, err = containerDataSizes(opts.SrcDir); err != nil {
+		return err
+	}
+	return metadata.WriteAgentReport(opts.TerminationMessagePath, report)
+}
+
+// containerDataSizes returns the total size of criu images and rootfs diff tarballs of all containers checkpointed in dir.
+func containerDataSizes(dir string) (int64, int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var dumped, rootfsDiff int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		size, err := copy.DirSize(filepath.Join(dir, entry.Name(), crmetadata.CheckpointDirectory))
+		if err != nil && !os.IsNotExist(err) {
+			return 0, 0, err
+		}
+		dumped += size
+
+		info, err := os.Stat(filepath.Join(dir, entry.Name(), crmetadata.RootFsDiffTar))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return 0, 0, err
+		}
+		rootfsDiff += info.Size()
+	}
+	return dumped, rootfsDiff, nil
+}
+
+// removeDirContents removes all entries in dir, and dir itself is kept because it's the mount point of host path volume.
+func removeDirContents(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
 }
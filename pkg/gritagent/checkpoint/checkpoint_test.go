@@ -0,0 +1,59 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveDirContents(t *testing.T) {
+	t.Run("dir does not exist", func(t *testing.T) {
+		if err := removeDirContents(filepath.Join(t.TempDir(), "nonexistent")); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+	})
+
+	t.Run("half-written data is removed and dir is kept", func(t *testing.T) {
+		dir := t.TempDir()
+		os.MkdirAll(filepath.Join(dir, "app-work", "checkpoint"), 0755)
+		os.WriteFile(filepath.Join(dir, "app-work", "checkpoint", "pages-1.img"), []byte("pages"), 0644)
+		os.WriteFile(filepath.Join(dir, "manifest.json"), []byte("{}"), 0644)
+
+		if err := removeDirContents(dir); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("expected dir is kept, got %v", err)
+		}
+		if len(entries) != 0 {
+			t.Errorf("expected dir is empty, got %d entries", len(entries))
+		}
+	})
+}
+
+func TestContainerDataSizes(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "app", "checkpoint"), 0755)
+	os.WriteFile(filepath.Join(dir, "app", "checkpoint", "pages-1.img"), make([]byte, 100), 0644)
+	os.WriteFile(filepath.Join(dir, "app", "checkpoint", "core-1.img"), make([]byte, 20), 0644)
+	os.WriteFile(filepath.Join(dir, "app", "rootfs-diff.tar"), make([]byte, 30), 0644)
+	os.MkdirAll(filepath.Join(dir, "sidecar", "checkpoint"), 0755)
+	os.WriteFile(filepath.Join(dir, "sidecar", "checkpoint", "pages-1.img"), make([]byte, 5), 0644)
+	os.WriteFile(filepath.Join(dir, "manifest.json"), []byte("{}"), 0644)
+
+	dumped, rootfsDiff, err := containerDataSizes(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if dumped != 125 {
+		t.Errorf("expected dumped size 125, got %d", dumped)
+	}
+	if rootfsDiff != 30 {
+		t.Errorf("expected rootfs diff size 30, got %d", rootfsDiff)
+	}
+}
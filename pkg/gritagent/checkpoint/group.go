@@ -0,0 +1,124 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"path"
+	"time"
+
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+	"github.com/kaito-project/grit/pkg/gritagent/storage"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+// groupSentinel is the content of the sentinel file of a member of checkpoint group. Attempt is generated randomly
+// when the member is frozen, and it's empty when the member is not frozen, like before the pod is frozen and after the
+// pod is rolled back. Acks holds the attempts of other members which have been seen frozen by this member.
+type groupSentinel struct {
+	Attempt string            `json:"attempt,omitempty"`
+	Acks    map[string]string `json:"acks,omitempty"`
+}
+
+// waitForGroupFrozen marks this member as frozen in the storage, then waits for all members of checkpoint group
+// frozen. storage is shared by all members, and data of each member is stored in the sibling directory of dst-dir.
+// a member only passes the barrier when every member has acknowledged its current attempt, so the sentinel of a
+// previous attempt(like an agent which was killed while waiting) is never mistaken for a frozen member, and clocks of
+// different nodes are not compared.
+func waitForGroupFrozen(ctx context.Context, opts *options.GritAgentOptions, store storage.Storage) error {
+	self := path.Base(opts.DstDir)
+	sentinel := &groupSentinel{Attempt: utilrand.String(10), Acks: map[string]string{}}
+	if err := writeGroupSentinel(ctx, store, opts.DstDir, sentinel); err != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("member is frozen, wait for other members of checkpoint group", "members", opts.GroupMembers, "attempt", sentinel.Attempt, "timeout", opts.GroupFreezeTimeout)
+
+	var pending []string
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, opts.GroupFreezeTimeout, true, func(ctx context.Context) (bool, error) {
+		members := make(map[string]*groupSentinel, len(opts.GroupMembers))
+		acks := make(map[string]string, len(opts.GroupMembers))
+		for _, member := range opts.GroupMembers {
+			memberSentinel, err := readGroupSentinel(ctx, store, path.Join(path.Dir(opts.DstDir), member))
+			if err != nil {
+				return false, err
+			}
+			members[member] = memberSentinel
+			if memberSentinel != nil && len(memberSentinel.Attempt) != 0 {
+				acks[member] = memberSentinel.Attempt
+			}
+		}
+
+		// acknowledge current attempts of frozen members, so they know this member is frozen together with them.
+		if !maps.Equal(acks, sentinel.Acks) {
+			sentinel.Acks = acks
+			if err := writeGroupSentinel(ctx, store, opts.DstDir, sentinel); err != nil {
+				return false, err
+			}
+		}
+
+		pending = pending[:0]
+		for _, member := range opts.GroupMembers {
+			if member == self {
+				continue
+			}
+			if memberSentinel := members[member]; len(acks[member]) == 0 || memberSentinel.Acks[self] != sentinel.Attempt {
+				pending = append(pending, member)
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("members %v of checkpoint group are not frozen: %w", pending, err)
+	}
+
+	log.FromContext(ctx).Info("all members of checkpoint group are frozen")
+	return nil
+}
+
+// thawGroupMember marks this member as not frozen in the storage. it's called before the pod is frozen and after the
+// pod is rolled back, so other members don't pass the barrier with the sentinel of a previous attempt.
+func thawGroupMember(ctx context.Context, opts *options.GritAgentOptions, store storage.Storage) error {
+	return writeGroupSentinel(ctx, store, opts.DstDir, &groupSentinel{})
+}
+
+func writeGroupSentinel(ctx context.Context, store storage.Storage, dir string, sentinel *groupSentinel) error {
+	data, err := json.Marshal(sentinel)
+	if err != nil {
+		return err
+	}
+	return store.WriteFile(ctx, path.Join(dir, metadata.GroupFrozenSentinelFile), bytes.NewReader(data))
+}
+
+// readGroupSentinel returns the sentinel of member in dir, and nil is returned if the member has not written it.
+func readGroupSentinel(ctx context.Context, store storage.Storage, dir string) (*groupSentinel, error) {
+	file := path.Join(dir, metadata.GroupFrozenSentinelFile)
+	if exists, err := store.Exists(ctx, file); err != nil || !exists {
+		return nil, err
+	}
+	r, err := store.ReadFile(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var sentinel groupSentinel
+	if err := json.Unmarshal(data, &sentinel); err != nil {
+		// the sentinel is being written.
+		return nil, nil
+	}
+	return &sentinel, nil
+}
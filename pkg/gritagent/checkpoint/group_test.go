@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"context"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+	"github.com/kaito-project/grit/pkg/gritagent/storage"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+func TestWaitForGroupFrozen(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("all members are frozen", func(t *testing.T) {
+		tempDir := t.TempDir()
+		members := []string{"group-0", "group-1"}
+
+		errs := make(chan error, len(members))
+		for _, member := range members {
+			go func() {
+				opts := &options.GritAgentOptions{
+					DstDir:             path.Join(tempDir, member),
+					GroupMembers:       members,
+					GroupFreezeTimeout: 10 * time.Second,
+				}
+				errs <- waitForGroupFrozen(ctx, opts, storage.NewVolumeStorage())
+			}()
+		}
+		for range members {
+			if err := <-errs; err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+
+		for _, member := range members {
+			if _, err := os.Stat(path.Join(tempDir, member, metadata.GroupFrozenSentinelFile)); err != nil {
+				t.Fatalf("expected sentinel file of %s, got %v", member, err)
+			}
+		}
+	})
+
+	t.Run("sentinels of thawed member and previous attempt are ignored", func(t *testing.T) {
+		tempDir := t.TempDir()
+		os.MkdirAll(path.Join(tempDir, "group-1"), 0755)
+		os.WriteFile(path.Join(tempDir, "group-1", metadata.GroupFrozenSentinelFile), []byte(`{}`), 0644)
+		// group-2 was killed while it was frozen, and it never acknowledges the current attempt of group-0.
+		os.MkdirAll(path.Join(tempDir, "group-2"), 0755)
+		os.WriteFile(path.Join(tempDir, "group-2", metadata.GroupFrozenSentinelFile), []byte(`{"attempt":"previous","acks":{"group-0":"previous"}}`), 0644)
+
+		opts := &options.GritAgentOptions{
+			DstDir:             path.Join(tempDir, "group-0"),
+			GroupMembers:       []string{"group-0", "group-1", "group-2"},
+			GroupFreezeTimeout: time.Second,
+		}
+		err := waitForGroupFrozen(ctx, opts, storage.NewVolumeStorage())
+		if err == nil || !strings.Contains(err.Error(), "[group-1 group-2]") {
+			t.Fatalf("expected group-1 and group-2 are not frozen, got %v", err)
+		}
+
+		if err := thawGroupMember(ctx, opts, storage.NewVolumeStorage()); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if sentinel, err := readGroupSentinel(ctx, storage.NewVolumeStorage(), opts.DstDir); err != nil || sentinel == nil || len(sentinel.Attempt) != 0 {
+			t.Fatalf("expected sentinel of this member is thawed, got %+v, %v", sentinel, err)
+		}
+	})
+
+	t.Run("other member is not frozen in time", func(t *testing.T) {
+		tempDir := t.TempDir()
+		opts := &options.GritAgentOptions{
+			DstDir:             path.Join(tempDir, "group-0"),
+			GroupMembers:       []string{"group-0", "group-1"},
+			GroupFreezeTimeout: time.Second,
+		}
+		if err := waitForGroupFrozen(ctx, opts, storage.NewVolumeStorage()); err == nil {
+			t.Fatalf("expected timeout error, got nil")
+		}
+	})
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// rollback records side effects which are applied on processes of the pod for checkpointing(like locking cuda state and
+// making mounts private), and undoes them in reverse order when checkpoint fails or is cancelled, so the workload keeps
+// running as before.
+type rollback struct {
+	steps []rollbackStep
+	// stopped records containers which have been dumped and stopped by criu, they can't be rolled back.
+	stopped []string
+}
+
+type rollbackStep struct {
+	pid  uint32
+	name string
+	undo func(context.Context) error
+}
+
+// add records a side effect applied on the process, and undo is called for rolling it back.
+func (r *rollback) add(pid uint32, name string, undo func(context.Context) error) {
+	r.steps = append(r.steps, rollbackStep{pid: pid, name: name, undo: undo})
+}
+
+// release drops side effects of the process without undoing them, it's called after the process of container has been
+// dumped and stopped by criu.
+func (r *rollback) release(pid uint32, container string) {
+	r.steps = slices.DeleteFunc(r.steps, func(step rollbackStep) bool {
+		return step.pid == pid
+	})
+	r.stopped = append(r.stopped, container)
+}
+
+// resume undoes side effects of the process in reverse order, it's called after the process has been dumped by criu
+// with --leave-running, so the process continues to run as before.
+func (r *rollback) resume(ctx context.Context, pid uint32) error {
+	var steps []rollbackStep
+	r.steps = slices.DeleteFunc(r.steps, func(step rollbackStep) bool {
+		if step.pid == pid {
+			steps = append(steps, step)
+			return true
+		}
+		return false
+	})
+	return undoSteps(ctx, steps)
+}
+
+// run undoes all recorded side effects in reverse order.
+func (r *rollback) run(ctx context.Context) error {
+	steps := r.steps
+	r.steps = nil
+	return undoSteps(ctx, steps)
+}
+
+// abort undoes all recorded side effects when checkpoint fails with err, and err is returned as it is if nothing has
+// been changed on the pod.
+func (r *rollback) abort(ctx context.Context, err error) error {
+	if len(r.steps) == 0 && len(r.stopped) == 0 {
+		return err
+	}
+	return &RolledBackError{Err: err, RollbackErr: r.run(ctx), StoppedContainers: r.stopped}
+}
+
+// undoSteps undoes steps in reverse order. all steps are tried even if some of them fail, and the failures are joined
+// into the returned error. context of grit agent maybe has been cancelled, so it's not used by undo.
+func undoSteps(ctx context.Context, steps []rollbackStep) error {
+	ctx = context.WithoutCancel(ctx)
+	var errs []error
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		log.FromContext(ctx).Info("Rolling back", "step", step.name, "pid", step.pid)
+		if err := step.undo(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to roll back %s of process %d: %w", step.name, step.pid, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RolledBackError is returned when checkpoint fails after side effects have been applied on the pod, RollbackErr is nil
+// when all of them have been undone successfully. StoppedContainers have been dumped and stopped by criu before the
+// failure, so the pod is only partially rolled back if there are any.
+type RolledBackError struct {
+	Err               error
+	RollbackErr       error
+	StoppedContainers []string
+}
+
+// RolledBack returns whether the pod keeps running as before checkpoint.
+func (e *RolledBackError) RolledBack() bool {
+	return e.RollbackErr == nil && len(e.StoppedContainers) == 0
+}
+
+func (e *RolledBackError) Error() string {
+	switch {
+	case e.RollbackErr != nil:
+		return fmt.Sprintf("%v, and rollback failed: %v", e.Err, e.RollbackErr)
+	case len(e.StoppedContainers) != 0:
+		return fmt.Sprintf("%v, containers %s have been stopped by criu, and other containers have been rolled back", e.Err, strings.Join(e.StoppedContainers, ", "))
+	}
+	return fmt.Sprintf("%v, and the pod has been rolled back", e.Err)
+}
+
+func (e *RolledBackError) Unwrap() error {
+	return e.Err
+}
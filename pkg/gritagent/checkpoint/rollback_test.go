@@ -0,0 +1,144 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	testcases := map[string]struct {
+		failedSteps   []string
+		releasedPid   uint32
+		resumedPid    uint32
+		expectedSteps []string
+		expectErr     bool
+	}{
+		"steps are undone in reverse order": {
+			expectedSteps: []string{"2/cuda checkpoint", "2/cuda lock", "1/cuda checkpoint", "1/cuda lock"},
+		},
+		"steps of released process are not undone": {
+			releasedPid:   1,
+			expectedSteps: []string{"2/cuda checkpoint", "2/cuda lock"},
+		},
+		"steps of resumed process are undone at once": {
+			resumedPid:    1,
+			expectedSteps: []string{"1/cuda checkpoint", "1/cuda lock", "2/cuda checkpoint", "2/cuda lock"},
+		},
+		"all steps are tried when some of them fail": {
+			failedSteps:   []string{"2/cuda checkpoint"},
+			expectedSteps: []string{"2/cuda checkpoint", "2/cuda lock", "1/cuda checkpoint", "1/cuda lock"},
+			expectErr:     true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var undone []string
+			rb := &rollback{}
+			for _, pid := range []uint32{1, 2} {
+				for _, step := range []string{"cuda lock", "cuda checkpoint"} {
+					key := string(rune('0'+pid)) + "/" + step
+					rb.add(pid, step, func(ctx context.Context) error {
+						undone = append(undone, key)
+						if slices.Contains(tc.failedSteps, key) {
+							return errors.New("failed")
+						}
+						return nil
+					})
+				}
+			}
+			if tc.releasedPid != 0 {
+				rb.release(tc.releasedPid, "container")
+			}
+			if tc.resumedPid != 0 {
+				if err := rb.resume(context.Background(), tc.resumedPid); err != nil {
+					t.Fatalf("failed to resume process %d, %v", tc.resumedPid, err)
+				}
+			}
+
+			err := rb.run(context.Background())
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if !slices.Equal(undone, tc.expectedSteps) {
+				t.Errorf("expected undone steps %v, got %v", tc.expectedSteps, undone)
+			}
+			if len(rb.steps) != 0 {
+				t.Errorf("expected no steps left after rollback, got %d", len(rb.steps))
+			}
+		})
+	}
+}
+
+func TestAbort(t *testing.T) {
+	checkpointErr := errors.New("failed to checkpoint container sidecar")
+
+	testcases := map[string]struct {
+		// dumped containers are stopped by criu before checkpoint of the next container fails.
+		dumped             []string
+		noSteps            bool
+		expectedRolledBack bool
+		expectedUndone     []string
+		expectedStopped    []string
+	}{
+		"pod is rolled back when no container has been dumped": {
+			expectedRolledBack: true,
+			expectedUndone:     []string{"sidecar", "app"},
+		},
+		"pod is partially rolled back when the first container has been dumped": {
+			dumped:          []string{"app"},
+			expectedUndone:  []string{"sidecar"},
+			expectedStopped: []string{"app"},
+		},
+		"error is returned as it is when nothing has been changed": {
+			noSteps: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var undone []string
+			rb := &rollback{}
+			if !tc.noSteps {
+				for pid, container := range []string{"app", "sidecar"} {
+					rb.add(uint32(pid+1), "cuda lock", func(ctx context.Context) error {
+						undone = append(undone, container)
+						return nil
+					})
+				}
+			}
+			for pid, container := range tc.dumped {
+				rb.release(uint32(pid+1), container)
+			}
+
+			err := rb.abort(context.Background(), checkpointErr)
+			if !errors.Is(err, checkpointErr) {
+				t.Fatalf("expected error wraps %v, got %v", checkpointErr, err)
+			}
+			var rolledBackErr *RolledBackError
+			if tc.noSteps {
+				if errors.As(err, &rolledBackErr) {
+					t.Errorf("expected no rollback, got %v", err)
+				}
+				return
+			}
+			if !errors.As(err, &rolledBackErr) {
+				t.Fatalf("expected RolledBackError, got %v", err)
+			}
+			if rolledBackErr.RolledBack() != tc.expectedRolledBack {
+				t.Errorf("expected rolled back %v, got %v", tc.expectedRolledBack, err)
+			}
+			if !slices.Equal(rolledBackErr.StoppedContainers, tc.expectedStopped) {
+				t.Errorf("expected stopped containers %v, got %v", tc.expectedStopped, rolledBackErr.StoppedContainers)
+			}
+			if !slices.Equal(undone, tc.expectedUndone) {
+				t.Errorf("expected undone containers %v, got %v", tc.expectedUndone, undone)
+			}
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,8 +23,9 @@ import (
 	"github.com/containerd/containerd/v2/core/diff"
 	"github.com/containerd/containerd/v2/pkg/namespaces"
 	"github.com/containerd/containerd/v2/pkg/rootfs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
-	"go.opentelemetry.io/otel/trace/noop"
 	internalapi "k8s.io/cri-api/pkg/apis"
 	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
 	remote "k8s.io/cri-client/pkg"
@@ -31,9 +34,13 @@ import (
 
 	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
 	"github.com/kaito-project/grit/pkg/metadata"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
-func RuntimeCheckpointPod(ctx context.Context, opts *options.RuntimeCheckpointOptions) error {
+// RuntimeCheckpointPod checkpoints running containers of the target pod, only containers specified by opts.Containers are
+// checkpointed if it's not empty, and containers keep running after dump if opts.LeaveRunning is true. if barrier is specified, all
+// containers will be frozen first, and containers are dumped only after barrier returns successfully.
+func RuntimeCheckpointPod(ctx context.Context, opts *options.RuntimeCheckpointOptions, barrier func(context.Context) error) error {
 	criClient, err := getRuntimeService(ctx, opts)
 	if err != nil {
 		return fmt.Errorf("failed to get runtime service: %w", err)
@@ -58,24 +65,75 @@ func RuntimeCheckpointPod(ctx context.Context, opts *options.RuntimeCheckpointOp
 		return fmt.Errorf("failed to list containers: %w", err)
 	}
 	if len(containers) == 0 {
-		return fmt.Errorf("no containers found for pod %s/%s", opts.TargetPodNamespace, opts.TargetPodName)
+		return metadata.Permanent(fmt.Errorf("no containers found for pod %s/%s", opts.TargetPodNamespace, opts.TargetPodName))
+	}
+	containers, err = selectContainers(containers, opts.Containers)
+	if err != nil {
+		return metadata.Permanent(fmt.Errorf("failed to select containers for pod %s/%s: %w", opts.TargetPodNamespace, opts.TargetPodName, err))
+	}
+
+	// side effects applied on processes of containers which have not been dumped are rolled back when checkpoint fails
+	// or is cancelled, so these containers keep running.
+	rb := &rollback{}
+	if err := checkpointContainers(ctx, containers, ctrClient, opts, barrier, rb); err != nil {
+		return rb.abort(ctx, err)
+	}
+	return nil
+}
+
+func checkpointContainers(ctx context.Context, containers []*runtimeapi.Container, ctrClient *containerd.Client, opts *options.RuntimeCheckpointOptions, barrier func(context.Context) error, rb *rollback) error {
+	frozen := false
+	if barrier != nil {
+		if err := freezeContainers(ctx, containers, ctrClient, rb); err != nil {
+			return fmt.Errorf("failed to freeze containers: %w", err)
+		}
+
+		barrierCtx, span := tracer.Start(ctx, "wait for group frozen")
+		err := barrier(barrierCtx)
+		tracing.EndSpan(span, err)
+		if err != nil {
+			return fmt.Errorf("failed to wait for barrier: %w", err)
+		}
+		frozen = true
 	}
 
 	// checkpoint each container
 	// TODO: consider consistency problems when checkpointing multiple containers
 	for _, container := range containers {
-		if err := runtimeCheckpointContainer(ctx, container, ctrClient, opts); err != nil {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("checkpoint is aborted before container %s: %w", container.Id, err)
+		}
+
+		if err := runtimeCheckpointContainer(ctx, container, ctrClient, opts, frozen, rb); err != nil {
 			return fmt.Errorf("failed to checkpoint container %s: %w", container.Id, err)
 		}
 	}
-
 	return nil
 }
 
+// selectContainers returns containers whose names are in the names list, and all containers are returned if names is empty.
+// every specified container should be running, otherwise checkpoint would be restored without it.
+func selectContainers(containers []*runtimeapi.Container, names []string) ([]*runtimeapi.Container, error) {
+	if len(names) == 0 {
+		return containers, nil
+	}
+
+	var selected []*runtimeapi.Container
+	for _, name := range names {
+		idx := slices.IndexFunc(containers, func(c *runtimeapi.Container) bool { return c.GetMetadata().GetName() == name })
+		if idx < 0 {
+			return nil, fmt.Errorf("container %s is not running", name)
+		}
+		selected = append(selected, containers[idx])
+	}
+	return selected, nil
+}
+
 func getRuntimeService(ctx context.Context, opts *options.RuntimeCheckpointOptions) (internalapi.RuntimeService, error) {
 	logger := klog.Background()
 
-	var tp trace.TracerProvider = noop.NewTracerProvider()
+	// cri calls are traced by the global tracer provider, which is a noop provider when tracing is disabled.
+	var tp trace.TracerProvider = otel.GetTracerProvider()
 	timeout := time.Second * 10
 
 	return remote.NewRemoteRuntimeService(opts.RuntimeEndpoint, timeout, tp, &logger)
@@ -89,7 +147,94 @@ func getContainerdClient(ctx context.Context, opts *options.RuntimeCheckpointOpt
 	return containerd.New(opts.RuntimeEndpoint, ctrOpts...)
 }
 
-func runtimeCheckpointContainer(ctx context.Context, ctrmeta *runtimeapi.Container, client *containerd.Client, opts *options.RuntimeCheckpointOptions) error {
+// getContainerTasks returns the task of each container.
+func getContainerTasks(ctx context.Context, containers []*runtimeapi.Container, client *containerd.Client) ([]containerd.Task, error) {
+	var tasks []containerd.Task
+	for _, ctrmeta := range containers {
+		container, err := client.LoadContainer(ctx, ctrmeta.Id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load container %s: %w", ctrmeta.Id, err)
+		}
+		task, err := container.Task(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// freezeContainers freezes containers of the pod before members of checkpoint group pass the barrier. cuda state of
+// processes using GPU is locked and checkpointed while they're running, then containers are paused by cgroup freezer,
+// so processes on CPU are stopped too. any failure fails the group, because a member which keeps running would be
+// dumped at a different moment from other members.
+func freezeContainers(ctx context.Context, containers []*runtimeapi.Container, client *containerd.Client, rb *rollback) error {
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	tasks, err := getContainerTasks(ctx, containers, client)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if !isCudaProcess(task.Pid()) {
+			continue
+		}
+		if err := lockCudaState(ctx, task.Pid(), rb); err != nil {
+			return fmt.Errorf("failed to lock task %s: %w", task.ID(), err)
+		}
+		if err := checkpointCudaState(ctx, task.Pid(), rb); err != nil {
+			return fmt.Errorf("failed to checkpoint cuda state of task %s: %w", task.ID(), err)
+		}
+	}
+
+	for _, task := range tasks {
+		log.FromContext(ctx).Info("Pausing container", "taskID", task.ID())
+		if err := task.Pause(ctx); err != nil {
+			return fmt.Errorf("failed to pause task %s: %w", task.ID(), err)
+		}
+		rb.add(task.Pid(), "container pause", func(ctx context.Context) error {
+			return thawTask(ctx, task)
+		})
+	}
+	return nil
+}
+
+// taskFreezerCgroup returns the freezer cgroup of the process in the host, which is passed to criu by --freeze-cgroup.
+// cgroup of the process is read in the cgroup namespace of the host, because grit agent maybe runs in its own one.
+func taskFreezerCgroup(pid uint32) (string, error) {
+	output, err := exec.Command("nsenter", "-t", "1", "-C", "--", "cat", fmt.Sprintf("/proc/%d/cgroup", pid)).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read cgroup of process %d: %w", pid, err)
+	}
+	return parseFreezerCgroup(string(output))
+}
+
+// parseFreezerCgroup returns the freezer cgroup dir from the content of /proc/<pid>/cgroup, the freezer controller is
+// used on cgroup v1, and the unified hierarchy is used on cgroup v2.
+func parseFreezerCgroup(content string) (string, error) {
+	unified := ""
+	for _, line := range strings.Split(strings.TrimSpace(content), "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if slices.Contains(strings.Split(fields[1], ","), "freezer") {
+			return path.Join("/sys/fs/cgroup/freezer", fields[2]), nil
+		}
+		if fields[0] == "0" && len(fields[1]) == 0 {
+			unified = path.Join("/sys/fs/cgroup", fields[2])
+		}
+	}
+	if len(unified) == 0 {
+		return "", fmt.Errorf("freezer cgroup is not found")
+	}
+	return unified, nil
+}
+
+func runtimeCheckpointContainer(ctx context.Context, ctrmeta *runtimeapi.Container, client *containerd.Client, opts *options.RuntimeCheckpointOptions, frozen bool, rb *rollback) (err error) {
+	ctx, span := tracer.Start(ctx, "checkpoint container", trace.WithAttributes(attribute.String("grit.container", ctrmeta.GetMetadata().GetName())))
+	defer func() { tracing.EndSpan(span, err) }()
+
 	// checkpoint to a temporary directory, then perform a rename to ensure atomicity
 	workPath := path.Join(opts.HostWorkPath, ctrmeta.GetMetadata().GetName()+"-work")
 	logger := log.FromContext(ctx).WithValues("container", ctrmeta.Id, "workPath", workPath)
@@ -116,14 +261,21 @@ func runtimeCheckpointContainer(ctx context.Context, ctrmeta *runtimeapi.Contain
 	// dump criu image
 	logger.Info("Checkpointing container", "step", "criu dump")
 	checkpointPath := path.Join(workPath, crmetadata.CheckpointDirectory)
-	if err := writeCriuCheckpoint(ctx, task, checkpointPath, workPath); err != nil {
+	prevImagesDir, err := resolvePrevImagesDir(ctrmeta, opts, checkpointPath)
+	if err != nil {
+		return err
+	}
+	if err := writeCriuCheckpoint(ctx, task, checkpointPath, workPath, prevImagesDir, frozen, opts.LeaveRunning, rb); err != nil {
 		return fmt.Errorf("failed to write criu checkpoint: %w", err)
 	}
 
 	// dump rw layer
 	logger.Info("Checkpointing container", "step", "write rootfs diff")
 	rootFsDiffTarPath := path.Join(workPath, crmetadata.RootFsDiffTar)
-	if err := writeRootFsDiffTar(ctx, ctrmeta, client, rootFsDiffTarPath); err != nil {
+	rootFsCtx, rootFsSpan := tracer.Start(ctx, "write rootfs diff")
+	err = writeRootFsDiffTar(rootFsCtx, ctrmeta, client, rootFsDiffTarPath)
+	tracing.EndSpan(rootFsSpan, err)
+	if err != nil {
 		return fmt.Errorf("failed to write rootfs diff tar: %w", err)
 	}
 
@@ -131,10 +283,13 @@ func runtimeCheckpointContainer(ctx context.Context, ctrmeta *runtimeapi.Contain
 	logger.Info("Checkpointing container", "step", "save container logs")
 	containerLogPath := path.Join(getPodLogPath(opts), ctrmeta.GetMetadata().GetName())
 	savePath := path.Join(workPath, metadata.ContainerLogFile)
-	if err := writeContainerLog(ctx, containerLogPath, savePath); err != nil {
+	logCtx, logSpan := tracer.Start(ctx, "save container logs")
+	if err := writeContainerLog(logCtx, containerLogPath, savePath); err != nil {
 		// not a critical error, just log it
 		logger.Info("Failed to save container log", "error", err)
+		logSpan.RecordError(err)
 	}
+	logSpan.End()
 
 	// TODO: add config.dump and spec.dump
 
@@ -150,7 +305,50 @@ func runtimeCheckpointContainer(ctx context.Context, ctrmeta *runtimeapi.Contain
 	return nil
 }
 
-func writeCriuCheckpoint(ctx context.Context, task containerd.Task, checkpointPath, criuWorkPath string) error {
+// criuLogTailLines is the number of lines printed from the end of criu log when criu fails.
+const criuLogTailLines = 50
+
+// criuLogTail returns the last n lines of criu log file, the error is returned as content if the file can't be read.
+func criuLogTail(file string, n int) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Sprintf("failed to read %s: %v", file, err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolvePrevImagesDir returns the criu images dir of parent checkpoint relative to checkpointPath, criu only dumps memory pages
+// which have been changed since parent checkpoint when it's specified. an error is returned if the container has no usable
+// checkpointed data in parent checkpoint, instead of making a full checkpoint which is not expected by the user.
+func resolvePrevImagesDir(ctrmeta *runtimeapi.Container, opts *options.RuntimeCheckpointOptions, checkpointPath string) (string, error) {
+	if len(opts.ParentCheckpoints) == 0 {
+		return "", nil
+	}
+
+	// checkpointed data of parent checkpoint is stored in the sibling directory of host work path
+	parentImagesDir := path.Join(path.Dir(opts.HostWorkPath), opts.ParentCheckpoints[0], ctrmeta.GetMetadata().GetName(), crmetadata.CheckpointDirectory)
+	if info, err := os.Stat(parentImagesDir); err != nil {
+		return "", metadata.Permanent(fmt.Errorf("checkpointed data of container %s is not found in parent checkpoint %s: %w", ctrmeta.GetMetadata().GetName(), opts.ParentCheckpoints[0], err))
+	} else if !info.IsDir() {
+		return "", metadata.Permanent(fmt.Errorf("checkpointed data of container %s in parent checkpoint %s is not a directory", ctrmeta.GetMetadata().GetName(), opts.ParentCheckpoints[0]))
+	}
+
+	// criu creates a symlink named parent to prev images dir, so use a relative path which is still valid after work path is renamed.
+	prevImagesDir, err := filepath.Rel(checkpointPath, parentImagesDir)
+	if err != nil {
+		return "", metadata.Permanent(fmt.Errorf("failed to resolve checkpointed data of container %s in parent checkpoint %s: %w", ctrmeta.GetMetadata().GetName(), opts.ParentCheckpoints[0], err))
+	}
+	return prevImagesDir, nil
+}
+
+// writeCriuCheckpoint dumps the process of task by criu. side effects applied on the process before dump are recorded
+// in rb, and they're released after the process has been dumped and stopped by criu. if leaveRunning is true, the process
+// is left running by criu, and side effects are undone after dump(like restoring and unlocking cuda state).
+func writeCriuCheckpoint(ctx context.Context, task containerd.Task, checkpointPath, criuWorkPath, prevImagesDir string, frozen, leaveRunning bool, rb *rollback) error {
 	// Ensure checkpoint directory exists
 	if err := os.MkdirAll(checkpointPath, 0755); err != nil {
 		return fmt.Errorf("failed to create checkpoint path %s: %w", checkpointPath, err)
@@ -162,6 +360,16 @@ func writeCriuCheckpoint(ctx context.Context, task containerd.Task, checkpointPa
 		return fmt.Errorf("task %s has no PID", task.ID())
 	}
 
+	// the process is prepared for dump by fixing mount propagation and locking cuda state, these steps are traced as
+	// pausing container.
+	_, pauseSpan := tracer.Start(ctx, "pause container")
+
+	// criu thaws the process when dump fails, but the process maybe is left paused when dump is interrupted, so it's
+	// resumed as the last step of rollback.
+	rb.add(pid, "process freeze", func(ctx context.Context) error {
+		return thawTask(ctx, task)
+	})
+
 	// PRE-FIX: Make ALL mounts with master: (shared propagation) private
 	// This fixes the "unreachable sharing" error for NVIDIA/CUDA mounts
 	log.FromContext(ctx).Info("Pre-fixing mount propagation for shared mounts", "pid", pid)
@@ -184,22 +392,31 @@ func writeCriuCheckpoint(ctx context.Context, task containerd.Task, checkpointPa
 			log.FromContext(ctx).Info("Mount fix error (continuing)", "mount", mount, "error", err, "output", string(output))
 		} else {
 			fixedCount++
+			rb.add(pid, fmt.Sprintf("mount propagation of %s", mount), func(ctx context.Context) error {
+				return makeMountSlave(ctx, pid, mount)
+			})
 		}
 	}
 	log.FromContext(ctx).Info("Mount fixes completed", "fixed", fixedCount, "total", len(mounts))
 
 	// PRE-STEP: Manually lock and checkpoint CUDA state before CRIU dump
 	// This is required because CRIU plugin needs the process in a specific state
-	log.FromContext(ctx).Info("Locking CUDA state", "pid", pid)
-	cudaLock := exec.Command("/usr/local/cuda/bin/cuda-checkpoint", "--action", "lock", "--pid", strconv.Itoa(int(pid)))
-	if output, err := cudaLock.CombinedOutput(); err != nil {
-		log.FromContext(ctx).Info("CUDA lock error (continuing)", "error", err, "output", string(output))
+	// cuda state of frozen container has been checkpointed before it's frozen.
+	if !frozen {
+		if err := lockCudaState(ctx, pid, rb); err != nil {
+			log.FromContext(ctx).Info("CUDA lock error (continuing)", "error", err)
+		}
+		if err := checkpointCudaState(ctx, pid, rb); err != nil {
+			log.FromContext(ctx).Info("CUDA checkpoint error (continuing)", "error", err)
+		}
 	}
-	
-	log.FromContext(ctx).Info("Checkpointing CUDA state", "pid", pid)
-	cudaCkpt := exec.Command("/usr/local/cuda/bin/cuda-checkpoint", "--action", "checkpoint", "--pid", strconv.Itoa(int(pid)))
-	if output, err := cudaCkpt.CombinedOutput(); err != nil {
-		log.FromContext(ctx).Info("CUDA checkpoint error (continuing)", "error", err, "output", string(output))
+
+	pauseSpan.End()
+
+	// checkpoint is cancelled before criu dump, and the process will be resumed by rollback. criu dump is not
+	// interrupted once it's started, because the process would be left seized by a killed criu.
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("checkpoint is aborted before criu dump: %w", err)
 	}
 
 	// Call CRIU directly (bypass runc to avoid cgroup freeze)
@@ -220,25 +437,63 @@ func writeCriuCheckpoint(ctx context.Context, task containerd.Task, checkpointPa
 		"--shell-job",
 		"--tcp-established",
 		"--ext-unix-sk",
+		"--track-mem",
+	}
+	if len(prevImagesDir) != 0 {
+		log.FromContext(ctx).Info("Making incremental checkpoint", "prevImagesDir", prevImagesDir)
+		criuArgs = append(criuArgs, "--prev-images-dir", prevImagesDir)
+	}
+	if leaveRunning {
+		criuArgs = append(criuArgs, "--leave-running")
+	}
+	if frozen {
+		// the container has been paused by cgroup freezer, and criu dumps processes of the frozen cgroup without
+		// thawing them.
+		freezerCgroup, err := taskFreezerCgroup(pid)
+		if err != nil {
+			return fmt.Errorf("failed to resolve freezer cgroup of task %s: %w", task.ID(), err)
+		}
+		criuArgs = append(criuArgs, "--freeze-cgroup", freezerCgroup)
 	}
 
 	// Execute CRIU via nsenter into HOST's mount namespace
 	cmd := exec.Command("nsenter", criuArgs...)
 	cmd.Dir = criuWorkPath
 
+	_, dumpSpan := tracer.Start(ctx, "criu dump", trace.WithAttributes(attribute.Bool("grit.incremental", len(prevImagesDir) != 0), attribute.Bool("grit.leave_running", leaveRunning)))
 	output, err := cmd.CombinedOutput()
+	tracing.EndSpan(dumpSpan, err)
 	if err != nil {
+		// the reason of failure is only recorded in dump.log, so its tail is printed into logs of grit agent.
 		log.FromContext(ctx).Error(err, "CRIU dump failed",
 			"pid", pid,
 			"output", string(output),
-			"checkpointPath", checkpointPath)
-		return fmt.Errorf("failed to checkpoint task %s: %w\nOutput: %s", task.ID(), err, string(output))
+			"checkpointPath", checkpointPath,
+			"dumpLogTail", criuLogTail(path.Join(checkpointPath, "dump.log"), criuLogTailLines))
+		// criu dump fails on unsupported resources of the process, like an unsupported socket or device, and retrying
+		// will fail in the same way.
+		return metadata.Permanent(fmt.Errorf("failed to checkpoint task %s: %w\nOutput: %s", task.ID(), err, string(output)))
 	}
 
 	log.FromContext(ctx).Info("CRIU checkpoint completed",
 		"pid", pid,
 		"path", checkpointPath,
 		"taskID", task.ID())
+	if leaveRunning {
+		// the process is resumed by criu, but it's still locked on GPU until cuda state is restored and unlocked.
+		if err := rb.resume(ctx, pid); err != nil {
+			return fmt.Errorf("failed to resume task %s after checkpoint: %w", task.ID(), err)
+		}
+	} else {
+		// processes killed by criu are left in the frozen cgroup until it's thawed.
+		if frozen {
+			if err := thawTask(ctx, task); err != nil {
+				log.FromContext(ctx).Error(err, "failed to thaw task after checkpoint", "taskID", task.ID())
+			}
+		}
+		// the process has been stopped by criu, so there is nothing to roll back.
+		rb.release(pid, task.ID())
+	}
 
 	// Create descriptors.json - required by runc restore
 	// This file contains external file descriptors info
@@ -338,3 +593,84 @@ func writeContainerLog(ctx context.Context, logdir, savePath string) error {
 
 	return nil
 }
+
+// lockCudaState locks cuda state of the process, so no more cuda api calls can be made and the process is frozen on GPU.
+// unlocking is recorded in rb when the process is locked successfully.
+func lockCudaState(ctx context.Context, pid uint32, rb *rollback) error {
+	log.FromContext(ctx).Info("Locking CUDA state", "pid", pid)
+	cudaLock := exec.Command("/usr/local/cuda/bin/cuda-checkpoint", "--action", "lock", "--pid", strconv.Itoa(int(pid)))
+	if output, err := cudaLock.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to lock cuda state: %w, output: %s", err, string(output))
+	}
+	rb.add(pid, "cuda lock", func(ctx context.Context) error {
+		return unlockCudaState(ctx, pid)
+	})
+	return nil
+}
+
+// checkpointCudaState checkpoints cuda state of the locked process into host memory, so it can be dumped by criu.
+func checkpointCudaState(ctx context.Context, pid uint32, rb *rollback) error {
+	log.FromContext(ctx).Info("Checkpointing CUDA state", "pid", pid)
+	cudaCkpt := exec.Command("/usr/local/cuda/bin/cuda-checkpoint", "--action", "checkpoint", "--pid", strconv.Itoa(int(pid)))
+	if output, err := cudaCkpt.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to checkpoint cuda state: %w, output: %s", err, string(output))
+	}
+	rb.add(pid, "cuda checkpoint", func(ctx context.Context) error {
+		return restoreCudaState(ctx, pid)
+	})
+	return nil
+}
+
+// isCudaProcess returns whether the process uses cuda, cuda-checkpoint can't get the state of a process without cuda
+// context, and it's not installed on nodes without GPU.
+func isCudaProcess(pid uint32) bool {
+	return exec.Command("/usr/local/cuda/bin/cuda-checkpoint", "--get-state", "--pid", strconv.Itoa(int(pid))).Run() == nil
+}
+
+// restoreCudaState restores cuda state of the process which has been checkpointed by cuda-checkpoint, and the process
+// is still locked until unlockCudaState is called.
+func restoreCudaState(ctx context.Context, pid uint32) error {
+	log.FromContext(ctx).Info("Restoring CUDA state", "pid", pid)
+	cudaRestore := exec.Command("/usr/local/cuda/bin/cuda-checkpoint", "--action", "restore", "--pid", strconv.Itoa(int(pid)))
+	if output, err := cudaRestore.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to restore cuda state: %w, output: %s", err, string(output))
+	}
+	return nil
+}
+
+// unlockCudaState unlocks cuda state of the process, it's used for resuming the process when checkpoint is aborted.
+func unlockCudaState(ctx context.Context, pid uint32) error {
+	log.FromContext(ctx).Info("Unlocking CUDA state", "pid", pid)
+	cudaUnlock := exec.Command("/usr/local/cuda/bin/cuda-checkpoint", "--action", "unlock", "--pid", strconv.Itoa(int(pid)))
+	if output, err := cudaUnlock.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to unlock cuda state: %w, output: %s", err, string(output))
+	}
+	return nil
+}
+
+// makeMountSlave puts back the propagation of mount in the mount namespace of the process, which has been made private
+// for criu dump. the mount can't rejoin the peer group of the host mount, so it's made a slave mount again, which is the
+// closest state that can be restored.
+func makeMountSlave(ctx context.Context, pid uint32, mount string) error {
+	log.FromContext(ctx).Info("Restoring mount propagation", "mount", mount, "pid", pid)
+	cmd := exec.Command("nsenter", "-t", strconv.Itoa(int(pid)), "-m", "--", "mount", "--make-slave", mount)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to make mount %s slave: %w, output: %s", mount, err, string(output))
+	}
+	return nil
+}
+
+// thawTask resumes the task if it's left paused. it's called by rollback with a context which has no containerd
+// namespace, so the namespace is set here.
+func thawTask(ctx context.Context, task containerd.Task) error {
+	ctx = namespaces.WithNamespace(ctx, "k8s.io")
+	status, err := task.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if status.Status != containerd.Paused {
+		return nil
+	}
+	log.FromContext(ctx).Info("Thawing process", "pid", task.Pid())
+	return task.Resume(ctx)
+}
@@ -7,7 +7,12 @@ import (
 	"context"
 	"os"
 	"path"
+	"slices"
 	"testing"
+
+	runtimeapi "k8s.io/cri-api/pkg/apis/runtime/v1"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
 )
 
 func TestWriteContainerLog(t *testing.T) {
@@ -68,3 +73,165 @@ func TestWriteContainerLog(t *testing.T) {
 		}
 	})
 }
+
+func TestSelectContainers(t *testing.T) {
+	containers := []*runtimeapi.Container{
+		{Id: "1", Metadata: &runtimeapi.ContainerMetadata{Name: "app"}},
+		{Id: "2", Metadata: &runtimeapi.ContainerMetadata{Name: "logger"}},
+		{Id: "3", Metadata: &runtimeapi.ContainerMetadata{Name: "metrics"}},
+	}
+
+	testcases := map[string]struct {
+		names       []string
+		expectedIDs []string
+		expectErr   bool
+	}{
+		"all containers are selected when names is empty": {
+			expectedIDs: []string{"1", "2", "3"},
+		},
+		"only specified containers are selected": {
+			names:       []string{"app"},
+			expectedIDs: []string{"1"},
+		},
+		"specified container is not running": {
+			names:     []string{"app", "trainer"},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			selected, err := selectContainers(containers, tc.names)
+			if tc.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			var ids []string
+			for _, c := range selected {
+				ids = append(ids, c.Id)
+			}
+			if !slices.Equal(ids, tc.expectedIDs) {
+				t.Errorf("expected containers %v, got %v", tc.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestCriuLogTail(t *testing.T) {
+	dir := t.TempDir()
+	file := path.Join(dir, "dump.log")
+	os.WriteFile(file, []byte("line1\nline2\nline3\n"), 0644)
+
+	testcases := map[string]struct {
+		file     string
+		n        int
+		expected string
+	}{
+		"all lines are returned": {
+			file:     file,
+			n:        5,
+			expected: "line1\nline2\nline3",
+		},
+		"last lines are returned": {
+			file:     file,
+			n:        2,
+			expected: "line2\nline3",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := criuLogTail(tc.file, tc.n); got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestResolvePrevImagesDir(t *testing.T) {
+	hostPath := t.TempDir()
+	os.MkdirAll(path.Join(hostPath, "parent", "app", "checkpoint"), 0755)
+	os.MkdirAll(path.Join(hostPath, "parent", "logger"), 0755)
+	os.WriteFile(path.Join(hostPath, "parent", "logger", "checkpoint"), []byte("corrupted"), 0644)
+
+	testcases := map[string]struct {
+		container        string
+		parents          []string
+		expectedImageDir string
+		expectErr        bool
+	}{
+		"full checkpoint without parent": {
+			container: "app",
+		},
+		"parent images dir relative to checkpoint path": {
+			container:        "app",
+			parents:          []string{"parent"},
+			expectedImageDir: "../../../parent/app/checkpoint",
+		},
+		"container is not checkpointed in parent": {
+			container: "metrics",
+			parents:   []string{"parent"},
+			expectErr: true,
+		},
+		"parent images dir is not a directory": {
+			container: "logger",
+			parents:   []string{"parent"},
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ctrmeta := &runtimeapi.Container{Metadata: &runtimeapi.ContainerMetadata{Name: tc.container}}
+			opts := &options.RuntimeCheckpointOptions{HostWorkPath: path.Join(hostPath, "ckpt"), ParentCheckpoints: tc.parents}
+			checkpointPath := path.Join(opts.HostWorkPath, tc.container+"-work", "checkpoint")
+
+			prevImagesDir, err := resolvePrevImagesDir(ctrmeta, opts, checkpointPath)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if prevImagesDir != tc.expectedImageDir {
+				t.Errorf("expected prev images dir %q, got %q", tc.expectedImageDir, prevImagesDir)
+			}
+		})
+	}
+}
+
+func TestParseFreezerCgroup(t *testing.T) {
+	testcases := map[string]struct {
+		content        string
+		expectedCgroup string
+		expectErr      bool
+	}{
+		"cgroup v2": {
+			content:        "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-abc.scope\n",
+			expectedCgroup: "/sys/fs/cgroup/kubepods.slice/kubepods-pod1.slice/cri-containerd-abc.scope",
+		},
+		"cgroup v1": {
+			content:        "12:memory:/kubepods/pod1/abc\n7:freezer:/kubepods/pod1/abc\n1:name=systemd:/kubepods/pod1/abc\n0::/\n",
+			expectedCgroup: "/sys/fs/cgroup/freezer/kubepods/pod1/abc",
+		},
+		"no freezer cgroup": {
+			content:   "12:memory:/kubepods/pod1/abc\n",
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			cgroup, err := parseFreezerCgroup(tc.content)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if cgroup != tc.expectedCgroup {
+				t.Errorf("expected freezer cgroup %q, got %q", tc.expectedCgroup, cgroup)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+	"github.com/kaito-project/grit/pkg/gritagent/storage"
+)
+
+// RunCleanup removes checkpointed data on the host(src-dir) and in the cloud storage(dst-dir) if it's specified.
+func RunCleanup(ctx context.Context, opts *options.GritAgentOptions) error {
+	if len(opts.SrcDir) != 0 {
+		log.FromContext(ctx).Info("remove checkpointed data", "dir", opts.SrcDir)
+		if err := os.RemoveAll(opts.SrcDir); err != nil {
+			return fmt.Errorf("failed to remove checkpointed data in %s: %w", opts.SrcDir, err)
+		}
+	}
+
+	if len(opts.DstDir) != 0 {
+		store, err := storage.NewStorage(&opts.StorageOptions)
+		if err != nil {
+			return err
+		}
+
+		log.FromContext(ctx).Info("remove checkpointed data in storage", "dir", opts.DstDir)
+		if err := store.Remove(ctx, opts.DstDir); err != nil {
+			return fmt.Errorf("failed to remove checkpointed data in %s: %w", opts.DstDir, err)
+		}
+	}
+	return nil
+}
@@ -24,6 +24,10 @@ func TransferData(ctx context.Context, srcDir, dstDir string) error {
 		if err != nil {
 			return err
 		}
+		// stop transferring when the deadline of emergency checkpoint is exceeded.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		relPath, err := filepath.Rel(srcDir, path)
 		if err != nil {
@@ -36,6 +40,18 @@ func TransferData(ctx context.Context, srcDir, dstDir string) error {
 			return os.MkdirAll(dstPath, os.ModePerm)
 		}
 
+		// criu links incremental checkpoint images to parent images with a relative symlink, keep it as it is.
+		if d.Type()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(dstPath); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return os.Symlink(target, dstPath)
+		}
+
 		wg.Add(1)
 		workerChan <- struct{}{}
 		go func(src, dst string) {
@@ -89,14 +105,20 @@ func copyFile(srcFile, dstFile string) error {
 	return os.Chmod(dstFile, info.Mode())
 }
 
-func CreateSentinelFile(dir, fileName string) error {
-	filePath := filepath.Join(dir, fileName)
-	f, err := os.Create(filePath)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+// DirSize returns the total size in bytes of regular files in dir.
+func DirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
 
-	_, err = f.WriteString("Transfer Completed\n")
-	return err
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
 }
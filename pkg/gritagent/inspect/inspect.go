@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package inspect
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+	"github.com/kaito-project/grit/pkg/gritagent/storage"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+// RunInspect downloads checkpointed data from the storage(src-dir) into dst-dir, and prints the report of it. if
+// compare-src-dir is specified, checkpointed data of another checkpoint is downloaded into the sibling directory of
+// dst-dir, and only changes from that checkpoint are printed.
+func RunInspect(ctx context.Context, opts *options.GritAgentOptions) error {
+	store, err := storage.NewStorage(&opts.StorageOptions)
+	if err != nil {
+		return err
+	}
+
+	report, err := downloadAndInspect(ctx, store, opts.SrcDir, opts.DstDir)
+	if err != nil {
+		return err
+	}
+	if len(opts.CompareSrcDir) == 0 {
+		WriteReport(os.Stdout, report)
+		return nil
+	}
+
+	compareDir := filepath.Join(filepath.Dir(opts.DstDir), path.Base(opts.CompareSrcDir))
+	oldReport, err := downloadAndInspect(ctx, store, opts.CompareSrcDir, compareDir)
+	if err != nil {
+		return err
+	}
+	WriteDiff(os.Stdout, oldReport, report)
+	return nil
+}
+
+func downloadAndInspect(ctx context.Context, store storage.Storage, storageDir, localDir string) (*Report, error) {
+	log.FromContext(ctx).Info("download checkpointed data for inspection", "src-dir", storageDir, "dst-dir", localDir)
+	if err := store.Download(ctx, storageDir, localDir); err != nil {
+		return nil, err
+	}
+	// checkpointed data is not changed by retrying, so inspection fails at once.
+	report, err := Inspect(localDir)
+	if err != nil {
+		return nil, metadata.Permanent(err)
+	}
+	return report, nil
+}
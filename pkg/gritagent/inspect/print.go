@@ -0,0 +1,138 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package inspect
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	crmetadata "github.com/checkpoint-restore/checkpointctl/lib"
+)
+
+// WriteReport prints the report of a checkpoint into w.
+func WriteReport(w io.Writer, report *Report) {
+	for _, c := range report.Containers {
+		fmt.Fprintf(w, "container %s\n", c.Name)
+		fmt.Fprintln(w, "  process tree:")
+		for _, ps := range c.Processes {
+			fmt.Fprintf(w, "    %s%d %s\n", strings.Repeat("  ", ps.Depth), ps.PID, ps.Comm)
+		}
+		fmt.Fprintf(w, "  memory pages: %s\n", crmetadata.ByteToString(c.PagesSize))
+		fmt.Fprintf(w, "  criu images: %s\n", crmetadata.ByteToString(c.ImagesSize))
+		if s := c.DumpStats; s != nil {
+			fmt.Fprintf(w, "  dump stats: pages scanned %d, skipped parent %d, written %d, frozen %s\n",
+				s.GetPagesScanned(), s.GetPagesSkippedParent(), s.GetPagesWritten(), time.Duration(s.GetFrozenTime())*time.Microsecond)
+		}
+		fmt.Fprintf(w, "  rootfs diff: %s\n", crmetadata.ByteToString(c.RootFsDiffSize))
+		writeSection(w, "files", fileLines(c.Files))
+		writeSection(w, "sockets", fileLines(c.Sockets))
+		writeSection(w, "mounts", c.Mounts)
+		writeSection(w, "log tail", c.LogTail)
+	}
+}
+
+// WriteDiff prints changes from the old checkpoint to the new checkpoint of the same pod into w, like growth of memory
+// pages and rootfs diff, and processes, files, sockets and mounts which are added or removed.
+func WriteDiff(w io.Writer, old, new *Report) {
+	oldContainers := make(map[string]*ContainerReport, len(old.Containers))
+	for _, c := range old.Containers {
+		oldContainers[c.Name] = c
+	}
+	for _, c := range new.Containers {
+		o, ok := oldContainers[c.Name]
+		if !ok {
+			fmt.Fprintf(w, "+ container %s\n", c.Name)
+			continue
+		}
+		delete(oldContainers, c.Name)
+
+		fmt.Fprintf(w, "container %s\n", c.Name)
+		fmt.Fprintf(w, "  memory pages: %s\n", sizeChange(o.PagesSize, c.PagesSize))
+		fmt.Fprintf(w, "  criu images: %s\n", sizeChange(o.ImagesSize, c.ImagesSize))
+		fmt.Fprintf(w, "  rootfs diff: %s\n", sizeChange(o.RootFsDiffSize, c.RootFsDiffSize))
+		writeDiffSection(w, "processes", processLines(o.Processes), processLines(c.Processes))
+		writeDiffSection(w, "files", fileLines(o.Files), fileLines(c.Files))
+		writeDiffSection(w, "sockets", fileLines(o.Sockets), fileLines(c.Sockets))
+		writeDiffSection(w, "mounts", o.Mounts, c.Mounts)
+	}
+	for _, c := range old.Containers {
+		if _, ok := oldContainers[c.Name]; ok {
+			fmt.Fprintf(w, "- container %s\n", c.Name)
+		}
+	}
+}
+
+func writeSection(w io.Writer, name string, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %s:\n", name)
+	for _, line := range lines {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+}
+
+func writeDiffSection(w io.Writer, name string, old, new []string) {
+	added, removed := diffLines(old, new)
+	if len(added) == 0 && len(removed) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %s:\n", name)
+	for _, line := range removed {
+		fmt.Fprintf(w, "    - %s\n", line)
+	}
+	for _, line := range added {
+		fmt.Fprintf(w, "    + %s\n", line)
+	}
+}
+
+// diffLines returns lines which are only in new and lines which are only in old, and the order of lines is kept.
+func diffLines(old, new []string) ([]string, []string) {
+	oldSet := make(map[string]int, len(old))
+	for _, line := range old {
+		oldSet[line]++
+	}
+	var added []string
+	for _, line := range new {
+		if oldSet[line] > 0 {
+			oldSet[line]--
+			continue
+		}
+		added = append(added, line)
+	}
+	var removed []string
+	for _, line := range old {
+		if oldSet[line] > 0 {
+			oldSet[line]--
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+func sizeChange(old, new int64) string {
+	sign, delta := "+", new-old
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	return fmt.Sprintf("%s -> %s (%s%s)", crmetadata.ByteToString(old), crmetadata.ByteToString(new), sign, crmetadata.ByteToString(delta))
+}
+
+func processLines(processes []Process) []string {
+	lines := make([]string, 0, len(processes))
+	for _, ps := range processes {
+		lines = append(lines, fmt.Sprintf("%d %s", ps.PID, ps.Comm))
+	}
+	return lines
+}
+
+func fileLines(files []File) []string {
+	lines := make([]string, 0, len(files))
+	for _, f := range files {
+		lines = append(lines, fmt.Sprintf("pid %d fd %s %s", f.PID, f.Fd, f.Path))
+	}
+	return lines
+}
@@ -0,0 +1,46 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package inspect
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	testcases := map[string]struct {
+		old             []string
+		new             []string
+		expectedAdded   []string
+		expectedRemoved []string
+	}{
+		"no changes": {
+			old: []string{"1 python", "2 worker"},
+			new: []string{"1 python", "2 worker"},
+		},
+		"lines are added and removed": {
+			old:             []string{"1 python", "2 worker"},
+			new:             []string{"1 python", "3 worker", "4 worker"},
+			expectedAdded:   []string{"3 worker", "4 worker"},
+			expectedRemoved: []string{"2 worker"},
+		},
+		"duplicated lines are counted": {
+			old:           []string{"/dev/null"},
+			new:           []string{"/dev/null", "/dev/null"},
+			expectedAdded: []string{"/dev/null"},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			added, removed := diffLines(tc.old, tc.new)
+			if !slices.Equal(added, tc.expectedAdded) {
+				t.Errorf("expected added %v, got %v", tc.expectedAdded, added)
+			}
+			if !slices.Equal(removed, tc.expectedRemoved) {
+				t.Errorf("expected removed %v, got %v", tc.expectedRemoved, removed)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package inspect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	crmetadata "github.com/checkpoint-restore/checkpointctl/lib"
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+	"github.com/checkpoint-restore/go-criu/v6/crit/images"
+	"golang.org/x/sys/unix"
+
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+// logTailLines is the number of lines kept from the end of saved container log.
+const logTailLines = 20
+
+// Report is the content of checkpointed data of a pod, and each checkpointed container has its own report.
+type Report struct {
+	Containers []*ContainerReport
+}
+
+// ContainerReport is read from criu images, rootfs diff and saved log of a checkpointed container.
+type ContainerReport struct {
+	Name      string
+	Processes []Process
+	// PagesSize is the size of memory pages dumped by criu, incremental checkpoint only contains pages which have been
+	// changed since parent checkpoint.
+	PagesSize int64
+	// ImagesSize is the size of all criu images, including memory pages.
+	ImagesSize     int64
+	DumpStats      *images.DumpStatsEntry
+	Files          []File
+	Sockets        []File
+	Mounts         []string
+	RootFsDiffSize int64
+	LogTail        []string
+}
+
+// Process is a process in the process tree, Depth is its level in the tree and the root process is at 0.
+type Process struct {
+	PID   uint32
+	Comm  string
+	Depth int
+}
+
+// File is an opened file descriptor of a process, sockets are described by their protocol and addresses.
+type File struct {
+	PID  uint32
+	Fd   string
+	Path string
+}
+
+// Inspect reads checkpointed data in dir, which has a sub directory for each checkpointed container.
+func Inspect(dir string) (*Report, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		containerDir := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(containerDir, crmetadata.CheckpointDirectory)); err != nil {
+			continue
+		}
+		container, err := inspectContainer(containerDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect container %s: %w", entry.Name(), err)
+		}
+		report.Containers = append(report.Containers, container)
+	}
+	if len(report.Containers) == 0 {
+		return nil, fmt.Errorf("no checkpointed containers in %s", dir)
+	}
+	return report, nil
+}
+
+func inspectContainer(dir string) (*ContainerReport, error) {
+	imagesDir := filepath.Join(dir, crmetadata.CheckpointDirectory)
+	container := &ContainerReport{Name: filepath.Base(dir)}
+
+	psTree, err := crit.New("", "", imagesDir, false, false).ExplorePs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process tree: %w", err)
+	}
+	container.Processes = flattenPsTree(psTree, 0, nil)
+
+	if container.Files, container.Sockets, err = readFiles(imagesDir, container.Processes); err != nil {
+		return nil, fmt.Errorf("failed to read opened files: %w", err)
+	}
+	if container.Mounts, err = readMounts(imagesDir); err != nil {
+		return nil, fmt.Errorf("failed to read mounts: %w", err)
+	}
+	if container.PagesSize, container.ImagesSize, err = imagesSize(imagesDir); err != nil {
+		return nil, err
+	}
+	// dump stats is missing in images of old criu, and it's not necessary for the report.
+	if stats, err := crit.GetDumpStats(imagesDir); err == nil {
+		container.DumpStats = stats
+	}
+
+	if info, err := os.Stat(filepath.Join(dir, crmetadata.RootFsDiffTar)); err == nil {
+		container.RootFsDiffSize = info.Size()
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, metadata.ContainerLogFile)); err == nil {
+		container.LogTail = tailLines(data, logTailLines)
+	}
+	return container, nil
+}
+
+func flattenPsTree(ps *crit.PsTree, depth int, processes []Process) []Process {
+	if ps == nil {
+		return processes
+	}
+	processes = append(processes, Process{PID: ps.PId, Comm: ps.Comm, Depth: depth})
+	sort.Slice(ps.Children, func(i, j int) bool { return ps.Children[i].PId < ps.Children[j].PId })
+	for _, child := range ps.Children {
+		processes = flattenPsTree(child, depth+1, processes)
+	}
+	return processes
+}
+
+// readFiles returns opened files and sockets of processes. processes sharing the same file table are only read once.
+// images are decoded here instead of crit.ExploreFds, because it caches files of the first checkpoint in global variables,
+// and two checkpoints are read by diff.
+func readFiles(imagesDir string, processes []Process) ([]File, []File, error) {
+	filesImg, err := decodeImage(filepath.Join(imagesDir, "files.img"))
+	if err != nil {
+		return nil, nil, err
+	}
+	fileEntries := make(map[uint32]*images.FileEntry, len(filesImg.Entries))
+	for _, entry := range filesImg.Entries {
+		if file, ok := entry.Message.(*images.FileEntry); ok {
+			fileEntries[file.GetId()] = file
+		}
+	}
+
+	var files, sockets []File
+	readTables := make(map[uint32]bool)
+	for _, ps := range processes {
+		idsImg, err := decodeImage(filepath.Join(imagesDir, fmt.Sprintf("ids-%d.img", ps.PID)))
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(idsImg.Entries) == 0 {
+			return nil, nil, fmt.Errorf("ids image of process %d is empty", ps.PID)
+		}
+		ids, ok := idsImg.Entries[0].Message.(*images.TaskKobjIdsEntry)
+		if !ok {
+			return nil, nil, fmt.Errorf("unexpected entry %T in ids image of process %d", idsImg.Entries[0].Message, ps.PID)
+		}
+		filesID := ids.GetFilesId()
+		if readTables[filesID] {
+			continue
+		}
+		readTables[filesID] = true
+
+		fdInfoImg, err := decodeImage(filepath.Join(imagesDir, fmt.Sprintf("fdinfo-%d.img", filesID)))
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, entry := range fdInfoImg.Entries {
+			fdInfo, ok := entry.Message.(*images.FdinfoEntry)
+			if !ok {
+				return nil, nil, fmt.Errorf("unexpected entry %T in fdinfo image of files table %d", entry.Message, filesID)
+			}
+			file := File{PID: ps.PID, Fd: strconv.FormatUint(uint64(fdInfo.GetFd()), 10)}
+			switch fileEntry := fileEntries[fdInfo.GetId()]; fdInfo.GetType() {
+			case images.FdTypes_INETSK, images.FdTypes_UNIXSK, images.FdTypes_PACKETSK, images.FdTypes_NETLINKSK:
+				file.Path = describeSocket(fileEntry, fdInfo.GetType())
+				sockets = append(sockets, file)
+			default:
+				file.Path = describeFile(fileEntry, fdInfo.GetType(), fdInfo.GetId())
+				files = append(files, file)
+			}
+		}
+	}
+	return files, sockets, nil
+}
+
+func describeFile(file *images.FileEntry, fdType images.FdTypes, id uint32) string {
+	switch {
+	case file.GetReg() != nil:
+		return file.GetReg().GetName()
+	case file.GetPipe() != nil:
+		return fmt.Sprintf("pipe[%d]", file.GetPipe().GetPipeId())
+	case file.GetMemfd() != nil:
+		return fmt.Sprintf("memfd[%d]", file.GetMemfd().GetInodeId())
+	}
+	return fmt.Sprintf("%s[%d]", strings.ToLower(fdType.String()), id)
+}
+
+func describeSocket(file *images.FileEntry, fdType images.FdTypes) string {
+	switch {
+	case file.GetIsk() != nil:
+		isk := file.GetIsk()
+		proto := strconv.FormatUint(uint64(isk.GetProto()), 10)
+		switch isk.GetProto() {
+		case unix.IPPROTO_TCP:
+			proto = "tcp"
+		case unix.IPPROTO_UDP:
+			proto = "udp"
+		}
+		if isk.GetFamily() == unix.AF_INET6 {
+			proto += "6"
+		}
+		s := fmt.Sprintf("%s %s -> %s", proto,
+			net.JoinHostPort(inetAddr(isk.GetSrcAddr()), strconv.FormatUint(uint64(isk.GetSrcPort()), 10)),
+			net.JoinHostPort(inetAddr(isk.GetDstAddr()), strconv.FormatUint(uint64(isk.GetDstPort()), 10)))
+		if state, ok := tcpStates[isk.GetState()]; ok && isk.GetProto() == unix.IPPROTO_TCP {
+			s += " " + state
+		}
+		return s
+	case file.GetUsk() != nil:
+		name := string(bytes.TrimRight(file.GetUsk().GetName(), "\x00"))
+		if len(name) == 0 {
+			name = fmt.Sprintf("ino %d peer %d", file.GetUsk().GetIno(), file.GetUsk().GetPeer())
+		}
+		return "unix " + name
+	case file.GetPsk() != nil:
+		return fmt.Sprintf("packet ifindex %d", file.GetPsk().GetIfindex())
+	case file.GetNlsk() != nil:
+		return fmt.Sprintf("netlink protocol %d", file.GetNlsk().GetProtocol())
+	}
+	return strings.ToLower(fdType.String())
+}
+
+// tcpStates are the names of tcp states in linux kernel.
+var tcpStates = map[uint32]string{
+	1:  "ESTABLISHED",
+	2:  "SYN_SENT",
+	3:  "SYN_RECV",
+	4:  "FIN_WAIT1",
+	5:  "FIN_WAIT2",
+	6:  "TIME_WAIT",
+	7:  "CLOSE",
+	8:  "CLOSE_WAIT",
+	9:  "LAST_ACK",
+	10: "LISTEN",
+	11: "CLOSING",
+}
+
+// inetAddr formats the address of inet socket. criu stores the address in network byte order as it's in kernel, and
+// reads it as little endian words on the nodes.
+func inetAddr(addr []uint32) string {
+	if len(addr) != 1 && len(addr) != 4 {
+		return "*"
+	}
+	ip := make(net.IP, 4*len(addr))
+	for i, word := range addr {
+		binary.LittleEndian.PutUint32(ip[4*i:], word)
+	}
+	return ip.String()
+}
+
+func readMounts(imagesDir string) ([]string, error) {
+	mountImages, err := filepath.Glob(filepath.Join(imagesDir, "mountpoints-*.img"))
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts []string
+	for _, mountImage := range mountImages {
+		img, err := decodeImage(mountImage)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range img.Entries {
+			mnt, ok := entry.Message.(*images.MntEntry)
+			if !ok {
+				return nil, fmt.Errorf("unexpected entry %T in %s", entry.Message, filepath.Base(mountImage))
+			}
+			mounts = append(mounts, fmt.Sprintf("%s from %s%s", mnt.GetMountpoint(), mnt.GetSource(), mnt.GetRoot()))
+		}
+	}
+	sort.Strings(mounts)
+	return mounts, nil
+}
+
+// imagesSize returns the size of memory pages and all criu images. parent symlink of incremental checkpoint is skipped,
+// so only data of this checkpoint is counted.
+func imagesSize(imagesDir string) (int64, int64, error) {
+	var pagesSize, total int64
+	err := filepath.WalkDir(imagesDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		total += info.Size()
+		if strings.HasPrefix(d.Name(), crmetadata.PagesPrefix) {
+			pagesSize += info.Size()
+		}
+		return nil
+	})
+	return pagesSize, total, err
+}
+
+func decodeImage(file string) (*crit.CriuImage, error) {
+	img, err := crit.New(file, "", "", false, true).Decode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filepath.Base(file), err)
+	}
+	return img, nil
+}
+
+// tailLines returns the last n lines of data.
+func tailLines(data []byte, n int) []string {
+	trimmed := strings.TrimRight(string(data), "\n")
+	if len(trimmed) == 0 {
+		return nil
+	}
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
@@ -0,0 +1,52 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package inspect
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/checkpoint-restore/go-criu/v6/crit"
+)
+
+func TestInetAddr(t *testing.T) {
+	testcases := map[string]struct {
+		addr     []uint32
+		expected string
+	}{
+		"ipv4 address": {
+			addr:     []uint32{0x0100000a},
+			expected: "10.0.0.1",
+		},
+		"ipv6 loopback address": {
+			addr:     []uint32{0, 0, 0, 0x01000000},
+			expected: "::1",
+		},
+		"no address": {
+			addr:     nil,
+			expected: "*",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := inetAddr(tc.addr); got != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestReadFilesWithEmptyIdsImage(t *testing.T) {
+	dir := t.TempDir()
+	for file, magic := range map[string]string{"files.img": "FILES", "ids-1.img": "IDS"} {
+		if err := crit.New("", filepath.Join(dir, file), "", false, false).Encode(&crit.CriuImage{Magic: magic}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, _, err := readFiles(dir, []Process{{PID: 1}}); err == nil {
+		t.Errorf("expected error for empty ids image, got nil")
+	}
+}
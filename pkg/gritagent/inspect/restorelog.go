@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	crmetadata "github.com/checkpoint-restore/checkpointctl/lib"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+const (
+	// RestoreLogFile is written by criu into the images directory of each container, because the shim runs runc
+	// restore without a separate work path.
+	RestoreLogFile = "restore.log"
+	// restoreLogTailLines is the number of lines printed from the end of criu restore log.
+	restoreLogTailLines = 100
+)
+
+// RunRestoreLog prints the tail of criu restore logs of containers in checkpointed data on the restored node(src-dir),
+// so failures of runc restore can be found without logging into the node.
+func RunRestoreLog(ctx context.Context, opts *options.GritAgentOptions) error {
+	// logs on the host are not changed by retrying.
+	if err := WriteRestoreLogs(os.Stdout, opts.SrcDir); err != nil {
+		return metadata.Permanent(err)
+	}
+	return nil
+}
+
+// WriteRestoreLogs prints the tail of criu restore log of each container in dir into w.
+func WriteRestoreLogs(w io.Writer, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name(), crmetadata.CheckpointDirectory, RestoreLogFile))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read criu restore log of container %s: %w", entry.Name(), err)
+		}
+		fmt.Fprintf(w, "container %s\n", entry.Name())
+		writeSection(w, "criu restore log tail", tailLines(data, restoreLogTailLines))
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package inspect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	crmetadata "github.com/checkpoint-restore/checkpointctl/lib"
+)
+
+func TestWriteRestoreLogs(t *testing.T) {
+	testcases := map[string]struct {
+		logs     map[string]string
+		expected string
+	}{
+		"restore logs of containers are printed": {
+			logs: map[string]string{
+				"app":     "(00.010000) restore started\n(00.020000) Error (criu/files-reg.c:1831): Can't open file\n",
+				"sidecar": "",
+			},
+			expected: "container app\n  criu restore log tail:\n    (00.010000) restore started\n    (00.020000) Error (criu/files-reg.c:1831): Can't open file\ncontainer sidecar\n",
+		},
+		"containers which have not been restored are skipped": {
+			logs: map[string]string{},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.MkdirAll(filepath.Join(dir, "not-restored", crmetadata.CheckpointDirectory), 0755); err != nil {
+				t.Fatal(err)
+			}
+			for container, content := range tc.logs {
+				imagesDir := filepath.Join(dir, container, crmetadata.CheckpointDirectory)
+				if err := os.MkdirAll(imagesDir, 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(filepath.Join(imagesDir, RestoreLogFile), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var out bytes.Buffer
+			if err := WriteRestoreLogs(&out, dir); err != nil {
+				t.Fatalf("failed to write restore logs, %v", err)
+			}
+			if out.String() != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, out.String())
+			}
+		})
+	}
+}
@@ -5,17 +5,91 @@ package restore
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
 	"github.com/kaito-project/grit/pkg/gritagent/copy"
+	"github.com/kaito-project/grit/pkg/gritagent/storage"
 	"github.com/kaito-project/grit/pkg/metadata"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
+var tracer = otel.Tracer("github.com/kaito-project/grit/pkg/gritagent/restore")
+
 func RunRestore(ctx context.Context, opts *options.GritAgentOptions) error {
+	store, err := storage.NewStorage(&opts.StorageOptions)
+	if err != nil {
+		return err
+	}
+
+	// verified marker of the previous attempt is removed first, so shim never restores from data which is being downloaded.
+	if err := metadata.RemoveDownloadState(opts.DstDir); err != nil {
+		return err
+	}
+
 	// download checkpointed data from cloud storage
-	if err := copy.TransferData(ctx, opts.SrcDir, opts.DstDir); err != nil {
+	transferStart := time.Now()
+	dataDirs, err := download(ctx, store, opts)
+	if err != nil {
 		return err
 	}
+	transferSeconds := time.Since(transferStart).Seconds()
+
+	// verify all downloaded data against integrity manifest, and the failure is reported to grit manager
+	// for failing the restore with a clear reason.
+	_, span := tracer.Start(ctx, "verify")
+	for _, dir := range dataDirs {
+		if err := metadata.VerifyManifest(dir); err != nil {
+			tracing.EndSpan(span, err)
+			if reportErr := metadata.WriteAgentReport(opts.TerminationMessagePath, &metadata.AgentReport{VerificationError: err.Error()}); reportErr != nil {
+				log.FromContext(ctx).Error(reportErr, "failed to report verification error")
+			}
+			return metadata.Permanent(fmt.Errorf("failed to verify checkpointed data: %w", err))
+		}
+		log.FromContext(ctx).Info("checkpointed data is verified", "dir", dir)
+	}
+	span.End()
 
-	return copy.CreateSentinelFile(opts.DstDir, metadata.DownloadSentinelFile)
+	// report downloaded data size to grit manager through termination message, it's only used for metrics.
+	report := &metadata.AgentReport{TransferSeconds: transferSeconds}
+	for _, dir := range dataDirs {
+		size, err := copy.DirSize(dir)
+		if err != nil {
+			return err
+		}
+		report.TransferredSize += size
+	}
+	if err := metadata.WriteAgentReport(opts.TerminationMessagePath, report); err != nil {
+		log.FromContext(ctx).Error(err, "failed to write report of grit agent")
+	}
+
+	// checkpointed data is verified, shim is allowed to restore containers from it.
+	return metadata.WriteDownloadState(opts.DstDir)
+}
+
+// download downloads checkpointed data of the checkpoint and all its parent checkpoints, and returns the directories of
+// downloaded data. incremental checkpoint only contains memory pages which have been changed since parent checkpoint,
+// so checkpointed data of all parent checkpoints are needed for restoring.
+func download(ctx context.Context, store storage.Storage, opts *options.GritAgentOptions) (dataDirs []string, err error) {
+	ctx, span := tracer.Start(ctx, "transfer")
+	defer func() { tracing.EndSpan(span, err) }()
+
+	if err := store.Download(ctx, opts.SrcDir, opts.DstDir); err != nil {
+		return nil, err
+	}
+	dataDirs = []string{opts.DstDir}
+	for _, parent := range opts.ParentCheckpoints {
+		parentDir := filepath.Join(filepath.Dir(opts.DstDir), parent)
+		if err := store.Download(ctx, path.Join(path.Dir(opts.SrcDir), parent), parentDir); err != nil {
+			return nil, err
+		}
+		dataDirs = append(dataDirs, parentDir)
+	}
+	return dataDirs, nil
 }
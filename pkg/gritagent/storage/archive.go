@@ -0,0 +1,161 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// tarDir writes the named entry of dir and all files under it into tar stream, paths in tar stream are relative to dir.
+// symlinks(like parent images link of incremental checkpoint) are kept as they are.
+func tarDir(dir, name string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(filepath.Join(dir, name), func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		var link string
+		if d.Type()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(relPath)
+		if d.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// untar extracts tar stream into dir, entries which would be extracted out of dir are rejected, and nothing is written
+// through symlinks. symlinks should have relative targets inside root, like the parent images link of incremental
+// checkpoint, which points to checkpointed data of parent checkpoint in the sibling directory of dir.
+func untar(r io.Reader, dir, root string) error {
+	dir, root = filepath.Clean(dir), filepath.Clean(root)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		path := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !isWithin(dir, path) {
+			return fmt.Errorf("invalid entry %s in tar stream", hdr.Name)
+		}
+		if err := mkdirNoFollow(dir, filepath.Dir(path), os.ModePerm); err != nil {
+			return fmt.Errorf("invalid entry %s in tar stream, %w", hdr.Name, err)
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := mkdirNoFollow(dir, path, os.FileMode(hdr.Mode)|0700); err != nil {
+				return fmt.Errorf("invalid entry %s in tar stream, %w", hdr.Name, err)
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(hdr.Linkname) || !isWithin(root, filepath.Join(filepath.Dir(path), hdr.Linkname)) {
+				return fmt.Errorf("invalid link %s -> %s in tar stream", hdr.Name, hdr.Linkname)
+			}
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeFile(path, tr, os.FileMode(hdr.Mode)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported type %c of entry %s in tar stream", hdr.Typeflag, hdr.Name)
+		}
+	}
+}
+
+// isWithin returns whether path is dir or under dir, both of them should be cleaned.
+func isWithin(dir, path string) bool {
+	return path == dir || strings.HasPrefix(path, dir+string(os.PathSeparator))
+}
+
+// mkdirNoFollow creates path and its parents under dir like os.MkdirAll, but existing components which are not
+// directories(like symlinks) are rejected, so nothing is created out of dir through symlinks.
+func mkdirNoFollow(dir, path string, mode os.FileMode) error {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return err
+	}
+	current := dir
+	for _, name := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, name)
+		info, err := os.Lstat(current)
+		switch {
+		case os.IsNotExist(err):
+			if err := os.Mkdir(current, mode); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case !info.IsDir():
+			return fmt.Errorf("%s is not a directory", current)
+		}
+	}
+	return nil
+}
+
+// writeFile writes r into path, and it fails if path is a symlink.
+func writeFile(path string, r io.Reader, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|syscall.O_NOFOLLOW, mode)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
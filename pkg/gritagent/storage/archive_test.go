@@ -0,0 +1,103 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUntar(t *testing.T) {
+	type entry struct {
+		name     string
+		typeflag byte
+		linkname string
+		content  string
+	}
+
+	testcases := map[string]struct {
+		entries      []entry
+		expectError  bool
+		expectedLink string
+	}{
+		"symlink to parent images in sibling directory": {
+			entries: []entry{
+				{name: "container/checkpoint/", typeflag: tar.TypeDir},
+				{name: "container/checkpoint/pages-1.img", typeflag: tar.TypeReg, content: "pages"},
+				{name: "container/checkpoint/parent", typeflag: tar.TypeSymlink, linkname: "../../../parent/container/checkpoint"},
+			},
+			expectedLink: "container/checkpoint/parent",
+		},
+		"entry out of directory": {
+			entries:     []entry{{name: "../passwd", typeflag: tar.TypeReg, content: "root"}},
+			expectError: true,
+		},
+		"symlink with absolute target": {
+			entries: []entry{
+				{name: "a", typeflag: tar.TypeSymlink, linkname: "/etc"},
+				{name: "a/passwd", typeflag: tar.TypeReg, content: "root"},
+			},
+			expectError: true,
+		},
+		"symlink with target out of root": {
+			entries:     []entry{{name: "a", typeflag: tar.TypeSymlink, linkname: "../../etc"}},
+			expectError: true,
+		},
+		"file is written through symlink": {
+			entries: []entry{
+				{name: "a", typeflag: tar.TypeSymlink, linkname: "../parent"},
+				{name: "a/passwd", typeflag: tar.TypeReg, content: "root"},
+			},
+			expectError: true,
+		},
+		"file is symlink": {
+			entries: []entry{
+				{name: "a", typeflag: tar.TypeSymlink, linkname: "../parent/passwd"},
+				{name: "a", typeflag: tar.TypeReg, content: "root"},
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, e := range tc.entries {
+				hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0644, Size: int64(len(e.content))}
+				if err := tw.WriteHeader(hdr); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := tw.Write([]byte(e.content)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := tw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			root := t.TempDir()
+			parentDir := filepath.Join(root, "parent")
+			if err := os.MkdirAll(parentDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := untar(&buf, filepath.Join(root, "ckpt"), root)
+			if tc.expectError != (err != nil) {
+				t.Fatalf("expected error: %v, got %v", tc.expectError, err)
+			}
+			if _, err := os.Stat(filepath.Join(parentDir, "passwd")); !os.IsNotExist(err) {
+				t.Errorf("expected nothing is written out of directory, got %v", err)
+			}
+			if len(tc.expectedLink) != 0 {
+				if _, err := os.Readlink(filepath.Join(root, "ckpt", tc.expectedLink)); err != nil {
+					t.Errorf("expected symlink %s, got %v", tc.expectedLink, err)
+				}
+			}
+		})
+	}
+}
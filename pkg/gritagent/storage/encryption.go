@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+)
+
+// Encrypted stream is composed of a header and a sequence of AES-256-GCM sealed chunks:
+//
+//	header: magic(8 bytes) | key id length(1 byte) | key id | nonce prefix(8 bytes)
+//	chunk:  final flag(1 bit) + sealed length(31 bits) | sealed data
+//
+// the nonce of each chunk is the nonce prefix followed by the chunk index, and the header and final flag are
+// authenticated as additional data, so reordered, truncated or tampered streams can't be decrypted. the key id in
+// header is used for finding the key when decrypting, then keys can be rotated without re-encrypting old streams.
+const (
+	encryptionMagic       = "GRITENC1"
+	encryptionKeySize     = 32
+	encryptionChunkSize   = 64 << 10
+	encryptionNonceSize   = 12
+	encryptionPrefixSize  = 8
+	encryptionFinalFlag   = 1 << 31
+	encryptionMaxIDLength = math.MaxUint8
+)
+
+// readEncryptionKey reads the AES-256 key named by key id from key dir, like a secret mounted into grit agent container.
+func readEncryptionKey(keyDir, keyID string) ([]byte, error) {
+	if len(keyID) == 0 || len(keyID) > encryptionMaxIDLength || filepath.Base(keyID) != keyID {
+		return nil, fmt.Errorf("invalid encryption key id %q", keyID)
+	}
+
+	key, err := os.ReadFile(filepath.Join(keyDir, keyID))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("encryption key %s is not found", keyID)
+	} else if err != nil {
+		return nil, err
+	}
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("encryption key %s should be %d bytes, got %d bytes", keyID, encryptionKeySize, len(key))
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type encryptingWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	header []byte
+	nonce  []byte
+	index  uint32
+	buf    []byte
+}
+
+// newEncryptingWriter returns a writer which encrypts data into w with the key named by key id in key dir. Close must
+// be called for sealing the final chunk, and w is not closed by it.
+func newEncryptingWriter(w io.Writer, keyDir, keyID string) (io.WriteCloser, error) {
+	key, err := readEncryptionKey(keyDir, keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte(encryptionMagic), byte(len(keyID)))
+	header = append(header, keyID...)
+	prefix := make([]byte, encryptionPrefixSize)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, err
+	}
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	return &encryptingWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		nonce:  append(prefix, make([]byte, encryptionNonceSize-encryptionPrefixSize)...),
+		buf:    make([]byte, 0, encryptionChunkSize),
+	}, nil
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	n := 0
+	for len(p) != 0 {
+		if len(e.buf) == encryptionChunkSize {
+			if err := e.seal(false); err != nil {
+				return n, err
+			}
+		}
+		copied := copy(e.buf[len(e.buf):encryptionChunkSize], p)
+		e.buf = e.buf[:len(e.buf)+copied]
+		p = p[copied:]
+		n += copied
+	}
+	return n, nil
+}
+
+func (e *encryptingWriter) Close() error {
+	return e.seal(true)
+}
+
+func (e *encryptingWriter) seal(final bool) error {
+	if e.index == math.MaxUint32 {
+		return errors.New("encrypted stream is too large")
+	}
+	binary.BigEndian.PutUint32(e.nonce[encryptionPrefixSize:], e.index)
+	e.index++
+
+	sealed := e.aead.Seal(nil, e.nonce, e.buf, chunkAdditionalData(e.header, final))
+	length := uint32(len(sealed))
+	if final {
+		length |= encryptionFinalFlag
+	}
+	if err := binary.Write(e.w, binary.BigEndian, length); err != nil {
+		return err
+	}
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+type decryptingReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	header []byte
+	nonce  []byte
+	index  uint32
+	plain  []byte
+	final  bool
+}
+
+// newDecryptingReader returns a reader which decrypts data from r, and the key is found in key dir by the key id in
+// the header of encrypted stream.
+func newDecryptingReader(r io.Reader, keyDir string) (io.Reader, error) {
+	header := make([]byte, len(encryptionMagic)+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read header of encrypted stream: %w", err)
+	} else if string(header[:len(encryptionMagic)]) != encryptionMagic {
+		return nil, errors.New("invalid header of encrypted stream")
+	}
+	rest := make([]byte, int(header[len(encryptionMagic)])+encryptionPrefixSize)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return nil, fmt.Errorf("failed to read header of encrypted stream: %w", err)
+	}
+	header = append(header, rest...)
+	keyID, prefix := string(rest[:len(rest)-encryptionPrefixSize]), rest[len(rest)-encryptionPrefixSize:]
+
+	key, err := readEncryptionKey(keyDir, keyID)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &decryptingReader{
+		r:      r,
+		aead:   aead,
+		header: header,
+		nonce:  append(append([]byte{}, prefix...), make([]byte, encryptionNonceSize-encryptionPrefixSize)...),
+	}, nil
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.final {
+			return 0, io.EOF
+		}
+		if err := d.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *decryptingReader) open() error {
+	var length uint32
+	if err := binary.Read(d.r, binary.BigEndian, &length); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return fmt.Errorf("encrypted stream is truncated: %w", err)
+	}
+	final := length&encryptionFinalFlag != 0
+	length &^= encryptionFinalFlag
+	if length > encryptionChunkSize+uint32(d.aead.Overhead()) {
+		return fmt.Errorf("invalid chunk length %d of encrypted stream", length)
+	}
+
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(d.r, sealed); err != nil {
+		return fmt.Errorf("encrypted stream is truncated: %w", err)
+	}
+	binary.BigEndian.PutUint32(d.nonce[encryptionPrefixSize:], d.index)
+	d.index++
+
+	plain, err := d.aead.Open(sealed[:0], d.nonce, sealed, chunkAdditionalData(d.header, final))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt chunk %d of encrypted stream: %w", d.index-1, err)
+	}
+	d.plain, d.final = plain, final
+	return nil
+}
+
+func chunkAdditionalData(header []byte, final bool) []byte {
+	data := append([]byte{}, header...)
+	if final {
+		return append(data, 1)
+	}
+	return append(data, 0)
+}
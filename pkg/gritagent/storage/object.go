@@ -0,0 +1,212 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"golang.org/x/sync/errgroup"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+)
+
+const (
+	// object storage has no symlink, so symlink(like parent images link of incremental checkpoint) is stored as an object
+	// with this suffix, and the content of object is the link target.
+	symlinkSuffix = ".grit-symlink"
+	// maxConcurrentTransfers is the max number of objects which are uploaded or downloaded at the same time.
+	maxConcurrentTransfers = 10
+	// multipartSize is the part size for uploading stream of unknown size, each part is buffered in memory.
+	multipartSize = 64 << 20
+)
+
+// ObjectStorage stores checkpointed data in S3-compatible object storage, each file is stored as an object and the
+// storage dir is the common prefix of object keys.
+type ObjectStorage struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewObjectStorage creates object storage client, credentials are loaded from environment variables(AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY or MINIO_ACCESS_KEY, MINIO_SECRET_KEY) first, then from IAM role of the node.
+func NewObjectStorage(opts *options.StorageOptions) (*ObjectStorage, error) {
+	if len(opts.S3Endpoint) == 0 || len(opts.S3Bucket) == 0 {
+		return nil, fmt.Errorf("endpoint or bucket of object storage is not specified")
+	}
+
+	client, err := minio.New(opts.S3Endpoint, &minio.Options{
+		Creds: credentials.NewChainCredentials([]credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+			&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+		}),
+		Secure: !opts.S3Insecure,
+		Region: opts.S3Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectStorage{
+		client: client,
+		bucket: opts.S3Bucket,
+	}, nil
+}
+
+func (s *ObjectStorage) Upload(ctx context.Context, localDir, storageDir string) (int64, error) {
+	log.FromContext(ctx).Info("start to upload data", "src-dir", localDir, "bucket", s.bucket, "dst-dir", storageDir)
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentTransfers)
+	var size atomic.Int64
+	err := filepath.WalkDir(localDir, func(filePath string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(localDir, filePath)
+		if err != nil {
+			return err
+		}
+		key := path.Join(storageDir, filepath.ToSlash(relPath))
+
+		if d.Type()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(filePath)
+			if err != nil {
+				return err
+			}
+			return s.WriteFile(ctx, key+symlinkSuffix, strings.NewReader(target))
+		}
+
+		g.Go(func() error {
+			info, err := s.client.FPutObject(gctx, s.bucket, key, filePath, minio.PutObjectOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to upload %s: %w", filePath, err)
+			}
+			size.Add(info.Size)
+			log.FromContext(ctx).Info("upload file successfully", "src-file", filePath, "key", key)
+			return nil
+		})
+		return nil
+	})
+	if gerr := g.Wait(); err == nil {
+		err = gerr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	log.FromContext(ctx).Info("data upload completed", "src-dir", localDir, "dst-dir", storageDir, "size", size.Load())
+	return size.Load(), nil
+}
+
+func (s *ObjectStorage) Download(ctx context.Context, storageDir, localDir string) error {
+	log.FromContext(ctx).Info("start to download data", "bucket", s.bucket, "src-dir", storageDir, "dst-dir", localDir)
+	if err := os.MkdirAll(localDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	// stop listing objects when download is interrupted by error.
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentTransfers)
+	prefix := strings.TrimSuffix(storageDir, "/") + "/"
+	var err error
+	for object := range s.client.ListObjects(listCtx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			err = object.Err
+			break
+		}
+
+		key := object.Key
+		filePath := filepath.Join(localDir, filepath.FromSlash(strings.TrimPrefix(key, prefix)))
+		if strings.HasSuffix(key, symlinkSuffix) {
+			if err = s.downloadSymlink(ctx, key, strings.TrimSuffix(filePath, symlinkSuffix)); err != nil {
+				break
+			}
+			continue
+		}
+
+		g.Go(func() error {
+			if err := s.client.FGetObject(gctx, s.bucket, key, filePath, minio.GetObjectOptions{}); err != nil {
+				return fmt.Errorf("failed to download %s: %w", key, err)
+			}
+			log.FromContext(ctx).Info("download file successfully", "key", key, "dst-file", filePath)
+			return nil
+		})
+	}
+	if gerr := g.Wait(); err == nil {
+		err = gerr
+	}
+	if err != nil {
+		return err
+	}
+
+	log.FromContext(ctx).Info("data download completed", "src-dir", storageDir, "dst-dir", localDir)
+	return nil
+}
+
+func (s *ObjectStorage) downloadSymlink(ctx context.Context, key, linkPath string) error {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+
+	target, err := io.ReadAll(object)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(linkPath), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.Symlink(string(target), linkPath)
+}
+
+// WriteFile uploads data of unknown size as a multipart object, so large stream(like compressed checkpointed data)
+// can be uploaded without buffering it entirely.
+func (s *ObjectStorage) WriteFile(ctx context.Context, storagePath string, r io.Reader) error {
+	_, err := s.client.PutObject(ctx, s.bucket, storagePath, r, -1, minio.PutObjectOptions{PartSize: multipartSize})
+	return err
+}
+
+func (s *ObjectStorage) ReadFile(ctx context.Context, storagePath string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, storagePath, minio.GetObjectOptions{})
+}
+
+func (s *ObjectStorage) Exists(ctx context.Context, storagePath string) (bool, error) {
+	if _, err := s.client.StatObject(ctx, s.bucket, storagePath, minio.StatObjectOptions{}); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *ObjectStorage) Remove(ctx context.Context, storageDir string) error {
+	objects := s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: strings.TrimSuffix(storageDir, "/") + "/", Recursive: true})
+	for result := range s.client.RemoveObjects(ctx, s.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if result.Err != nil {
+			return fmt.Errorf("failed to remove %s: %w", result.ObjectName, result.Err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+)
+
+// TestObjectStorage runs against a local MinIO, for example:
+//
+//	minio server /tmp/minio &
+//	GRIT_TEST_S3_ENDPOINT=127.0.0.1:9000 AWS_ACCESS_KEY_ID=minioadmin AWS_SECRET_ACCESS_KEY=minioadmin go test ./pkg/gritagent/storage/...
+func TestObjectStorage(t *testing.T) {
+	endpoint := os.Getenv("GRIT_TEST_S3_ENDPOINT")
+	if len(endpoint) == 0 {
+		t.Skip("GRIT_TEST_S3_ENDPOINT is not set")
+	}
+
+	ctx := context.Background()
+	store, err := NewObjectStorage(&options.StorageOptions{
+		StorageType: options.StorageTypeS3,
+		S3Endpoint:  endpoint,
+		S3Bucket:    "grit-test",
+		S3Insecure:  true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create object storage, %v", err)
+	}
+	if exists, err := store.client.BucketExists(ctx, store.bucket); err != nil {
+		t.Fatalf("failed to check bucket, %v", err)
+	} else if !exists {
+		if err := store.client.MakeBucket(ctx, store.bucket, minio.MakeBucketOptions{}); err != nil {
+			t.Fatalf("failed to create bucket, %v", err)
+		}
+	}
+
+	srcDir := t.TempDir()
+	os.MkdirAll(filepath.Join(srcDir, "container", "checkpoint"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "container", "checkpoint", "pages-1.img"), []byte("pages"), 0644)
+	os.Symlink("../../parent/container/checkpoint", filepath.Join(srcDir, "container", "checkpoint", "parent"))
+
+	t.Run("upload and download", func(t *testing.T) {
+		if _, err := store.Upload(ctx, srcDir, "default/ckpt"); err != nil {
+			t.Fatalf("failed to upload, %v", err)
+		}
+
+		dstDir := t.TempDir()
+		if err := store.Download(ctx, "default/ckpt", dstDir); err != nil {
+			t.Fatalf("failed to download, %v", err)
+		}
+
+		if data, err := os.ReadFile(filepath.Join(dstDir, "container", "checkpoint", "pages-1.img")); err != nil || string(data) != "pages" {
+			t.Fatalf("expected downloaded file with content pages, got %q, %v", data, err)
+		}
+		if target, err := os.Readlink(filepath.Join(dstDir, "container", "checkpoint", "parent")); err != nil || target != "../../parent/container/checkpoint" {
+			t.Fatalf("expected downloaded symlink to parent images, got %q, %v", target, err)
+		}
+	})
+
+	t.Run("write file and remove", func(t *testing.T) {
+		if err := store.WriteFile(ctx, "default/ckpt/sentinel", strings.NewReader("done")); err != nil {
+			t.Fatalf("failed to write file, %v", err)
+		}
+		if exists, err := store.Exists(ctx, "default/ckpt/sentinel"); err != nil || !exists {
+			t.Fatalf("expected sentinel file exists, got %v, %v", exists, err)
+		}
+
+		if err := store.Remove(ctx, "default/ckpt"); err != nil {
+			t.Fatalf("failed to remove, %v", err)
+		}
+		if exists, err := store.Exists(ctx, "default/ckpt/sentinel"); err != nil || exists {
+			t.Fatalf("expected sentinel file removed, got %v, %v", exists, err)
+		}
+	})
+}
@@ -0,0 +1,179 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"context"
+	"io"
+	"path"
+	"path/filepath"
+
+	"github.com/klauspost/compress/zstd"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+// PackedStorage packs all files of local dir into a tar stream which is zstd compressed and/or encrypted, and the
+// stream is stored as a single file(metadata.PackedStreamFile with suffixes) in the storage dir of the underlying storage.
+type PackedStorage struct {
+	Storage
+	opts *options.StorageOptions
+}
+
+func NewPackedStorage(store Storage, opts *options.StorageOptions) *PackedStorage {
+	return &PackedStorage{
+		Storage: store,
+		opts:    opts,
+	}
+}
+
+func (s *PackedStorage) Upload(ctx context.Context, localDir, storageDir string) (int64, error) {
+	streamPath := path.Join(storageDir, packedStreamFile(s.opts.CompressionLevel != 0, len(s.opts.EncryptionKeyID) != 0))
+	log.FromContext(ctx).Info("start to upload packed stream", "src-dir", localDir, "dst-file", streamPath, "level", s.opts.CompressionLevel, "key-id", s.opts.EncryptionKeyID)
+
+	pr, pw := io.Pipe()
+	counter := &countingReader{r: pr}
+	go func() {
+		pw.CloseWithError(packTo(pw, s.opts, func(w io.Writer) error {
+			return tarDir(localDir, ".", w)
+		}))
+	}()
+
+	err := s.WriteFile(ctx, streamPath, counter)
+	// unblock the packing goroutine if the stream is not consumed completely.
+	pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		return 0, err
+	}
+
+	log.FromContext(ctx).Info("packed stream upload completed", "src-dir", localDir, "dst-file", streamPath, "size", counter.n)
+	return counter.n, nil
+}
+
+// Download unpacks the packed stream into local dir, and falls back to the underlying storage if there is no packed
+// stream in the storage dir(like parent checkpoint which is neither compressed nor encrypted).
+func (s *PackedStorage) Download(ctx context.Context, storageDir, localDir string) error {
+	for _, transform := range []struct{ compressed, encrypted bool }{{true, true}, {false, true}, {true, false}} {
+		streamPath := path.Join(storageDir, packedStreamFile(transform.compressed, transform.encrypted))
+		if exists, err := s.Exists(ctx, streamPath); err != nil {
+			return err
+		} else if !exists {
+			continue
+		}
+
+		log.FromContext(ctx).Info("start to download packed stream", "src-file", streamPath, "dst-dir", localDir)
+		rc, err := s.ReadFile(ctx, streamPath)
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+
+		// encrypted stream is only decrypted here, so plain data is only written into local dir.
+		if err := unpackFrom(rc, s.opts, transform.compressed, transform.encrypted, func(r io.Reader) error {
+			return untar(r, localDir, filepath.Dir(localDir))
+		}); err != nil {
+			return err
+		}
+
+		log.FromContext(ctx).Info("packed stream download completed", "src-file", streamPath, "dst-dir", localDir)
+		return nil
+	}
+
+	return s.Storage.Download(ctx, storageDir, localDir)
+}
+
+func packedStreamFile(compressed, encrypted bool) string {
+	name := metadata.PackedStreamFile
+	if compressed {
+		name += metadata.CompressedStreamSuffix
+	}
+	if encrypted {
+		name += metadata.EncryptedStreamSuffix
+	}
+	return name
+}
+
+// packTo writes data generated by pack into w, and the data is compressed and then encrypted if they are enabled in opts.
+func packTo(w io.Writer, opts *options.StorageOptions, pack func(io.Writer) error) error {
+	if len(opts.EncryptionKeyID) == 0 {
+		return compressTo(w, opts, pack)
+	}
+
+	encrypter, err := newEncryptingWriter(w, opts.EncryptionKeyDir, opts.EncryptionKeyID)
+	if err != nil {
+		return err
+	}
+	if err := compressTo(encrypter, opts, pack); err != nil {
+		return err
+	}
+	return encrypter.Close()
+}
+
+// unpackFrom decrypts and then decompresses data from r, and the plain data is consumed by unpack.
+func unpackFrom(r io.Reader, opts *options.StorageOptions, compressed, encrypted bool, unpack func(io.Reader) error) error {
+	if encrypted {
+		decrypter, err := newDecryptingReader(r, opts.EncryptionKeyDir)
+		if err != nil {
+			return err
+		}
+		if err := unpackFrom(decrypter, opts, compressed, false, unpack); err != nil {
+			return err
+		}
+		// read until the final chunk for making sure the encrypted stream is not truncated.
+		_, err = io.Copy(io.Discard, decrypter)
+		return err
+	}
+
+	if compressed {
+		return decompressFrom(r, unpack)
+	}
+	return unpack(r)
+}
+
+// compressTo compresses data written by pack into w with zstd multi-threaded encoder, and data is written into w
+// directly when compression is disabled.
+func compressTo(w io.Writer, opts *options.StorageOptions, pack func(io.Writer) error) error {
+	if opts.CompressionLevel == 0 {
+		return pack(w)
+	}
+
+	encoderOpts := []zstd.EOption{zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(opts.CompressionLevel))}
+	if opts.CompressionConcurrency > 0 {
+		encoderOpts = append(encoderOpts, zstd.WithEncoderConcurrency(opts.CompressionConcurrency))
+	}
+	encoder, err := zstd.NewWriter(w, encoderOpts...)
+	if err != nil {
+		return err
+	}
+
+	if err := pack(encoder); err != nil {
+		encoder.Close()
+		return err
+	}
+	return encoder.Close()
+}
+
+// decompressFrom decompresses zstd stream from r, and the decompressed data is consumed by unpack.
+func decompressFrom(r io.Reader, unpack func(io.Reader) error) error {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer decoder.Close()
+
+	return unpack(decoder)
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+)
+
+func TestPackedStorage(t *testing.T) {
+	ctx := context.Background()
+	keyDir := t.TempDir()
+	os.WriteFile(filepath.Join(keyDir, "key-1"), bytes.Repeat([]byte{1}, encryptionKeySize), 0600)
+	os.WriteFile(filepath.Join(keyDir, "key-2"), bytes.Repeat([]byte{2}, encryptionKeySize), 0600)
+
+	srcDir := t.TempDir()
+	os.MkdirAll(filepath.Join(srcDir, "container", "checkpoint"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "container", "checkpoint", "pages-1.img"), bytes.Repeat([]byte("pages"), encryptionChunkSize), 0644)
+	os.Symlink("../../parent/container/checkpoint", filepath.Join(srcDir, "container", "checkpoint", "parent"))
+
+	testcases := map[string]struct {
+		uploadOpts   *options.StorageOptions
+		downloadOpts *options.StorageOptions
+		streamFile   string
+		corrupt      func(streamPath string)
+		expectError  bool
+	}{
+		"compressed stream": {
+			uploadOpts:   &options.StorageOptions{CompressionLevel: 3, CompressionConcurrency: 2},
+			downloadOpts: &options.StorageOptions{CompressionLevel: 3},
+			streamFile:   "checkpoint.tar.zst",
+		},
+		"compressed and encrypted stream": {
+			uploadOpts:   &options.StorageOptions{CompressionLevel: 3, EncryptionKeyDir: keyDir, EncryptionKeyID: "key-1"},
+			downloadOpts: &options.StorageOptions{CompressionLevel: 3, EncryptionKeyDir: keyDir},
+			streamFile:   "checkpoint.tar.zst.enc",
+		},
+		"encrypted stream is decrypted with rotated keys": {
+			uploadOpts:   &options.StorageOptions{EncryptionKeyDir: keyDir, EncryptionKeyID: "key-1"},
+			downloadOpts: &options.StorageOptions{EncryptionKeyDir: keyDir, EncryptionKeyID: "key-2"},
+			streamFile:   "checkpoint.tar.enc",
+		},
+		"encryption key is removed": {
+			uploadOpts:   &options.StorageOptions{EncryptionKeyDir: keyDir, EncryptionKeyID: "key-1"},
+			downloadOpts: &options.StorageOptions{EncryptionKeyDir: t.TempDir()},
+			streamFile:   "checkpoint.tar.enc",
+			expectError:  true,
+		},
+		"encrypted stream is tampered": {
+			uploadOpts:   &options.StorageOptions{EncryptionKeyDir: keyDir, EncryptionKeyID: "key-1"},
+			downloadOpts: &options.StorageOptions{EncryptionKeyDir: keyDir},
+			streamFile:   "checkpoint.tar.enc",
+			corrupt: func(streamPath string) {
+				data, _ := os.ReadFile(streamPath)
+				data[len(data)/2] ^= 0xff
+				os.WriteFile(streamPath, data, 0644)
+			},
+			expectError: true,
+		},
+		"encrypted stream is truncated": {
+			uploadOpts:   &options.StorageOptions{EncryptionKeyDir: keyDir, EncryptionKeyID: "key-1"},
+			downloadOpts: &options.StorageOptions{EncryptionKeyDir: keyDir},
+			streamFile:   "checkpoint.tar.enc",
+			corrupt: func(streamPath string) {
+				data, _ := os.ReadFile(streamPath)
+				os.WriteFile(streamPath, data[:len(data)-100], 0644)
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			storageDir := filepath.Join(t.TempDir(), "default", "ckpt")
+			size, err := NewPackedStorage(NewVolumeStorage(), tc.uploadOpts).Upload(ctx, srcDir, storageDir)
+			if err != nil {
+				t.Fatalf("failed to upload, %v", err)
+			}
+			streamPath := filepath.Join(storageDir, tc.streamFile)
+			if info, err := os.Stat(streamPath); err != nil || info.Size() != size {
+				t.Fatalf("expected packed stream %s with size %d, got %v", tc.streamFile, size, err)
+			}
+			if tc.corrupt != nil {
+				tc.corrupt(streamPath)
+			}
+
+			dstDir := t.TempDir()
+			err = NewPackedStorage(NewVolumeStorage(), tc.downloadOpts).Download(ctx, storageDir, dstDir)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected download error, got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("failed to download, %v", err)
+			}
+
+			if data, err := os.ReadFile(filepath.Join(dstDir, "container", "checkpoint", "pages-1.img")); err != nil || !bytes.Equal(data, bytes.Repeat([]byte("pages"), encryptionChunkSize)) {
+				t.Fatalf("expected downloaded file with the same content, got %d bytes, %v", len(data), err)
+			}
+			if target, err := os.Readlink(filepath.Join(dstDir, "container", "checkpoint", "parent")); err != nil || target != "../../parent/container/checkpoint" {
+				t.Fatalf("expected downloaded symlink to parent images, got %q, %v", target, err)
+			}
+		})
+	}
+
+	t.Run("fall back to unpacked data", func(t *testing.T) {
+		dstDir := t.TempDir()
+		if err := NewPackedStorage(NewVolumeStorage(), &options.StorageOptions{CompressionLevel: 3}).Download(ctx, srcDir, dstDir); err != nil {
+			t.Fatalf("failed to download, %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dstDir, "container", "checkpoint", "pages-1.img")); err != nil {
+			t.Fatalf("expected downloaded file, %v", err)
+		}
+	})
+}
@@ -0,0 +1,344 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/containerd/containerd/v2/core/content"
+	"github.com/containerd/containerd/v2/core/remotes"
+	"github.com/containerd/containerd/v2/core/remotes/docker"
+	"github.com/containerd/errdefs"
+	"github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+// RegistryStorage publishes checkpointed data as an OCI artifact into container registry, the storage dir is the
+// repository of artifact, and the artifact is tagged with metadata.CheckpointArtifactTag.
+type RegistryStorage struct {
+	resolver remotes.Resolver
+	opts     *options.StorageOptions
+}
+
+// NewRegistryStorage creates registry client, credentials are loaded from docker config file(like the content of
+// kubernetes.io/dockerconfigjson secret) if it's specified.
+func NewRegistryStorage(opts *options.StorageOptions) (*RegistryStorage, error) {
+	creds := func(string) (string, string, error) { return "", "", nil }
+	if len(opts.RegistryConfig) != 0 {
+		auths, err := loadDockerConfig(opts.RegistryConfig)
+		if err != nil {
+			return nil, err
+		}
+		creds = auths.credentials
+	}
+
+	plainHTTP := docker.MatchLocalhost
+	if opts.RegistryInsecure {
+		plainHTTP = docker.MatchAllHosts
+	}
+
+	return &RegistryStorage{
+		resolver: docker.NewResolver(docker.ResolverOptions{
+			Hosts: docker.ConfigureDefaultRegistries(
+				docker.WithPlainHTTP(plainHTTP),
+				docker.WithAuthorizer(docker.NewDockerAuthorizer(docker.WithAuthCreds(creds))),
+			),
+		}),
+		opts: opts,
+	}, nil
+}
+
+// Upload packs each top level entry of local dir as a layer, then pushes layers and manifest of checkpoint artifact.
+// layers are compressed with zstd when compression is enabled.
+func (s *RegistryStorage) Upload(ctx context.Context, localDir, storageDir string) (int64, error) {
+	ref := artifactReference(storageDir)
+	log.FromContext(ctx).Info("start to push checkpoint artifact", "src-dir", localDir, "reference", ref)
+	pusher, err := s.resolver.Pusher(ctx, ref)
+	if err != nil {
+		return 0, err
+	}
+
+	entries, err := os.ReadDir(localDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var layers []ocispec.Descriptor
+	var size int64
+	for _, entry := range entries {
+		layer, err := s.pushLayer(ctx, pusher, localDir, entry.Name())
+		if err != nil {
+			return 0, fmt.Errorf("failed to push layer %s: %w", entry.Name(), err)
+		}
+		layers = append(layers, layer)
+		size += layer.Size
+		log.FromContext(ctx).Info("push layer successfully", "name", entry.Name(), "digest", layer.Digest, "size", layer.Size)
+	}
+
+	config := ocispec.DescriptorEmptyJSON
+	if err := pushBlob(ctx, pusher, config, config.Data); err != nil {
+		return 0, err
+	}
+	config.Data = nil
+
+	manifest := ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: metadata.CheckpointArtifactType,
+		Config:       config,
+		Layers:       layers,
+		Annotations: map[string]string{
+			ocispec.AnnotationCreated: time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return 0, err
+	}
+	desc := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: metadata.CheckpointArtifactType,
+		Digest:       digest.FromBytes(data),
+		Size:         int64(len(data)),
+	}
+	if err := pushBlob(ctx, pusher, desc, data); err != nil {
+		return 0, err
+	}
+
+	log.FromContext(ctx).Info("checkpoint artifact is pushed", "reference", ref, "digest", desc.Digest, "size", size)
+	return size, nil
+}
+
+// Download pulls checkpoint artifact and unpacks each layer into local dir, content of each layer is verified with its digest.
+func (s *RegistryStorage) Download(ctx context.Context, storageDir, localDir string) error {
+	ref := artifactReference(storageDir)
+	log.FromContext(ctx).Info("start to pull checkpoint artifact", "reference", ref, "dst-dir", localDir)
+	name, desc, err := s.resolver.Resolve(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	fetcher, err := s.resolver.Fetcher(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	data, err := fetchBlob(ctx, fetcher, desc)
+	if err != nil {
+		return err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return err
+	}
+	if manifest.ArtifactType != metadata.CheckpointArtifactType {
+		return fmt.Errorf("%s is not a checkpoint artifact, artifact type is %q", ref, manifest.ArtifactType)
+	}
+
+	if err := os.MkdirAll(localDir, os.ModePerm); err != nil {
+		return err
+	}
+	for _, layer := range manifest.Layers {
+		if err := s.pullLayer(ctx, fetcher, layer, localDir); err != nil {
+			return fmt.Errorf("failed to pull layer %s: %w", layer.Digest, err)
+		}
+		log.FromContext(ctx).Info("pull layer successfully", "name", layer.Annotations[ocispec.AnnotationTitle], "digest", layer.Digest)
+	}
+
+	log.FromContext(ctx).Info("checkpoint artifact is pulled", "reference", ref, "digest", desc.Digest)
+	return nil
+}
+
+func (s *RegistryStorage) WriteFile(_ context.Context, _ string, _ io.Reader) error {
+	return errors.New("writing a single file is not supported by registry storage")
+}
+
+func (s *RegistryStorage) ReadFile(_ context.Context, _ string) (io.ReadCloser, error) {
+	return nil, errors.New("reading a single file is not supported by registry storage")
+}
+
+func (s *RegistryStorage) Exists(_ context.Context, _ string) (bool, error) {
+	return false, errors.New("checking a single file is not supported by registry storage")
+}
+
+// Remove keeps checkpoint artifact in registry, because deleting manifest is disabled in most registries by default,
+// and artifacts should be pruned by the retention policy of registry.
+func (s *RegistryStorage) Remove(ctx context.Context, storageDir string) error {
+	log.FromContext(ctx).Info("checkpoint artifact is kept in registry", "reference", artifactReference(storageDir))
+	return nil
+}
+
+func artifactReference(storageDir string) string {
+	return fmt.Sprintf("%s:%s", storageDir, metadata.CheckpointArtifactTag)
+}
+
+// pushLayer packs the named entry of dir into a temporary tar file for computing digest, then pushes the tar file.
+func (s *RegistryStorage) pushLayer(ctx context.Context, pusher remotes.Pusher, dir, name string) (ocispec.Descriptor, error) {
+	f, err := os.CreateTemp("", "grit-layer-*.tar")
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer func() {
+		f.Close()
+		os.Remove(f.Name())
+	}()
+
+	digester := digest.Canonical.Digester()
+	mediaType := metadata.CheckpointLayerMediaType
+	if s.opts.CompressionLevel != 0 {
+		mediaType += metadata.CheckpointLayerCompressedSuffix
+	}
+	if len(s.opts.EncryptionKeyID) != 0 {
+		mediaType += metadata.CheckpointLayerEncryptedSuffix
+	}
+	if err := packTo(io.MultiWriter(f, digester.Hash()), s.opts, func(w io.Writer) error {
+		return tarDir(dir, name, w)
+	}); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digester.Digest(),
+		Size:      size,
+		Annotations: map[string]string{
+			ocispec.AnnotationTitle: name,
+		},
+	}
+	return desc, pushContent(ctx, pusher, desc, f)
+}
+
+func pushBlob(ctx context.Context, pusher remotes.Pusher, desc ocispec.Descriptor, data []byte) error {
+	return pushContent(ctx, pusher, desc, bytes.NewReader(data))
+}
+
+func pushContent(ctx context.Context, pusher remotes.Pusher, desc ocispec.Descriptor, r io.Reader) error {
+	w, err := pusher.Push(ctx, desc)
+	if err != nil {
+		if errdefs.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	defer w.Close()
+
+	return content.Copy(ctx, w, r, desc.Size, desc.Digest)
+}
+
+func fetchBlob(ctx context.Context, fetcher remotes.Fetcher, desc ocispec.Descriptor) ([]byte, error) {
+	rc, err := fetcher.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, desc.Size))
+	if err != nil {
+		return nil, err
+	}
+	if digest.FromBytes(data) != desc.Digest {
+		return nil, fmt.Errorf("content of blob doesn't match digest %s", desc.Digest)
+	}
+	return data, nil
+}
+
+func (s *RegistryStorage) pullLayer(ctx context.Context, fetcher remotes.Fetcher, layer ocispec.Descriptor, dir string) error {
+	mediaType := layer.MediaType
+	encrypted := strings.HasSuffix(mediaType, metadata.CheckpointLayerEncryptedSuffix)
+	mediaType = strings.TrimSuffix(mediaType, metadata.CheckpointLayerEncryptedSuffix)
+	compressed := strings.HasSuffix(mediaType, metadata.CheckpointLayerCompressedSuffix)
+	mediaType = strings.TrimSuffix(mediaType, metadata.CheckpointLayerCompressedSuffix)
+	if mediaType != metadata.CheckpointLayerMediaType {
+		return fmt.Errorf("unknown media type %s of layer", layer.MediaType)
+	}
+
+	rc, err := fetcher.Fetch(ctx, layer)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	verifier := layer.Digest.Verifier()
+	r := io.TeeReader(rc, verifier)
+	if err := unpackFrom(r, s.opts, compressed, encrypted, func(r io.Reader) error {
+		return untar(r, dir, filepath.Dir(dir))
+	}); err != nil {
+		return err
+	}
+	// tar stream may have padding after the last entry.
+	if _, err := io.Copy(io.Discard, r); err != nil {
+		return err
+	}
+
+	if !verifier.Verified() {
+		return fmt.Errorf("content of layer doesn't match digest %s", layer.Digest)
+	}
+	return nil
+}
+
+type dockerConfig struct {
+	Auths map[string]dockerAuth `json:"auths"`
+}
+
+type dockerAuth struct {
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+func loadDockerConfig(file string) (*dockerConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var config dockerConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse docker config %s: %w", file, err)
+	}
+	return &config, nil
+}
+
+// credentials returns username and password of the registry host, registry keys in docker config may have scheme and path.
+func (c *dockerConfig) credentials(host string) (string, string, error) {
+	for registry, auth := range c.Auths {
+		registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+		if registry, _, _ = strings.Cut(registry, "/"); registry != host {
+			continue
+		}
+
+		if len(auth.Auth) == 0 {
+			return auth.Username, auth.Password, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to decode auth of registry %s: %w", host, err)
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		return username, password, nil
+	}
+	return "", "", nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+)
+
+// TestRegistryStorage runs against a local registry, for example:
+//
+//	docker run -d -p 5000:5000 registry:2
+//	GRIT_TEST_REGISTRY=127.0.0.1:5000 go test ./pkg/gritagent/storage/...
+func TestRegistryStorage(t *testing.T) {
+	registry := os.Getenv("GRIT_TEST_REGISTRY")
+	if len(registry) == 0 {
+		t.Skip("GRIT_TEST_REGISTRY is not set")
+	}
+
+	ctx := context.Background()
+	store, err := NewRegistryStorage(&options.StorageOptions{
+		StorageType:      options.StorageTypeRegistry,
+		RegistryInsecure: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create registry storage, %v", err)
+	}
+
+	srcDir := t.TempDir()
+	os.MkdirAll(filepath.Join(srcDir, "container", "checkpoint"), 0755)
+	os.WriteFile(filepath.Join(srcDir, "container", "checkpoint", "pages-1.img"), []byte("pages"), 0644)
+	os.WriteFile(filepath.Join(srcDir, "container", "rootfs-diff.tar"), []byte("rootfs"), 0644)
+	os.Symlink("../../parent/container/checkpoint", filepath.Join(srcDir, "container", "checkpoint", "parent"))
+
+	repository := filepath.Join(registry, "grit", "default", "ckpt")
+	if _, err := store.Upload(ctx, srcDir, repository); err != nil {
+		t.Fatalf("failed to push, %v", err)
+	}
+
+	dstDir := t.TempDir()
+	if err := store.Download(ctx, repository, dstDir); err != nil {
+		t.Fatalf("failed to pull, %v", err)
+	}
+
+	for file, content := range map[string]string{"checkpoint/pages-1.img": "pages", "rootfs-diff.tar": "rootfs"} {
+		if data, err := os.ReadFile(filepath.Join(dstDir, "container", file)); err != nil || string(data) != content {
+			t.Fatalf("expected pulled file %s with content %s, got %q, %v", file, content, data, err)
+		}
+	}
+	if target, err := os.Readlink(filepath.Join(dstDir, "container", "checkpoint", "parent")); err != nil || target != "../../parent/container/checkpoint" {
+		t.Fatalf("expected pulled symlink to parent images, got %q, %v", target, err)
+	}
+}
+
+func TestDockerConfigCredentials(t *testing.T) {
+	config := &dockerConfig{
+		Auths: map[string]dockerAuth{
+			"https://registry.example.com/v1/": {Auth: "dXNlcjpwYXNz"},
+			"localhost:5000":                   {Username: "admin", Password: "secret"},
+		},
+	}
+
+	for host, expected := range map[string][2]string{
+		"registry.example.com": {"user", "pass"},
+		"localhost:5000":       {"admin", "secret"},
+		"unknown.example.com":  {"", ""},
+	} {
+		username, password, err := config.credentials(host)
+		if err != nil || username != expected[0] || password != expected[1] {
+			t.Fatalf("expected credentials %v of %s, got %s/%s, %v", expected, host, username, password, err)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kaito-project/grit/cmd/grit-agent/app/options"
+)
+
+// Storage is used by grit agent for transferring checkpointed data between the host and the storage which is shared
+// across nodes. the storage dir and path are backend specific, like a directory in the mounted volume for pvc, the
+// common prefix of object keys for object storage or the repository of artifact for registry.
+type Storage interface {
+	// Upload transfers all files in the local dir into the storage dir, and returns the size in bytes of data in the storage.
+	Upload(ctx context.Context, localDir, storageDir string) (int64, error)
+	// Download transfers all files in the storage dir into the local dir.
+	Download(ctx context.Context, storageDir, localDir string) error
+	// WriteFile writes data from reader into a single file of the storage.
+	WriteFile(ctx context.Context, storagePath string, r io.Reader) error
+	// ReadFile opens a single file of the storage for reading.
+	ReadFile(ctx context.Context, storagePath string) (io.ReadCloser, error)
+	// Exists checks whether a single file exists in the storage.
+	Exists(ctx context.Context, storagePath string) (bool, error)
+	// Remove removes the storage dir and all files in it.
+	Remove(ctx context.Context, storageDir string) error
+}
+
+// NewStorage returns the storage specified by grit agent options. checkpointed data is packed into a single stream when
+// compression or encryption is enabled, and registry storage compresses and encrypts each layer of checkpoint artifact instead.
+func NewStorage(opts *options.StorageOptions) (Storage, error) {
+	var store Storage
+	var err error
+	switch opts.StorageType {
+	case "", options.StorageTypePVC:
+		store = NewVolumeStorage()
+	case options.StorageTypeS3:
+		store, err = NewObjectStorage(opts)
+	case options.StorageTypeRegistry:
+		return NewRegistryStorage(opts)
+	default:
+		return nil, fmt.Errorf("unknown storage type %s", opts.StorageType)
+	}
+	if err != nil || (opts.CompressionLevel == 0 && len(opts.EncryptionKeyDir) == 0) {
+		return store, err
+	}
+	return NewPackedStorage(store, opts), nil
+}
@@ -0,0 +1,56 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/kaito-project/grit/pkg/gritagent/copy"
+)
+
+// VolumeStorage stores checkpointed data in the storage volume(like pvc) which is mounted into agent container.
+type VolumeStorage struct{}
+
+func NewVolumeStorage() *VolumeStorage {
+	return &VolumeStorage{}
+}
+
+func (s *VolumeStorage) Upload(ctx context.Context, localDir, storageDir string) (int64, error) {
+	if err := copy.TransferData(ctx, localDir, storageDir); err != nil {
+		return 0, err
+	}
+	return copy.DirSize(storageDir)
+}
+
+func (s *VolumeStorage) Download(ctx context.Context, storageDir, localDir string) error {
+	return copy.TransferData(ctx, storageDir, localDir)
+}
+
+func (s *VolumeStorage) WriteFile(_ context.Context, storagePath string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(storagePath), os.ModePerm); err != nil {
+		return err
+	}
+	return writeFile(storagePath, r, 0644)
+}
+
+func (s *VolumeStorage) ReadFile(_ context.Context, storagePath string) (io.ReadCloser, error) {
+	return os.Open(storagePath)
+}
+
+func (s *VolumeStorage) Exists(_ context.Context, storagePath string) (bool, error) {
+	if _, err := os.Stat(storagePath); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *VolumeStorage) Remove(_ context.Context, storageDir string) error {
+	return os.RemoveAll(storageDir)
+}
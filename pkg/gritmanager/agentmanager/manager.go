@@ -8,19 +8,27 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/samber/lo"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	corev1listers "k8s.io/client-go/listers/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+	"github.com/kaito-project/grit/pkg/metadata"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
 const (
@@ -28,19 +36,39 @@ const (
 	HostPathKey            = "host-path"
 	GritAgentYamlKey       = "grit-agent-template.yaml"
 	PvcDirInContainer      = "/mnt/pvc-data/"
+	// RegistryConfigDirInContainer is the directory where docker config of registry credentials is mounted.
+	RegistryConfigDirInContainer = "/etc/grit-agent/registry/"
+	// EncryptionKeyDirInContainer is the directory where secret of encryption keys is mounted.
+	EncryptionKeyDirInContainer = "/etc/grit-agent/encryption/"
+	// EmergencyPriorityClassNameKey is the priority class of grit agent for emergency checkpoints, so grit agent is
+	// admitted by kubelet before other pods when node resources are insufficient.
+	EmergencyPriorityClassNameKey = "emergency-priority-class-name"
+	// DefaultBackoffLimit is the number of retries of grit agent job when it's not specified by Checkpoint or Restore.
+	DefaultBackoffLimit = 3
+	// InspectDirInContainer is the directory where checkpointed data is downloaded for inspection.
+	InspectDirInContainer = "/var/lib/grit-inspect/"
+	// InspectJobTTLSeconds is the lifetime of inspection job after it's finished, in case it's not removed by the client.
+	InspectJobTTLSeconds = 3600
+	// CheckpointTerminationGracePeriodSeconds is the minimum termination grace period of checkpoint agent, a running
+	// criu dump is not interrupted when checkpoint agent is stopped, and side effects on the pod are rolled back after
+	// that, so both of them should be finished before checkpoint agent is killed.
+	CheckpointTerminationGracePeriodSeconds = 300
 )
 
 type AgentManager struct {
 	namespace string
 	lister    corev1listers.ConfigMapLister
+	// tracingEndpoint is the OTLP endpoint which grit agent exports spans to, tracing is disabled when it's empty.
+	tracingEndpoint string
 }
 
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=list;watch;get
 
-func NewAgentManager(ns string, lister corev1listers.ConfigMapLister) *AgentManager {
+func NewAgentManager(ns string, lister corev1listers.ConfigMapLister, tracingEndpoint string) *AgentManager {
 	return &AgentManager{
-		namespace: ns,
-		lister:    lister,
+		namespace:       ns,
+		lister:          lister,
+		tracingEndpoint: tracingEndpoint,
 	}
 }
 
@@ -52,45 +80,29 @@ func (m *AgentManager) GetHostPath() string {
 	return strings.TrimSpace(cm.Data[HostPathKey])
 }
 
-func (m *AgentManager) GenerateGritAgentJob(ctx context.Context, ckpt *v1alpha1.Checkpoint, restore *v1alpha1.Restore) (*batchv1.Job, error) {
+func (m *AgentManager) getEmergencyPriorityClassName() string {
 	cm, err := m.lister.ConfigMaps(m.namespace).Get(GritAgentConfigMapName)
 	if err != nil {
-		return nil, err
-	}
-
-	if cm.Data == nil || len(strings.TrimSpace(cm.Data[HostPathKey])) == 0 || len(cm.Data[GritAgentYamlKey]) == 0 {
-		return nil, errors.New("There is no host-path or grit-agent-template.yaml in grit-agent-config")
-	}
-
-	girtAgentJobTemplate := cm.Data[GritAgentYamlKey]
-	templateCtx := map[string]string{
-		"namespace": ckpt.Namespace,
-		"jobName":   util.GritAgentJobName(ckpt, nil),
-		"nodeName":  ckpt.Status.NodeName,
+		return ""
 	}
+	return strings.TrimSpace(cm.Data[EmergencyPriorityClassNameKey])
+}
 
+func (m *AgentManager) GenerateGritAgentJob(ctx context.Context, ckpt *v1alpha1.Checkpoint, restore *v1alpha1.Restore) (*batchv1.Job, error) {
+	jobName := util.GritAgentJobName(ckpt, nil)
+	nodeName := ckpt.Status.NodeName
 	if restore != nil {
-		templateCtx["jobName"] = util.GritAgentJobName(nil, restore)
-		templateCtx["nodeName"] = restore.Status.NodeName
+		jobName = util.GritAgentJobName(nil, restore)
+		nodeName = restore.Status.NodeName
 	}
 
-	gritAgentJob, err := convertToGritAgentJob(girtAgentJobTemplate, templateCtx)
+	gritAgentJob, hostPathRoot, err := m.renderGritAgentJob(ctx, ckpt.Namespace, jobName, nodeName)
 	if err != nil {
 		return nil, err
-	} else if len(gritAgentJob.Spec.Template.Spec.Containers) != 1 {
-		return nil, errors.New("There should be only one container in grit-agent job")
 	}
-	log.FromContext(ctx).Info("grit manager job template", "object", *gritAgentJob)
 
 	// preare volumes and volume mount for job
-	pvcStorage := corev1.Volume{
-		Name: "pvc-data",
-		VolumeSource: corev1.VolumeSource{
-			PersistentVolumeClaim: ckpt.Spec.VolumeClaim,
-		},
-	}
-
-	hostPath := filepath.Join(strings.TrimSpace(cm.Data[HostPathKey]), ckpt.Namespace, ckpt.Name)
+	hostPath := filepath.Join(hostPathRoot, ckpt.Namespace, ckpt.Name)
 	hostStorage := corev1.Volume{
 		Name: "host-data",
 		VolumeSource: corev1.VolumeSource{
@@ -100,21 +112,35 @@ func (m *AgentManager) GenerateGritAgentJob(ctx context.Context, ckpt *v1alpha1.
 			},
 		},
 	}
-	gritAgentJob.Spec.Template.Spec.Volumes = append(gritAgentJob.Spec.Template.Spec.Volumes, pvcStorage, hostStorage)
+	gritAgentJob.Spec.Template.Spec.Volumes = append(gritAgentJob.Spec.Template.Spec.Volumes, hostStorage)
 
-	pvcDataPath := filepath.Join(PvcDirInContainer, ckpt.Namespace, ckpt.Name)
 	volumeMounts := []corev1.VolumeMount{
 		{
 			Name:      "host-data",
 			MountPath: hostPath,
 		},
-		{
-			Name:      "pvc-data",
-			MountPath: PvcDirInContainer,
-		},
+	}
+	// checkpointed data of parent checkpoints is needed for incremental checkpoint, mount them at the same path as the host.
+	for i, parent := range ckpt.Status.ParentCheckpoints {
+		volumeName := fmt.Sprintf("host-data-parent-%d", i)
+		parentHostPath := filepath.Join(hostPathRoot, ckpt.Namespace, parent)
+		gritAgentJob.Spec.Template.Spec.Volumes = append(gritAgentJob.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: parentHostPath,
+					Type: lo.ToPtr(corev1.HostPathDirectoryOrCreate),
+				},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      volumeName,
+			MountPath: parentHostPath,
+		})
 	}
 	c := &gritAgentJob.Spec.Template.Spec.Containers[0]
 	c.VolumeMounts = append(c.VolumeMounts, volumeMounts...)
+	storageDataPath := applyStorage(ckpt, &gritAgentJob.Spec.Template.Spec)
 
 	action := "checkpoint"
 	if restore != nil {
@@ -124,15 +150,88 @@ func (m *AgentManager) GenerateGritAgentJob(ctx context.Context, ckpt *v1alpha1.
 	args := map[string]string{
 		"action":         action,
 		"src-dir":        hostPath,
-		"dst-dir":        pvcDataPath,
+		"dst-dir":        storageDataPath,
 		"host-work-path": hostPath,
 	}
 
 	if restore != nil {
-		args["src-dir"] = pvcDataPath
+		args["src-dir"] = storageDataPath
 		args["dst-dir"] = hostPath
 	}
 
+	// only specified containers are checkpointed, restore agent transfers whatever is checkpointed.
+	if len(ckpt.Spec.Containers) != 0 && restore == nil {
+		args["containers"] = strings.Join(ckpt.Spec.Containers, ",")
+	}
+
+	if ckpt.Spec.Mode == v1alpha1.CheckpointModeSnapshot && restore == nil {
+		args["leave-running"] = "true"
+	}
+
+	// member of checkpoint group should wait for all members frozen before dumping.
+	if members, ok := ckpt.Annotations[v1alpha1.CheckpointGroupMembersAnnotation]; ok && restore == nil {
+		args["group-members"] = members
+		if timeout, ok := ckpt.Annotations[v1alpha1.CheckpointGroupFreezeTimeoutAnnotation]; ok {
+			args["group-freeze-timeout"] = timeout
+		}
+	}
+
+	// transient failures are retried by job controller with exponential backoff, and permanent failures reported by
+	// the exit code of grit agent fail the job at once.
+	backoffLimit, activeDeadlineSeconds, deadlineObj := ckpt.Spec.BackoffLimit, ckpt.Spec.ActiveDeadlineSeconds, metav1.Object(ckpt)
+	if restore != nil {
+		backoffLimit, activeDeadlineSeconds, deadlineObj = restore.Spec.BackoffLimit, restore.Spec.ActiveDeadlineSeconds, restore
+	}
+	gritAgentJob.Spec.BackoffLimit = lo.ToPtr(lo.FromPtrOr(backoffLimit, DefaultBackoffLimit))
+	gritAgentJob.Spec.PodFailurePolicy = permanentFailurePolicy(c.Name)
+	if restore == nil && lo.FromPtr(gritAgentJob.Spec.Template.Spec.TerminationGracePeriodSeconds) < CheckpointTerminationGracePeriodSeconds {
+		gritAgentJob.Spec.Template.Spec.TerminationGracePeriodSeconds = lo.ToPtr[int64](CheckpointTerminationGracePeriodSeconds)
+	}
+
+	// grit agent job is terminated if it's still running after the deadline, and checkpoint agent stops at the deadline
+	// by itself. emergency checkpoint should be completed before the node is preempted.
+	var deadlines []time.Time
+	if t, ok := util.ActiveDeadline(deadlineObj, activeDeadlineSeconds); ok {
+		deadlines = append(deadlines, t)
+	}
+	if deadline, ok := ckpt.Annotations[v1alpha1.CheckpointDeadlineAnnotation]; ok && restore == nil {
+		if t, err := time.Parse(time.RFC3339, deadline); err == nil {
+			deadlines = append(deadlines, t)
+		}
+	}
+	if len(deadlines) != 0 {
+		deadline := slices.MinFunc(deadlines, func(a, b time.Time) int { return a.Compare(b) })
+		if restore == nil {
+			args["deadline"] = deadline.UTC().Format(time.RFC3339)
+		}
+		gritAgentJob.Spec.ActiveDeadlineSeconds = lo.ToPtr(max(int64(math.Ceil(time.Until(deadline).Seconds())), 1))
+	}
+	if _, ok := ckpt.Labels[v1alpha1.EmergencyCheckpointLabel]; ok && restore == nil {
+		if priorityClassName := m.getEmergencyPriorityClassName(); len(priorityClassName) != 0 {
+			gritAgentJob.Spec.Template.Spec.PriorityClassName = priorityClassName
+		}
+	}
+
+	if len(ckpt.Status.ParentCheckpoints) != 0 {
+		args["parent-checkpoints"] = strings.Join(ckpt.Status.ParentCheckpoints, ",")
+	}
+
+	if compression := ckpt.Spec.Compression; compression != nil {
+		args["compression-level"] = strconv.Itoa(int(compression.Level))
+		if compression.Concurrency != nil {
+			args["compression-concurrency"] = strconv.Itoa(int(*compression.Concurrency))
+		}
+	}
+
+	// all keys in the secret are mounted, because restore agent finds the key by key id recorded in encrypted data.
+	if encryption := ckpt.Spec.Encryption; encryption != nil {
+		mountEncryptionKeys(&gritAgentJob.Spec.Template.Spec, encryption.SecretName)
+		args["encryption-key-dir"] = EncryptionKeyDirInContainer
+		if restore == nil {
+			args["encryption-key-id"] = encryption.KeyID
+		}
+	}
+
 	for k, v := range args {
 		c.Args = append(c.Args, fmt.Sprintf("--%s=%s", k, v))
 	}
@@ -142,9 +241,329 @@ func (m *AgentManager) GenerateGritAgentJob(ctx context.Context, ckpt *v1alpha1.
 		corev1.EnvVar{Name: "TARGET_NAME", Value: ckpt.Spec.PodName},
 		corev1.EnvVar{Name: "TARGET_UID", Value: ckpt.Status.PodUID},
 	)
+
+	// spans of grit agent are added into the trace of checkpoint or restore.
+	traceParent := ckpt.Annotations[v1alpha1.TraceParentAnnotation]
+	if restore != nil {
+		traceParent = restore.Annotations[v1alpha1.TraceParentAnnotation]
+	}
+	if len(m.tracingEndpoint) != 0 && len(traceParent) != 0 {
+		c.Env = append(c.Env,
+			corev1.EnvVar{Name: tracing.EndpointEnv, Value: m.tracingEndpoint},
+			corev1.EnvVar{Name: tracing.TraceParentEnv, Value: traceParent},
+		)
+	}
+	return gritAgentJob, nil
+}
+
+// GenerateGritAgentCleanupJob generates a grit agent job for removing checkpointed data of checkpoint. host path data of
+// checkpoint on the specified node is removed, and if nodeName is empty, data in the storage is removed by the job on any
+// node instead, because the storage is shared across nodes.
+func (m *AgentManager) GenerateGritAgentCleanupJob(ctx context.Context, ckpt *v1alpha1.Checkpoint, jobName, nodeName string) (*batchv1.Job, error) {
+	gritAgentJob, hostPathRoot, err := m.renderGritAgentJob(ctx, ckpt.Namespace, jobName, nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if gritAgentJob.Labels == nil {
+		gritAgentJob.Labels = make(map[string]string)
+	}
+	gritAgentJob.Labels[v1alpha1.CheckpointCleanupLabel] = ckpt.Name
+
+	c := &gritAgentJob.Spec.Template.Spec.Containers[0]
+	c.Args = append(c.Args, "--action=cleanup")
+	if len(nodeName) == 0 {
+		if !HasStorage(ckpt) {
+			return nil, fmt.Errorf("checkpoint %s has no storage for cleanup", ckpt.Name)
+		}
+		c.Args = append(c.Args, fmt.Sprintf("--dst-dir=%s", applyStorage(ckpt, &gritAgentJob.Spec.Template.Spec)))
+		return gritAgentJob, nil
+	}
+
+	// mount the parent directory of checkpointed data, because the mount point itself can not be removed in container.
+	hostNamespacePath := filepath.Join(hostPathRoot, ckpt.Namespace)
+	gritAgentJob.Spec.Template.Spec.Volumes = append(gritAgentJob.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "host-data",
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: hostNamespacePath,
+				Type: lo.ToPtr(corev1.HostPathDirectoryOrCreate),
+			},
+		},
+	})
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      "host-data",
+		MountPath: hostNamespacePath,
+	})
+	c.Args = append(c.Args, fmt.Sprintf("--src-dir=%s", filepath.Join(hostNamespacePath, ckpt.Name)))
+
+	return gritAgentJob, nil
+}
+
+// HasStorage returns true if checkpointed data of checkpoint is uploaded into a storage volume, object storage or registry,
+// otherwise the data is only kept in host path of nodes.
+func HasStorage(ckpt *v1alpha1.Checkpoint) bool {
+	return ckpt.Spec.VolumeClaim != nil || ckpt.Spec.ObjectStorage != nil || ckpt.Spec.Registry != nil
+}
+
+// GenerateGritAgentInspectJob generates a grit agent job for inspecting checkpointed data of checkpoint in the storage,
+// the report is printed into logs of the job. if compare is specified, changes from compare to checkpoint are printed
+// instead, and both checkpoints should be stored in the same storage and encrypted by keys in the same secret.
+func (m *AgentManager) GenerateGritAgentInspectJob(ctx context.Context, ckpt, compare *v1alpha1.Checkpoint, jobName string) (*batchv1.Job, error) {
+	if !HasStorage(ckpt) {
+		return nil, fmt.Errorf("checkpoint %s has no storage for inspection", ckpt.Name)
+	}
+	if compare != nil && !sameStorage(ckpt, compare) {
+		return nil, fmt.Errorf("checkpoint %s and %s should be stored in the same storage and encrypted by the same secret", ckpt.Name, compare.Name)
+	}
+
+	// inspection job is scheduled onto any node, because checkpointed data is downloaded from the storage.
+	gritAgentJob, _, err := m.renderGritAgentJob(ctx, ckpt.Namespace, jobName, "")
+	if err != nil {
+		return nil, err
+	}
+	gritAgentJob.Spec.BackoffLimit = lo.ToPtr[int32](0)
+	gritAgentJob.Spec.TTLSecondsAfterFinished = lo.ToPtr[int32](InspectJobTTLSeconds)
+
+	podSpec := &gritAgentJob.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "inspect-data",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+	c := &podSpec.Containers[0]
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      "inspect-data",
+		MountPath: InspectDirInContainer,
+	})
+	c.Args = append(c.Args,
+		"--action=inspect",
+		fmt.Sprintf("--src-dir=%s", applyStorage(ckpt, podSpec)),
+		fmt.Sprintf("--dst-dir=%s", filepath.Join(InspectDirInContainer, ckpt.Name)),
+	)
+	if compare != nil {
+		c.Args = append(c.Args, fmt.Sprintf("--compare-src-dir=%s", storageDir(compare)))
+	}
+
+	// packed stream of checkpointed data is only looked up when compression or encryption is enabled, and the level
+	// is not used for downloading.
+	compression, encryption := ckpt.Spec.Compression, ckpt.Spec.Encryption
+	if compare != nil && compression == nil {
+		compression = compare.Spec.Compression
+	}
+	if compare != nil && encryption == nil {
+		encryption = compare.Spec.Encryption
+	}
+	if compression != nil {
+		c.Args = append(c.Args, fmt.Sprintf("--compression-level=%d", compression.Level))
+	}
+	if encryption != nil {
+		mountEncryptionKeys(podSpec, encryption.SecretName)
+		c.Args = append(c.Args, fmt.Sprintf("--encryption-key-dir=%s", EncryptionKeyDirInContainer))
+	}
+	return gritAgentJob, nil
+}
+
+// GenerateGritAgentRestoreLogJob generates a grit agent job which prints criu restore logs of restore, they're left in
+// checkpointed data on the restored node by the shim.
+func (m *AgentManager) GenerateGritAgentRestoreLogJob(ctx context.Context, restore *v1alpha1.Restore, jobName string) (*batchv1.Job, error) {
+	if len(restore.Status.NodeName) == 0 {
+		return nil, fmt.Errorf("restore %s has not been scheduled onto a node", restore.Name)
+	}
+
+	gritAgentJob, hostPathRoot, err := m.renderGritAgentJob(ctx, restore.Namespace, jobName, restore.Status.NodeName)
+	if err != nil {
+		return nil, err
+	}
+	gritAgentJob.Spec.BackoffLimit = lo.ToPtr[int32](0)
+	gritAgentJob.Spec.TTLSecondsAfterFinished = lo.ToPtr[int32](InspectJobTTLSeconds)
+
+	hostPath := filepath.Join(hostPathRoot, restore.Namespace, restore.Spec.CheckpointName)
+	podSpec := &gritAgentJob.Spec.Template.Spec
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "host-data",
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: hostPath,
+				Type: lo.ToPtr(corev1.HostPathDirectory),
+			},
+		},
+	})
+	c := &podSpec.Containers[0]
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      "host-data",
+		MountPath: hostPath,
+		ReadOnly:  true,
+	})
+	c.Args = append(c.Args,
+		"--action=restore-log",
+		fmt.Sprintf("--src-dir=%s", hostPath),
+	)
 	return gritAgentJob, nil
 }
 
+// sameStorage checks whether checkpointed data of two checkpoints can be accessed by one grit agent.
+func sameStorage(a, b *v1alpha1.Checkpoint) bool {
+	if !equality.Semantic.DeepEqual(a.Spec.VolumeClaim, b.Spec.VolumeClaim) ||
+		!equality.Semantic.DeepEqual(a.Spec.ObjectStorage, b.Spec.ObjectStorage) ||
+		!equality.Semantic.DeepEqual(a.Spec.Registry, b.Spec.Registry) {
+		return false
+	}
+	if a.Spec.Encryption != nil && b.Spec.Encryption != nil {
+		return a.Spec.Encryption.SecretName == b.Spec.Encryption.SecretName
+	}
+	return true
+}
+
+// applyStorage prepares grit agent container for accessing storage of checkpoint, and returns the directory of checkpointed
+// data in the storage. storage volume is mounted for pvc, and object storage or registry is accessed by grit agent directly.
+func applyStorage(ckpt *v1alpha1.Checkpoint, podSpec *corev1.PodSpec) string {
+	c := &podSpec.Containers[0]
+	if objectStorage := ckpt.Spec.ObjectStorage; objectStorage != nil {
+		c.Args = append(c.Args,
+			"--storage-type=s3",
+			fmt.Sprintf("--s3-endpoint=%s", objectStorage.Endpoint),
+			fmt.Sprintf("--s3-bucket=%s", objectStorage.Bucket),
+			fmt.Sprintf("--s3-region=%s", objectStorage.Region),
+			fmt.Sprintf("--s3-insecure=%t", objectStorage.Insecure),
+		)
+		if len(objectStorage.CredentialsSecretName) != 0 {
+			c.Env = append(c.Env,
+				secretEnvVar("AWS_ACCESS_KEY_ID", objectStorage.CredentialsSecretName, v1alpha1.ObjectStorageAccessKeyIDKey),
+				secretEnvVar("AWS_SECRET_ACCESS_KEY", objectStorage.CredentialsSecretName, v1alpha1.ObjectStorageSecretAccessKeyKey),
+			)
+		}
+		return storageDir(ckpt)
+	}
+
+	if registry := ckpt.Spec.Registry; registry != nil {
+		c.Args = append(c.Args,
+			"--storage-type=registry",
+			fmt.Sprintf("--registry-insecure=%t", registry.Insecure),
+		)
+		if len(registry.CredentialsSecretName) != 0 {
+			podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+				Name: "registry-credentials",
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: registry.CredentialsSecretName,
+						Items:      []corev1.KeyToPath{{Key: corev1.DockerConfigJsonKey, Path: "config.json"}},
+					},
+				},
+			})
+			c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+				Name:      "registry-credentials",
+				MountPath: RegistryConfigDirInContainer,
+				ReadOnly:  true,
+			})
+			c.Args = append(c.Args, fmt.Sprintf("--registry-config=%s", filepath.Join(RegistryConfigDirInContainer, "config.json")))
+		}
+		return storageDir(ckpt)
+	}
+
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "pvc-data",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: ckpt.Spec.VolumeClaim,
+		},
+	})
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      "pvc-data",
+		MountPath: PvcDirInContainer,
+	})
+	return storageDir(ckpt)
+}
+
+// storageDir returns the directory of checkpointed data in the storage of checkpoint.
+func storageDir(ckpt *v1alpha1.Checkpoint) string {
+	switch {
+	case ckpt.Spec.ObjectStorage != nil:
+		return util.ObjectStorageKey(ckpt.Spec.ObjectStorage, ckpt.Namespace, ckpt.Name)
+	case ckpt.Spec.Registry != nil:
+		return util.RegistryRepository(ckpt.Spec.Registry, ckpt.Namespace, ckpt.Name)
+	}
+	return filepath.Join(PvcDirInContainer, ckpt.Namespace, ckpt.Name)
+}
+
+// mountEncryptionKeys mounts all keys in the secret into grit agent container.
+func mountEncryptionKeys(podSpec *corev1.PodSpec, secretName string) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: "encryption-keys",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secretName,
+			},
+		},
+	})
+	c := &podSpec.Containers[0]
+	c.VolumeMounts = append(c.VolumeMounts, corev1.VolumeMount{
+		Name:      "encryption-keys",
+		MountPath: EncryptionKeyDirInContainer,
+		ReadOnly:  true,
+	})
+}
+
+// permanentFailurePolicy fails grit agent job at once when grit agent exits with the exit code of permanent failure.
+func permanentFailurePolicy(containerName string) *batchv1.PodFailurePolicy {
+	return &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{
+			{
+				Action: batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+					ContainerName: lo.ToPtr(containerName),
+					Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+					Values:        []int32{metadata.PermanentFailureExitCode},
+				},
+			},
+		},
+	}
+}
+
+func secretEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
+// renderGritAgentJob renders grit agent job from the template in grit-agent-config, and returns the job with host path in config.
+func (m *AgentManager) renderGritAgentJob(ctx context.Context, namespace, jobName, nodeName string) (*batchv1.Job, string, error) {
+	cm, err := m.lister.ConfigMaps(m.namespace).Get(GritAgentConfigMapName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if cm.Data == nil || len(strings.TrimSpace(cm.Data[HostPathKey])) == 0 || len(cm.Data[GritAgentYamlKey]) == 0 {
+		return nil, "", errors.New("There is no host-path or grit-agent-template.yaml in grit-agent-config")
+	}
+
+	girtAgentJobTemplate := cm.Data[GritAgentYamlKey]
+	templateCtx := map[string]string{
+		"namespace": namespace,
+		"jobName":   jobName,
+		"nodeName":  nodeName,
+	}
+
+	gritAgentJob, err := convertToGritAgentJob(girtAgentJobTemplate, templateCtx)
+	if err != nil {
+		return nil, "", err
+	} else if len(gritAgentJob.Spec.Template.Spec.Containers) != 1 {
+		return nil, "", errors.New("There should be only one container in grit-agent job")
+	}
+	log.FromContext(ctx).Info("grit manager job template", "object", *gritAgentJob)
+
+	if gritAgentJob.Spec.BackoffLimit == nil {
+		gritAgentJob.Spec.BackoffLimit = lo.ToPtr[int32](DefaultBackoffLimit)
+	}
+
+	return gritAgentJob, strings.TrimSpace(cm.Data[HostPathKey]), nil
+}
+
 func convertToGritAgentJob(templateStr string, context map[string]string) (*batchv1.Job, error) {
 	resourceTemplate, err := template.New("grit").Option("missingkey=zero").Parse(templateStr)
 	if err != nil {
@@ -6,20 +6,28 @@ package checkpoint
 import (
 	"context"
 	"fmt"
+	"path"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/samber/lo"
 	"golang.org/x/time/rate"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -27,16 +35,25 @@ import (
 	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
 	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+	"github.com/kaito-project/grit/pkg/gritmanager/metrics"
+	"github.com/kaito-project/grit/pkg/gritmanager/podmatch"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+const (
+	// gritAgentTerminationTimeout is how long grit agent pod is waited for after its termination grace period when
+	// checkpoint is cancelled, and then it's given up.
+	gritAgentTerminationTimeout = time.Minute
+	// cancellingRequeueInterval is the interval of checking grit agent of cancelled checkpoint until it's terminated.
+	cancellingRequeueInterval = 10 * time.Second
 )
 
 var (
 	checkpointConditionOrder = map[string]int{
-		string(v1alpha1.CheckpointCreated):       1,
-		string(v1alpha1.CheckpointPending):       2,
-		string(v1alpha1.Checkpointing):           3,
-		string(v1alpha1.Checkpointed):            4,
-		string(v1alpha1.AutoMigrationSubmitting): 5,
-		string(v1alpha1.AutoMigrationSubmitted):  6,
+		string(v1alpha1.CheckpointCreated): 1,
+		string(v1alpha1.CheckpointPending): 2,
+		string(v1alpha1.Checkpointing):     3,
+		string(v1alpha1.Checkpointed):      4,
 	}
 )
 
@@ -46,24 +63,24 @@ type Controller struct {
 	client.Client
 	clock         clock.Clock
 	agentManager  *agentmanager.AgentManager
+	recorder      record.EventRecorder
 	statesMachine map[v1alpha1.CheckpointPhase]CheckpointStateHandler
 }
 
-func NewController(clk clock.Clock, kubeClient client.Client, agentManager *agentmanager.AgentManager) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, agentManager *agentmanager.AgentManager, recorder record.EventRecorder) *Controller {
 	c := &Controller{
 		clock:        clk,
 		Client:       kubeClient,
 		agentManager: agentManager,
+		recorder:     recorder,
 	}
 
-	// v1alpha1.CheckpointFailed, v1alpha1.AutoMigrationSubmitted,
-	// these two states, girt-manager don't need to do anything.
+	// v1alpha1.CheckpointFailed state, girt-manager don't need to do anything.
 	c.statesMachine = map[v1alpha1.CheckpointPhase]CheckpointStateHandler{
-		v1alpha1.CheckpointCreated:       c.createdHandler,
-		v1alpha1.CheckpointPending:       c.pendingHandler,
-		v1alpha1.Checkpointing:           c.checkpointingHandler,
-		v1alpha1.Checkpointed:            c.checkpointedHandler,
-		v1alpha1.AutoMigrationSubmitting: c.submittingHandler,
+		v1alpha1.CheckpointCreated: c.createdHandler,
+		v1alpha1.CheckpointPending: c.pendingHandler,
+		v1alpha1.Checkpointing:     c.checkpointingHandler,
+		v1alpha1.Checkpointed:      c.checkpointedHandler,
 	}
 
 	return c
@@ -72,6 +89,25 @@ func NewController(clk clock.Clock, kubeClient client.Client, agentManager *agen
 func (c *Controller) Reconcile(ctx context.Context, ckpt *v1alpha1.Checkpoint) (reconcile.Result, error) {
 	ctx = util.WithControllerName(ctx, "checkpoint.lifecycle")
 
+	if !ckpt.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, c.finalize(ctx, ckpt)
+	}
+
+	// make sure checkpointed data will be removed when checkpoint is deleted, and the trace of checkpoint is started
+	// at the same time.
+	if !controllerutil.ContainsFinalizer(ckpt, v1alpha1.CheckpointDataFinalizer) {
+		updatedCkpt := ckpt.DeepCopy()
+		controllerutil.AddFinalizer(updatedCkpt, v1alpha1.CheckpointDataFinalizer)
+		util.StartTrace(ctx, updatedCkpt, "checkpoint", c.clock.Now())
+		return reconcile.Result{}, c.Patch(ctx, updatedCkpt, client.MergeFrom(ckpt))
+	}
+
+	// checkpoint which fails on exceeding its active deadline is not reconciled anymore, otherwise the handler of the
+	// last phase would overwrite the failure or create grit agent job again.
+	if util.DeadlineExceeded(ckpt.Status.Conditions, string(v1alpha1.CheckpointFailed)) {
+		return reconcile.Result{}, nil
+	}
+
 	updatedCkpt := ckpt.DeepCopy()
 	phase := v1alpha1.CheckpointPhase(util.ResolveLastPhaseFromConditions(updatedCkpt.Status.Conditions, checkpointConditionOrder, string(v1alpha1.CheckpointCreated)))
 	log.FromContext(ctx).Info("the last pahse of checkpoint", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "phase", phase)
@@ -80,19 +116,47 @@ func (c *Controller) Reconcile(ctx context.Context, ckpt *v1alpha1.Checkpoint) (
 		return reconcile.Result{}, nil
 	}
 
+	// checkpoint which is still in progress is aborted when it's cancelled, or fails when active deadline is exceeded,
+	// otherwise it's reconciled again at the deadline.
+	var result reconcile.Result
+	cancelling := ckpt.Spec.Cancel && phase != v1alpha1.Checkpointed && ckpt.Status.Phase != v1alpha1.CheckpointFailed
+	if cancelling {
+		stateHandler = c.cancelHandler
+	} else if deadline, ok := util.ActiveDeadline(ckpt, ckpt.Spec.ActiveDeadlineSeconds); ok && phase != v1alpha1.Checkpointed && ckpt.Status.Phase != v1alpha1.CheckpointFailed {
+		if remaining := deadline.Sub(c.clock.Now()); remaining > 0 {
+			result.RequeueAfter = remaining
+		} else {
+			stateHandler = c.deadlineExceededHandler
+		}
+	}
+
 	if err := stateHandler(ctx, updatedCkpt); err != nil {
 		return reconcile.Result{}, err
 	}
 
+	// grit agent pod maybe is never updated after it's deleted, like its node has gone, so cancelled checkpoint is
+	// checked periodically until grit agent is terminated.
+	if cancelling && updatedCkpt.Status.Phase != v1alpha1.CheckpointCancelled && updatedCkpt.Status.Phase != v1alpha1.Checkpointed && updatedCkpt.Status.Phase != v1alpha1.CheckpointFailed {
+		result.RequeueAfter = cancellingRequeueInterval
+	}
+
 	// if phase is not CheckpointFailed, we need to remove failed condition
 	if updatedCkpt.Status.Phase != v1alpha1.CheckpointFailed {
 		util.RemoveCondition(&updatedCkpt.Status.Conditions, string(v1alpha1.CheckpointFailed))
 	}
 
 	if !reflect.DeepEqual(ckpt, updatedCkpt) {
-		return reconcile.Result{}, c.Status().Update(ctx, updatedCkpt)
+		if err := c.Status().Update(ctx, updatedCkpt); err != nil {
+			return reconcile.Result{}, err
+		}
+		metrics.RecordCheckpointPhase(ckpt, updatedCkpt, c.clock.Now())
+		if updatedCkpt.Status.Phase != ckpt.Status.Phase {
+			failed := updatedCkpt.Status.Phase == v1alpha1.CheckpointFailed || updatedCkpt.Status.Phase == v1alpha1.CheckpointCancelled
+			util.RecordPhaseSpan(ctx, updatedCkpt, updatedCkpt.Status.Conditions, "checkpoint", string(ckpt.Status.Phase), string(updatedCkpt.Status.Phase), failed, c.clock.Now())
+			c.recordPhaseEvent(updatedCkpt)
+		}
 	}
-	return reconcile.Result{}, nil
+	return result, nil
 }
 
 // createdHandler is used for initializing pod spec hash for checkpoint resource, then upgraded state to CheckpointPending.
@@ -113,9 +177,33 @@ func (c *Controller) createdHandler(ctx context.Context, ckpt *v1alpha1.Checkpoi
 	}
 	log.FromContext(ctx).Info("pod metadata", "metadata", pod.ObjectMeta, "checkpoint", ckpt.Name)
 
+	// resolve parent checkpoint chain for incremental checkpoint
+	if len(ckpt.Spec.ParentCheckpointName) != 0 {
+		var parent v1alpha1.Checkpoint
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.ParentCheckpointName}, &parent); err != nil {
+			if apierrors.IsNotFound(err) {
+				ckpt.Status.Phase = v1alpha1.CheckpointFailed
+				util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), "ParentCheckpointNotExist", fmt.Sprintf("parent checkpoint(%s) doesn't exist", ckpt.Spec.ParentCheckpointName))
+				return nil
+			}
+			return err
+		}
+
+		if err := util.ValidateParentCheckpoint(ckpt, &parent, &pod); err != nil {
+			ckpt.Status.Phase = v1alpha1.CheckpointFailed
+			util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), "ParentCheckpointInvalid", err.Error())
+			return nil
+		}
+		ckpt.Status.ParentCheckpoints = append([]string{parent.Name}, parent.Status.ParentCheckpoints...)
+	}
+
 	ckpt.Status.NodeName = pod.Spec.NodeName
 	ckpt.Status.PodSpecHash = util.ComputeHash(&pod.Spec)
+	ckpt.Status.PodIdentity = podmatch.For(&pod).Identity(&pod)
 	ckpt.Status.PodUID = string(pod.UID)
+	if ownerRef := metav1.GetControllerOf(&pod); ownerRef != nil {
+		ckpt.Status.PodOwnerUID = string(ownerRef.UID)
+	}
 	ckpt.Status.Phase = v1alpha1.CheckpointPending
 	util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointPending), "InitializingCompleted", "pod spec hash has been configured")
 	return nil
@@ -153,16 +241,25 @@ func (c *Controller) checkpointingHandler(ctx context.Context, ckpt *v1alpha1.Ch
 	if err = c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: util.GritAgentJobName(ckpt, nil)}, &gritAgentJob); client.IgnoreNotFound(err) != nil {
 		return err
 	} else if err == nil {
-		isCompleted, isFailed = jobCompletedOrFailed(&gritAgentJob)
+		isCompleted, isFailed = util.JobCompletedOrFailed(&gritAgentJob)
 		if isCompleted {
-			var pvc corev1.PersistentVolumeClaim
-			if err = c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.VolumeClaim.ClaimName}, &pvc); err != nil {
+			if ckpt.Status.StorageLocation, err = c.resolveStorageLocation(ctx, ckpt); err != nil {
 				return err
 			}
 
-			ckpt.Status.DataPath = fmt.Sprintf("%s://%s/%s", pvc.Spec.VolumeName, ckpt.Namespace, ckpt.Name)
+			// data size and throughput are reported by grit agent, and they're only used for observability, so missing report is ignored.
+			if report, err := util.GritAgentReport(ctx, c.Client, &gritAgentJob); err != nil {
+				log.FromContext(ctx).Error(err, "failed to get report of grit agent", "job", gritAgentJob.Name)
+			} else if report != nil {
+				ckpt.Status.DataSize = &v1alpha1.DataSize{
+					Uncompressed: report.UncompressedSize,
+					Compressed:   report.CompressedSize,
+				}
+				metrics.RecordAgentReport(metrics.ActionCheckpoint, report)
+			}
+
 			ckpt.Status.Phase = v1alpha1.Checkpointed
-			util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.Checkpointed), "GritAgentJobCompleted", fmt.Sprintf("grit agent job(%s/%s) is completed", gritAgentJob.Namespace, gritAgentJob.Name))
+			util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.Checkpointed), "GritAgentJobCompleted", fmt.Sprintf("grit agent job(%s/%s) is completed, containers are dumped and checkpointed data is stored", gritAgentJob.Namespace, gritAgentJob.Name))
 			return nil
 		}
 	}
@@ -170,128 +267,279 @@ func (c *Controller) checkpointingHandler(ctx context.Context, ckpt *v1alpha1.Ch
 	// girt job is not found or failed
 	if err != nil || isFailed {
 		ckpt.Status.Phase = v1alpha1.CheckpointFailed
-		util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), "GritAgentJobFailed", fmt.Sprintf("failed to execute grit agent job(%s/%s) in checkpointing state", gritAgentJob.Namespace, gritAgentJob.Name))
+		report := c.failureReport(ctx, &gritAgentJob)
+		setRollbackCondition(c.clock, ckpt, report)
+		if util.JobFailedReason(&gritAgentJob) == batchv1.JobReasonDeadlineExceeded || (report != nil && report.DeadlineExceeded) {
+			util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), util.DeadlineExceededReason, fmt.Sprintf("grit agent job(%s/%s) is not completed before deadline", gritAgentJob.Namespace, gritAgentJob.Name))
+			return nil
+		}
+		if util.JobFailedReason(&gritAgentJob) == batchv1.JobReasonPodFailurePolicy {
+			util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), "GritAgentPermanentFailure", fmt.Sprintf("grit agent job(%s/%s) failed on a permanent error, it's not retried", gritAgentJob.Namespace, gritAgentJob.Name))
+			return nil
+		}
+		util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), "GritAgentJobFailed", fmt.Sprintf("failed to execute grit agent job(%s/%s) in checkpointing state after %d attempts", gritAgentJob.Namespace, gritAgentJob.Name, gritAgentJob.Status.Failed))
 	}
 	return nil
 }
 
-func jobCompletedOrFailed(job *batchv1.Job) (bool, bool) {
-	if job == nil {
-		return false, false
+// cancelHandler is used for aborting an in-progress checkpoint. grit agent job is removed, and grit agent rolls back the
+// pod and removes half-written data in its termination grace period. pods of grit agent are kept by a finalizer until
+// they're terminated and their report is read, so checkpoint tells whether the pod has been rolled back.
+func (c *Controller) cancelHandler(ctx context.Context, ckpt *v1alpha1.Checkpoint) error {
+	var gritAgentJob batchv1.Job
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: util.GritAgentJobName(ckpt, nil)}, &gritAgentJob); client.IgnoreNotFound(err) != nil {
+		return err
+	} else if apierrors.IsNotFound(err) {
+		if ckpt.Status.Phase != v1alpha1.CheckpointCancelled {
+			setCancelled(c.clock, ckpt, nil)
+		}
+		return nil
 	}
 
-	if job.Status.Succeeded > 0 {
-		return true, false
+	// it's too late to cancel checkpoint when grit agent job has completed.
+	if isCompleted, _ := util.JobCompletedOrFailed(&gritAgentJob); isCompleted {
+		return c.checkpointingHandler(ctx, ckpt)
 	}
 
-	if job.Status.Failed > 0 {
-		return false, true
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(gritAgentJob.Namespace), client.MatchingLabels{batchv1.JobNameLabel: gritAgentJob.Name}); err != nil {
+		return err
 	}
 
-	for _, cond := range job.Status.Conditions {
-		if cond.Type == batchv1.JobComplete && cond.Status == "True" {
-			return true, false
+	if gritAgentJob.DeletionTimestamp.IsZero() {
+		for i := range pods.Items {
+			if err := c.updateReportFinalizer(ctx, &pods.Items[i], true); err != nil {
+				return err
+			}
 		}
+		log.FromContext(ctx).Info("checkpoint is cancelled, remove grit agent job", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "job", gritAgentJob.Name)
+		deletePolicy := metav1.DeletePropagationForeground
+		if err := c.Delete(ctx, &gritAgentJob, &client.DeleteOptions{PropagationPolicy: &deletePolicy}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return nil
+	}
+
+	// the report has been recorded in checkpoint, so pods of grit agent are released.
+	if ckpt.Status.Phase == v1alpha1.CheckpointCancelled {
+		for i := range pods.Items {
+			if err := c.updateReportFinalizer(ctx, &pods.Items[i], false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
 
-		if cond.Type == batchv1.JobFailed && cond.Status == "True" {
-			return false, true
+	for i := range pods.Items {
+		if !c.gritAgentTerminated(&pods.Items[i]) {
+			log.FromContext(ctx).Info("wait for grit agent to be terminated", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "pod", pods.Items[i].Name)
+			return nil
 		}
 	}
-	return false, false
+	setCancelled(c.clock, ckpt, c.failureReport(ctx, &gritAgentJob))
+	return nil
 }
 
-// checkpointedHandler is used for garbage collecting grit agent pod. then pvc for cloud storage can be used for restoring.
-// if checkpoint.Spec.AutoMigration is true, upgrade phase to checkpoint Submitting.
-func (c *Controller) checkpointedHandler(ctx context.Context, ckpt *v1alpha1.Checkpoint) error {
+// updateReportFinalizer adds or removes the finalizer which keeps grit agent pod until its report is read.
+func (c *Controller) updateReportFinalizer(ctx context.Context, pod *corev1.Pod, add bool) error {
+	updatedPod := pod.DeepCopy()
+	if add {
+		controllerutil.AddFinalizer(updatedPod, v1alpha1.GritAgentReportFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(updatedPod, v1alpha1.GritAgentReportFinalizer)
+	}
+	if reflect.DeepEqual(pod.Finalizers, updatedPod.Finalizers) {
+		return nil
+	}
+	return client.IgnoreNotFound(c.Patch(ctx, updatedPod, client.MergeFrom(pod)))
+}
+
+// gritAgentTerminated returns whether grit agent has exited, or it's not terminated by kubelet long after its grace
+// period, like the node has gone, then its report will never come.
+func (c *Controller) gritAgentTerminated(pod *corev1.Pod) bool {
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return true
+	}
+	if len(pod.Status.ContainerStatuses) != 0 && lo.EveryBy(pod.Status.ContainerStatuses, func(status corev1.ContainerStatus) bool {
+		return status.State.Terminated != nil
+	}) {
+		return true
+	}
+	return pod.DeletionTimestamp != nil && c.clock.Since(pod.DeletionTimestamp.Time) > gritAgentTerminationTimeout
+}
+
+// setCancelled cancels checkpoint, and the pod is said to be rolled back only when grit agent has reported it.
+func setCancelled(clk clock.Clock, ckpt *v1alpha1.Checkpoint, report *metadata.AgentReport) {
+	message := "checkpoint is cancelled before it's checkpointed, grit agent doesn't report whether the pod has been rolled back"
+	switch {
+	case report == nil:
+	case report.RolledBack:
+		message = "checkpoint is cancelled before it's checkpointed, and the pod has been rolled back"
+	case len(report.RollbackError) != 0:
+		message = "checkpoint is cancelled before it's checkpointed, but the pod failed to be rolled back"
+	case len(report.StoppedContainers) != 0:
+		message = "checkpoint is cancelled before it's checkpointed, but the pod is partially rolled back"
+	default:
+		message = "checkpoint is cancelled before it's checkpointed, and no change has been made on the pod"
+	}
+	setRollbackCondition(clk, ckpt, report)
+	ckpt.Status.Phase = v1alpha1.CheckpointCancelled
+	util.UpdateCondition(clk, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointCancelled), "CheckpointCancelled", message)
+}
+
+// deadlineExceededHandler is used for failing checkpoint which is not completed within active deadline, and grit agent
+// job is removed, so a stuck criu dump or data transfer is stopped.
+func (c *Controller) deadlineExceededHandler(ctx context.Context, ckpt *v1alpha1.Checkpoint) error {
 	var gritAgentJob batchv1.Job
 	if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: util.GritAgentJobName(ckpt, nil)}, &gritAgentJob); client.IgnoreNotFound(err) != nil {
 		return err
-	} else if err == nil { // grit agent exist
-		if gritAgentJob.DeletionTimestamp.IsZero() { // skip deleting grit agent job
-			deletePolicy := metav1.DeletePropagationForeground
-			return c.Delete(ctx, &gritAgentJob, &client.DeleteOptions{PropagationPolicy: &deletePolicy})
+	} else if err == nil {
+		// grit agent job is completed just before the deadline, but it has not been observed.
+		if isCompleted, _ := util.JobCompletedOrFailed(&gritAgentJob); isCompleted {
+			return c.checkpointingHandler(ctx, ckpt)
 		}
-	} else { // grit agent job is deleted
-		if ckpt.Spec.AutoMigration {
-			ckpt.Status.Phase = v1alpha1.AutoMigrationSubmitting
-			util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.AutoMigrationSubmitting), "CheckpointedCompleted", "auto migration is true and start to submit migration")
+
+		if gritAgentJob.DeletionTimestamp.IsZero() {
+			deletePolicy := metav1.DeletePropagationForeground
+			if err := c.Delete(ctx, &gritAgentJob, &client.DeleteOptions{PropagationPolicy: &deletePolicy}); client.IgnoreNotFound(err) != nil {
+				return err
+			}
 		}
 	}
 
+	ckpt.Status.Phase = v1alpha1.CheckpointFailed
+	util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), util.DeadlineExceededReason, fmt.Sprintf("checkpoint is not completed within %d seconds", *ckpt.Spec.ActiveDeadlineSeconds))
 	return nil
 }
 
-// submittingHandler is used for submitting Restore resource and deleting checkpointed pod.
-func (c *Controller) submittingHandler(ctx context.Context, ckpt *v1alpha1.Checkpoint) error {
-	// get checkpoint pod
-	var checkpointPod corev1.Pod
-	if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.PodName}, &checkpointPod); err != nil {
-		if apierrors.IsNotFound(err) {
-			ckpt.Status.Phase = v1alpha1.CheckpointFailed
-			util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), "PodIsRemoved", fmt.Sprintf("checkpointed pod(%s) referenced by checkpoint resource(%s) has been removed", ckpt.Spec.PodName, ckpt.Name))
-			return nil
-		} else {
-			return err
-		}
+// recordPhaseEvent emits events on Checkpoint and the checkpointed pod when Checkpoint moves into a new phase, so the
+// history of checkpoint can be followed by kubectl describe.
+func (c *Controller) recordPhaseEvent(ckpt *v1alpha1.Checkpoint) {
+	failed := ckpt.Status.Phase == v1alpha1.CheckpointFailed || ckpt.Status.Phase == v1alpha1.CheckpointCancelled
+	eventType, reason, message := util.PhaseEvent(ckpt.Status.Conditions, string(ckpt.Status.Phase), failed)
+	pod := util.PodForEvent(ckpt.Namespace, ckpt.Spec.PodName, types.UID(ckpt.Status.PodUID))
+	c.recorder.Event(ckpt, eventType, reason, message)
+	c.recorder.Eventf(pod, eventType, reason, "checkpoint(%s): %s", ckpt.Name, message)
+
+	// the pod maybe is left frozen or locked on GPU when rollback fails, so it needs attention of users.
+	if cond := meta.FindStatusCondition(ckpt.Status.Conditions, v1alpha1.CheckpointRolledBackCondition); failed && cond != nil && cond.Status == metav1.ConditionFalse {
+		c.recorder.Event(ckpt, corev1.EventTypeWarning, cond.Reason, cond.Message)
+		c.recorder.Eventf(pod, corev1.EventTypeWarning, cond.Reason, "checkpoint(%s): %s", ckpt.Name, cond.Message)
 	}
+}
 
-	// resolve owner reference from checkpoint pod
-	var ownerRef *metav1.OwnerReference
-	for i := range checkpointPod.OwnerReferences {
-		if *checkpointPod.OwnerReferences[i].Controller {
-			ownerRef = &checkpointPod.OwnerReferences[i]
-			break
-		}
+// failureReport returns the report of failed grit agent job, like checkpoint is not completed before the deadline, and
+// whether the pod has been rolled back. nil is returned if grit agent doesn't report it.
+func (c *Controller) failureReport(ctx context.Context, job *batchv1.Job) *metadata.AgentReport {
+	if len(job.Name) == 0 {
+		return nil
 	}
-
-	if ownerRef == nil {
-		ckpt.Status.Phase = v1alpha1.CheckpointFailed
-		util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointFailed), "PodHasNoOwnerReference", fmt.Sprintf("checkpointed pod(%s) referenced by checkpoint resource(%s) has no owner reference", ckpt.Spec.PodName, ckpt.Name))
+	report, err := util.GritAgentReport(ctx, c.Client, job)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to get report of grit agent", "job", job.Name)
 		return nil
 	}
+	return report
+}
+
+// setRollbackCondition records whether side effects applied on the pod have been undone after checkpoint failed.
+func setRollbackCondition(clk clock.Clock, ckpt *v1alpha1.Checkpoint, report *metadata.AgentReport) {
+	switch {
+	case report == nil:
+	case report.RolledBack:
+		util.UpdateCondition(clk, &ckpt.Status.Conditions, metav1.ConditionTrue, v1alpha1.CheckpointRolledBackCondition, "RollbackSucceeded", "all changes made on the pod for checkpointing have been undone")
+	case len(report.RollbackError) != 0:
+		util.UpdateCondition(clk, &ckpt.Status.Conditions, metav1.ConditionFalse, v1alpha1.CheckpointRolledBackCondition, "RollbackFailed", report.RollbackError)
+	case len(report.StoppedContainers) != 0:
+		util.UpdateCondition(clk, &ckpt.Status.Conditions, metav1.ConditionFalse, v1alpha1.CheckpointRolledBackCondition, "PartiallyRolledBack", fmt.Sprintf("containers %s have been dumped and stopped by criu, other containers have been rolled back", strings.Join(report.StoppedContainers, ", ")))
+	}
+}
 
-	restore := v1alpha1.Restore{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      ckpt.Name,
-			Namespace: ckpt.Namespace,
-			Annotations: map[string]string{
-				v1alpha1.PodSpecHashLabel: ckpt.Status.PodSpecHash,
+// resolveStorageLocation returns the location where grit agent has stored checkpointed data.
+func (c *Controller) resolveStorageLocation(ctx context.Context, ckpt *v1alpha1.Checkpoint) (*v1alpha1.StorageLocation, error) {
+	if ckpt.Spec.ObjectStorage != nil {
+		return &v1alpha1.StorageLocation{
+			ObjectStorage: &v1alpha1.ObjectStorageLocation{
+				Endpoint: ckpt.Spec.ObjectStorage.Endpoint,
+				Bucket:   ckpt.Spec.ObjectStorage.Bucket,
+				Key:      util.ObjectStorageKey(ckpt.Spec.ObjectStorage, ckpt.Namespace, ckpt.Name),
 			},
-		},
-		Spec: v1alpha1.RestoreSpec{
-			CheckpointName: ckpt.Name,
-			OwnerRef:       *ownerRef,
-		},
+		}, nil
 	}
 
-	if err := c.Create(ctx, &restore); client.IgnoreAlreadyExists(err) != nil {
-		return err
+	if ckpt.Spec.Registry != nil {
+		return &v1alpha1.StorageLocation{
+			Registry: &v1alpha1.RegistryLocation{
+				Reference: fmt.Sprintf("%s:%s", util.RegistryRepository(ckpt.Spec.Registry, ckpt.Namespace, ckpt.Name), metadata.CheckpointArtifactTag),
+			},
+		}, nil
 	}
 
-	log.FromContext(ctx).Info("checkpoint pod spec", "name", checkpointPod.Name, "spec", checkpointPod.Spec)
-	// delete checkpoint pod
-	if checkpointPod.DeletionTimestamp.IsZero() {
-		if err := c.Delete(ctx, &checkpointPod); client.IgnoreNotFound(err) != nil {
-			return err
+	var pvc corev1.PersistentVolumeClaim
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.VolumeClaim.ClaimName}, &pvc); err != nil {
+		return nil, err
+	}
+	return &v1alpha1.StorageLocation{
+		PersistentVolumeClaim: &v1alpha1.PersistentVolumeClaimLocation{
+			ClaimName:  pvc.Name,
+			VolumeName: pvc.Spec.VolumeName,
+			Path:       path.Join(ckpt.Namespace, ckpt.Name),
+		},
+	}, nil
+}
+
+// checkpointedHandler is used for garbage collecting grit agent pod. then pvc for cloud storage can be used for restoring.
+func (c *Controller) checkpointedHandler(ctx context.Context, ckpt *v1alpha1.Checkpoint) error {
+	var gritAgentJob batchv1.Job
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: util.GritAgentJobName(ckpt, nil)}, &gritAgentJob); client.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil { // grit agent exist
+		if gritAgentJob.DeletionTimestamp.IsZero() { // skip deleting grit agent job
+			deletePolicy := metav1.DeletePropagationForeground
+			return c.Delete(ctx, &gritAgentJob, &client.DeleteOptions{PropagationPolicy: &deletePolicy})
 		}
 	}
 
-	ckpt.Status.Phase = v1alpha1.AutoMigrationSubmitted
-	util.UpdateCondition(c.clock, &ckpt.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.AutoMigrationSubmitted), "SubmittingCompleted", "restore resource is created and checkpoint pod is removed.")
 	return nil
 }
 
-// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=list;watch;get;patch
 // +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints/status,verbs=update
-// +kubebuilder:rbac:groups=kaito.sh,resources=restores,verbs=create
+// +kubebuilder:rbac:groups=kaito.sh,resources=restores,verbs=list;watch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list;watch;get;create;delete
-// +kubebuilder:rbac:groups="",resources=pods,verbs=get;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
 
 func (c *Controller) Register(_ context.Context, m manager.Manager) error {
 	return controllerruntime.NewControllerManagedBy(m).
 		Named("checkpoint.lifecycle").
 		For(&v1alpha1.Checkpoint{}).
 		Watches(&batchv1.Job{}, util.GritAgentJobHandler, builder.WithPredicates(util.GritAgentJobPredicate)).
+		Watches(&corev1.Pod{}, util.GritAgentPodHandler, builder.WithPredicates(util.GritAgentReportPodPredicate)).
+		Watches(&v1alpha1.Checkpoint{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			ckpt, ok := obj.(*v1alpha1.Checkpoint)
+			if !ok || len(ckpt.Spec.ParentCheckpointName) == 0 {
+				return []reconcile.Request{}
+			}
+
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{Namespace: ckpt.Namespace, Name: ckpt.Spec.ParentCheckpointName},
+				},
+			}
+		})).
+		Watches(&v1alpha1.Restore{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			restore, ok := obj.(*v1alpha1.Restore)
+			if !ok {
+				return []reconcile.Request{}
+			}
+
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.CheckpointName},
+				},
+			}
+		})).
 		WithOptions(controller.Options{
 			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
 				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
@@ -0,0 +1,223 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+func TestCancel(t *testing.T) {
+	ckpt := &v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "default",
+			Name:       "ckpt",
+			Finalizers: []string{v1alpha1.CheckpointDataFinalizer},
+		},
+		Spec: v1alpha1.CheckpointSpec{PodName: "pod", Cancel: true},
+		Status: v1alpha1.CheckpointStatus{
+			Phase: v1alpha1.Checkpointing,
+			Conditions: []metav1.Condition{
+				{Type: string(v1alpha1.Checkpointing), Status: metav1.ConditionTrue},
+			},
+		},
+	}
+	gritAgentJob := func(deleting bool) *batchv1.Job {
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: ckpt.Namespace, Name: util.GritAgentJobName(ckpt, nil)}}
+		if deleting {
+			job.Finalizers = []string{metav1.FinalizerDeleteDependents}
+			job.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		}
+		return job
+	}
+	gritAgentPod := func(state corev1.ContainerState, finalizers ...string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:  ckpt.Namespace,
+				Name:       "grit-agent-pod",
+				Labels:     map[string]string{batchv1.JobNameLabel: util.GritAgentJobName(ckpt, nil)},
+				Finalizers: finalizers,
+			},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "grit-agent", State: state}},
+			},
+		}
+	}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	terminated := func(message string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: message}}
+	}
+
+	testcases := map[string]struct {
+		objs                 []client.Object
+		expectedPhase        v1alpha1.CheckpointPhase
+		expectedMessage      string
+		expectedRolledBack   metav1.ConditionStatus
+		expectedJobDeleted   bool
+		expectedPodFinalizer bool
+	}{
+		"grit agent pod is kept before grit agent job is removed": {
+			objs:                 []client.Object{gritAgentJob(false), gritAgentPod(running)},
+			expectedPhase:        v1alpha1.Checkpointing,
+			expectedJobDeleted:   true,
+			expectedPodFinalizer: true,
+		},
+		"checkpoint is not cancelled until grit agent is terminated": {
+			objs:                 []client.Object{gritAgentJob(true), gritAgentPod(running, v1alpha1.GritAgentReportFinalizer)},
+			expectedPhase:        v1alpha1.Checkpointing,
+			expectedPodFinalizer: true,
+		},
+		"pod is rolled back when grit agent reports it": {
+			objs:                 []client.Object{gritAgentJob(true), gritAgentPod(terminated(`{"rolledBack":true}`), v1alpha1.GritAgentReportFinalizer)},
+			expectedPhase:        v1alpha1.CheckpointCancelled,
+			expectedMessage:      "checkpoint is cancelled before it's checkpointed, and the pod has been rolled back",
+			expectedRolledBack:   metav1.ConditionTrue,
+			expectedPodFinalizer: true,
+		},
+		"failed rollback is reported": {
+			objs:                 []client.Object{gritAgentJob(true), gritAgentPod(terminated(`{"rollbackError":"failed to thaw"}`), v1alpha1.GritAgentReportFinalizer)},
+			expectedPhase:        v1alpha1.CheckpointCancelled,
+			expectedMessage:      "checkpoint is cancelled before it's checkpointed, but the pod failed to be rolled back",
+			expectedRolledBack:   metav1.ConditionFalse,
+			expectedPodFinalizer: true,
+		},
+		"partial rollback is reported": {
+			objs:                 []client.Object{gritAgentJob(true), gritAgentPod(terminated(`{"stoppedContainers":["app"]}`), v1alpha1.GritAgentReportFinalizer)},
+			expectedPhase:        v1alpha1.CheckpointCancelled,
+			expectedMessage:      "checkpoint is cancelled before it's checkpointed, but the pod is partially rolled back",
+			expectedRolledBack:   metav1.ConditionFalse,
+			expectedPodFinalizer: true,
+		},
+		"rollback is not claimed without report of grit agent": {
+			expectedPhase:      v1alpha1.CheckpointCancelled,
+			expectedMessage:    "checkpoint is cancelled before it's checkpointed, grit agent doesn't report whether the pod has been rolled back",
+			expectedJobDeleted: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c, _ := newTestController(t, append(tc.objs, ckpt.DeepCopy())...)
+			var updatedCkpt v1alpha1.Checkpoint
+			if err := c.Get(ctx, client.ObjectKeyFromObject(ckpt), &updatedCkpt); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Reconcile(ctx, updatedCkpt.DeepCopy()); err != nil {
+				t.Fatalf("failed to reconcile checkpoint, %v", err)
+			}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(ckpt), &updatedCkpt); err != nil {
+				t.Fatal(err)
+			}
+			if updatedCkpt.Status.Phase != tc.expectedPhase {
+				t.Fatalf("expected checkpoint is %s, got %s", tc.expectedPhase, updatedCkpt.Status.Phase)
+			}
+			if cond := meta.FindStatusCondition(updatedCkpt.Status.Conditions, string(v1alpha1.CheckpointCancelled)); len(tc.expectedMessage) != 0 && (cond == nil || cond.Message != tc.expectedMessage) {
+				t.Errorf("expected cancelled condition with message %q, got %+v", tc.expectedMessage, cond)
+			}
+			cond := meta.FindStatusCondition(updatedCkpt.Status.Conditions, v1alpha1.CheckpointRolledBackCondition)
+			if (cond == nil && len(tc.expectedRolledBack) != 0) || (cond != nil && cond.Status != tc.expectedRolledBack) {
+				t.Errorf("expected rolled back condition %q, got %+v", tc.expectedRolledBack, cond)
+			}
+
+			err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: util.GritAgentJobName(ckpt, nil)}, &batchv1.Job{})
+			if tc.expectedJobDeleted != apierrors.IsNotFound(err) {
+				t.Errorf("expected grit agent job is deleted: %v, got %v", tc.expectedJobDeleted, err)
+			}
+
+			// grit agent pod is released once checkpoint is cancelled.
+			if tc.expectedPhase == v1alpha1.CheckpointCancelled {
+				if _, err := c.Reconcile(ctx, &updatedCkpt); err != nil {
+					t.Fatalf("failed to reconcile checkpoint, %v", err)
+				}
+				tc.expectedPodFinalizer = false
+			}
+			var pod corev1.Pod
+			if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: "grit-agent-pod"}, &pod); client.IgnoreNotFound(err) != nil {
+				t.Fatal(err)
+			}
+			if controllerutil.ContainsFinalizer(&pod, v1alpha1.GritAgentReportFinalizer) != tc.expectedPodFinalizer {
+				t.Errorf("expected finalizer of grit agent pod: %v, got %v", tc.expectedPodFinalizer, pod.Finalizers)
+			}
+		})
+	}
+}
+
+func TestDeadlineExceeded(t *testing.T) {
+	checkpoint := func(phase v1alpha1.CheckpointPhase) *v1alpha1.Checkpoint {
+		conditions := []metav1.Condition{{Type: string(v1alpha1.CheckpointPending), Status: metav1.ConditionTrue}}
+		if phase == v1alpha1.Checkpointing {
+			conditions = append(conditions, metav1.Condition{Type: string(v1alpha1.Checkpointing), Status: metav1.ConditionTrue})
+		}
+		return &v1alpha1.Checkpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         "default",
+				Name:              "ckpt",
+				Finalizers:        []string{v1alpha1.CheckpointDataFinalizer},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+			},
+			Spec:   v1alpha1.CheckpointSpec{PodName: "pod", ActiveDeadlineSeconds: ptr.To[int64](60)},
+			Status: v1alpha1.CheckpointStatus{Phase: phase, NodeName: "node-1", Conditions: conditions},
+		}
+	}
+	gritAgentJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "grit-agent-ckpt"}}
+
+	testcases := map[string]struct {
+		ckpt *v1alpha1.Checkpoint
+		objs []client.Object
+	}{
+		"failure is not overwritten after grit agent job is removed": {
+			ckpt: checkpoint(v1alpha1.Checkpointing),
+			objs: []client.Object{gritAgentJob.DeepCopy()},
+		},
+		"grit agent job is not created again for pending checkpoint": {
+			ckpt: checkpoint(v1alpha1.CheckpointPending),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c, _ := newTestController(t, append(tc.objs, tc.ckpt)...)
+
+			// the second reconciliation should keep the failure of the first one.
+			for i := 0; i < 2; i++ {
+				var ckpt v1alpha1.Checkpoint
+				if err := c.Get(ctx, client.ObjectKeyFromObject(tc.ckpt), &ckpt); err != nil {
+					t.Fatal(err)
+				}
+				if _, err := c.Reconcile(ctx, &ckpt); err != nil {
+					t.Fatalf("failed to reconcile checkpoint, %v", err)
+				}
+			}
+
+			var ckpt v1alpha1.Checkpoint
+			if err := c.Get(ctx, client.ObjectKeyFromObject(tc.ckpt), &ckpt); err != nil {
+				t.Fatal(err)
+			}
+			if ckpt.Status.Phase != v1alpha1.CheckpointFailed {
+				t.Errorf("expected checkpoint is %s, got %s", v1alpha1.CheckpointFailed, ckpt.Status.Phase)
+			}
+			if !util.DeadlineExceeded(ckpt.Status.Conditions, string(v1alpha1.CheckpointFailed)) {
+				t.Errorf("expected failed condition with reason %s, got %+v", util.DeadlineExceededReason, ckpt.Status.Conditions)
+			}
+			if err := c.Get(ctx, client.ObjectKeyFromObject(gritAgentJob), &batchv1.Job{}); !apierrors.IsNotFound(err) {
+				t.Errorf("expected grit agent job is removed, got %v", err)
+			}
+		})
+	}
+}
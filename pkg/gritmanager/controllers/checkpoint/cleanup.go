@@ -0,0 +1,169 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"context"
+	"sort"
+
+	"github.com/samber/lo"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+// finalize is used for removing checkpointed data from storage volume and nodes before checkpoint is deleted.
+// checkpoint can't be deleted until all Restores which reference it have completed and all child checkpoints are deleted.
+func (c *Controller) finalize(ctx context.Context, ckpt *v1alpha1.Checkpoint) error {
+	if !controllerutil.ContainsFinalizer(ckpt, v1alpha1.CheckpointDataFinalizer) {
+		return nil
+	}
+
+	var restoreList v1alpha1.RestoreList
+	if err := c.List(ctx, &restoreList, &client.ListOptions{Namespace: ckpt.Namespace}); err != nil {
+		return err
+	}
+	restores := lo.Filter(restoreList.Items, func(restore v1alpha1.Restore, _ int) bool {
+		return restore.Spec.CheckpointName == ckpt.Name
+	})
+
+	inUseRestores := lo.Filter(restores, func(restore v1alpha1.Restore, _ int) bool {
+		return restore.Status.Phase != v1alpha1.Restored && restore.Status.Phase != v1alpha1.RestoreFailed
+	})
+	if len(inUseRestores) != 0 {
+		log.FromContext(ctx).Info("checkpoint is used by restores, wait for restores completed before removing checkpointed data", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "restores", lo.Map(inUseRestores, func(restore v1alpha1.Restore, _ int) string { return restore.Name }))
+		return nil
+	}
+
+	// incremental checkpoints need checkpointed data of parent checkpoints for restoring.
+	var ckptList v1alpha1.CheckpointList
+	if err := c.List(ctx, &ckptList, &client.ListOptions{Namespace: ckpt.Namespace}); err != nil {
+		return err
+	}
+	children := lo.Filter(ckptList.Items, func(item v1alpha1.Checkpoint, _ int) bool {
+		return item.Spec.ParentCheckpointName == ckpt.Name
+	})
+	if len(children) != 0 {
+		log.FromContext(ctx).Info("checkpoint is parent of other checkpoints, wait for them deleted before removing checkpointed data", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "children", lo.Map(children, func(child v1alpha1.Checkpoint, _ int) string { return child.Name }))
+		return nil
+	}
+
+	// grit agent job for checkpointing maybe is still running, deletion is handled as cancel, so stop it and wait for
+	// grit agent rolling back the pod before cleaning up data.
+	var gritAgentJob batchv1.Job
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: util.GritAgentJobName(ckpt, nil)}, &gritAgentJob); err == nil {
+		if gritAgentJob.DeletionTimestamp.IsZero() {
+			deletePolicy := metav1.DeletePropagationForeground
+			return c.Delete(ctx, &gritAgentJob, &client.DeleteOptions{PropagationPolicy: &deletePolicy})
+		}
+		return nil
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	nodeNames, err := c.resolveCleanupNodes(ctx, ckpt, restores)
+	if err != nil {
+		return err
+	}
+
+	// host path data is removed by a job on each node. the storage is shared across nodes, so data in it is removed by a
+	// separate job on any node, and it's removed even if the checkpointed node has been removed from cluster.
+	jobNodeNames := nodeNames
+	if agentmanager.HasStorage(ckpt) {
+		jobNodeNames = append([]string{""}, nodeNames...)
+	}
+
+	completed := true
+	var failedJobs []string
+	for _, nodeName := range jobNodeNames {
+		var cleanupJob batchv1.Job
+		jobName := util.GritAgentCleanupJobName(ckpt, nodeName)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: jobName}, &cleanupJob); apierrors.IsNotFound(err) {
+			job, err := c.agentManager.GenerateGritAgentCleanupJob(ctx, ckpt, jobName, nodeName)
+			if err != nil {
+				// the job can't be generated(like grit-agent-config is missing), it's handled as a failed cleanup job,
+				// so deleting checkpoint is not blocked forever.
+				log.FromContext(ctx).Error(err, "failed to generate cleanup job", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "job", jobName)
+				c.recorder.Eventf(ckpt, corev1.EventTypeWarning, "CleanupFailed", "failed to generate cleanup job %s, %v, the data should be removed manually", jobName, err)
+				continue
+			}
+			if err := c.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
+				return err
+			}
+			completed = false
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		isCompleted, isFailed := util.JobCompletedOrFailed(&cleanupJob)
+		if isFailed {
+			failedJobs = append(failedJobs, jobName)
+		} else if !isCompleted {
+			completed = false
+		}
+	}
+
+	if !completed {
+		return nil
+	}
+
+	// don't block deleting checkpoint forever, checkpointed data left by failed jobs should be removed manually.
+	if len(failedJobs) != 0 {
+		log.FromContext(ctx).Error(nil, "failed to clean up checkpointed data", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "jobs", failedJobs)
+		c.recorder.Eventf(ckpt, corev1.EventTypeWarning, "CleanupFailed", "failed to clean up checkpointed data by jobs %v, the data should be removed manually", failedJobs)
+	}
+
+	// jobs are listed by label, so jobs of nodes which have been removed during cleanup are deleted too.
+	var cleanupJobs batchv1.JobList
+	if err := c.List(ctx, &cleanupJobs, client.InNamespace(ckpt.Namespace), client.MatchingLabels{v1alpha1.CheckpointCleanupLabel: ckpt.Name}); err != nil {
+		return err
+	}
+	deletePolicy := metav1.DeletePropagationForeground
+	for i := range cleanupJobs.Items {
+		if err := c.Delete(ctx, &cleanupJobs.Items[i], &client.DeleteOptions{PropagationPolicy: &deletePolicy}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	log.FromContext(ctx).Info("checkpointed data has been cleaned up", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "nodes", nodeNames, "storage", agentmanager.HasStorage(ckpt), "failedJobs", failedJobs)
+	updatedCkpt := ckpt.DeepCopy()
+	controllerutil.RemoveFinalizer(updatedCkpt, v1alpha1.CheckpointDataFinalizer)
+	return c.Patch(ctx, updatedCkpt, client.MergeFrom(ckpt))
+}
+
+// resolveCleanupNodes returns nodes which store checkpointed data in host path. the checkpointed node is always the first one,
+// and nodes which have been removed from cluster are skipped, because their host path data is gone with them.
+func (c *Controller) resolveCleanupNodes(ctx context.Context, ckpt *v1alpha1.Checkpoint, restores []v1alpha1.Restore) ([]string, error) {
+	restoreNodeNames := lo.Uniq(lo.FilterMap(restores, func(restore v1alpha1.Restore, _ int) (string, bool) {
+		return restore.Status.NodeName, len(restore.Status.NodeName) != 0 && restore.Status.NodeName != ckpt.Status.NodeName
+	}))
+	sort.Strings(restoreNodeNames)
+
+	candidates := restoreNodeNames
+	if len(ckpt.Status.NodeName) != 0 {
+		candidates = append([]string{ckpt.Status.NodeName}, restoreNodeNames...)
+	}
+
+	var nodeNames []string
+	for _, nodeName := range candidates {
+		var node corev1.Node
+		if err := c.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		nodeNames = append(nodeNames, nodeName)
+	}
+	return nodeNames, nil
+}
@@ -0,0 +1,223 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpoint
+
+import (
+	"context"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+const testGritAgentTemplate = `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{ .jobName }}
+  namespace: {{ .namespace }}
+spec:
+  template:
+    spec:
+      nodeName: {{ .nodeName }}
+      restartPolicy: Never
+      containers:
+      - name: grit-agent
+        image: grit-agent
+`
+
+func newTestController(t *testing.T, objs ...client.Object) (*Controller, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	if err := indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "grit", Name: agentmanager.GritAgentConfigMapName},
+		Data: map[string]string{
+			agentmanager.HostPathKey:      "/var/lib/grit",
+			agentmanager.GritAgentYamlKey: testGritAgentTemplate,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := record.NewFakeRecorder(10)
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&v1alpha1.Checkpoint{}).Build()
+	manager := agentmanager.NewAgentManager("grit", corev1listers.NewConfigMapLister(indexer), "")
+	return NewController(clock.NewFakeClock(metav1.Now().Time), kubeClient, manager, recorder), recorder
+}
+
+func deletingCheckpoint(storage *corev1.PersistentVolumeClaimVolumeSource) *v1alpha1.Checkpoint {
+	return &v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "ckpt",
+			Finalizers:        []string{v1alpha1.CheckpointDataFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: metav1.Now().Time},
+		},
+		Spec: v1alpha1.CheckpointSpec{
+			PodName:     "pod",
+			VolumeClaim: storage,
+		},
+		Status: v1alpha1.CheckpointStatus{
+			Phase:    v1alpha1.Checkpointed,
+			NodeName: "node-1",
+		},
+	}
+}
+
+func TestFinalize(t *testing.T) {
+	node := func(name string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+	restoredOn := func(nodeName string) *v1alpha1.Restore {
+		return &v1alpha1.Restore{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "restore-" + nodeName},
+			Spec:       v1alpha1.RestoreSpec{CheckpointName: "ckpt"},
+			Status:     v1alpha1.RestoreStatus{Phase: v1alpha1.Restored, NodeName: nodeName},
+		}
+	}
+	storage := &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"}
+
+	testcases := map[string]struct {
+		ckpt         *v1alpha1.Checkpoint
+		objs         []client.Object
+		expectedJobs map[string]string
+	}{
+		"checkpointed node is removed, data in storage is still cleaned up": {
+			ckpt: deletingCheckpoint(storage),
+			expectedJobs: map[string]string{
+				"grit-agent-ckpt-cleanup-storage": "",
+			},
+		},
+		"host path data is cleaned up on checkpointed and restored nodes": {
+			ckpt: deletingCheckpoint(nil),
+			objs: []client.Object{node("node-1"), node("node-2"), restoredOn("node-2"), restoredOn("node-3")},
+			expectedJobs: map[string]string{
+				util.GritAgentCleanupJobName(deletingCheckpoint(nil), "node-1"): "node-1",
+				util.GritAgentCleanupJobName(deletingCheckpoint(nil), "node-2"): "node-2",
+			},
+		},
+		"checkpoint without storage on removed node has nothing to clean up": {
+			ckpt: deletingCheckpoint(nil),
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			c, _ := newTestController(t, append(tc.objs, tc.ckpt)...)
+			if err := c.finalize(ctx, tc.ckpt); err != nil {
+				t.Fatalf("failed to finalize checkpoint, %v", err)
+			}
+
+			var jobs batchv1.JobList
+			if err := c.List(ctx, &jobs, client.MatchingLabels{v1alpha1.CheckpointCleanupLabel: tc.ckpt.Name}); err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs.Items) != len(tc.expectedJobs) {
+				t.Fatalf("expected %d cleanup jobs, got %d", len(tc.expectedJobs), len(jobs.Items))
+			}
+			for _, job := range jobs.Items {
+				nodeName, ok := tc.expectedJobs[job.Name]
+				if !ok {
+					t.Fatalf("unexpected cleanup job %s", job.Name)
+				}
+				if job.Spec.Template.Spec.NodeName != nodeName {
+					t.Errorf("expected job %s on node %q, got %q", job.Name, nodeName, job.Spec.Template.Spec.NodeName)
+				}
+			}
+
+			// the finalizer is only removed after all cleanup jobs finished.
+			err := c.Get(ctx, client.ObjectKeyFromObject(tc.ckpt), &v1alpha1.Checkpoint{})
+			if len(tc.expectedJobs) != 0 && err != nil {
+				t.Errorf("expected checkpoint is kept until cleanup jobs finished, got %v", err)
+			} else if len(tc.expectedJobs) == 0 && !apierrors.IsNotFound(err) {
+				t.Errorf("expected checkpoint is removed, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFinalizeWithFailedCleanupJob(t *testing.T) {
+	ctx := context.Background()
+	ckpt := deletingCheckpoint(&corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"})
+	finishedJob := func(nodeName string, condType batchv1.JobConditionType) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: ckpt.Namespace,
+				Name:      util.GritAgentCleanupJobName(ckpt, nodeName),
+				Labels:    map[string]string{v1alpha1.CheckpointCleanupLabel: ckpt.Name},
+			},
+			Status: batchv1.JobStatus{
+				Conditions: []batchv1.JobCondition{{Type: condType, Status: corev1.ConditionTrue}},
+			},
+		}
+	}
+
+	c, recorder := newTestController(t, ckpt,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		finishedJob("", batchv1.JobFailed),
+		finishedJob("node-1", batchv1.JobComplete),
+	)
+	if err := c.finalize(ctx, ckpt); err != nil {
+		t.Fatalf("failed to finalize checkpoint, %v", err)
+	}
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(ckpt), &v1alpha1.Checkpoint{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected checkpoint is removed after cleanup jobs finished, got %v", err)
+	}
+	var jobs batchv1.JobList
+	if err := c.List(ctx, &jobs); err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Items) != 0 {
+		t.Errorf("expected cleanup jobs are deleted, got %d", len(jobs.Items))
+	}
+	select {
+	case event := <-recorder.Events:
+		if event != "Warning CleanupFailed failed to clean up checkpointed data by jobs [grit-agent-ckpt-cleanup-storage], the data should be removed manually" {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Errorf("expected a warning event for failed cleanup job")
+	}
+}
+
+func TestFinalizeWithoutGritAgentConfig(t *testing.T) {
+	ctx := context.Background()
+	ckpt := deletingCheckpoint(&corev1.PersistentVolumeClaimVolumeSource{ClaimName: "pvc"})
+	c, recorder := newTestController(t, ckpt, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	c.agentManager = agentmanager.NewAgentManager("grit", corev1listers.NewConfigMapLister(indexer), "")
+
+	if err := c.finalize(ctx, ckpt); err != nil {
+		t.Fatalf("failed to finalize checkpoint, %v", err)
+	}
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(ckpt), &v1alpha1.Checkpoint{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected checkpoint is removed when cleanup jobs can't be generated, got %v", err)
+	}
+	if len(recorder.Events) != 2 {
+		t.Errorf("expected a warning event for each cleanup job, got %d", len(recorder.Events))
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpointgroup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type Controller struct {
+	client.Client
+	clock clock.Clock
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client) *Controller {
+	return &Controller{
+		clock:  clk,
+		Client: kubeClient,
+	}
+}
+
+// Reconcile creates a Checkpoint for each selected pod, and aggregates the phases of member Checkpoints into CheckpointGroup.
+// the grit agent of each member freezes its pod and waits for all other members frozen before dumping, so all member
+// pods are checkpointed at the same moment.
+func (c *Controller) Reconcile(ctx context.Context, group *v1alpha1.CheckpointGroup) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "checkpointgroup.lifecycle")
+
+	updatedGroup := group.DeepCopy()
+	var err error
+	switch updatedGroup.Status.Phase {
+	case "":
+		updatedGroup.Status.Phase = v1alpha1.CheckpointGroupCreated
+		util.UpdateCondition(c.clock, &updatedGroup.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointGroupCreated), "CheckpointGroupIsCreated", "checkpoint group resource is created")
+	case v1alpha1.CheckpointGroupCreated:
+		err = c.createMembers(ctx, updatedGroup)
+	case v1alpha1.CheckpointGroupCheckpointing:
+		err = c.syncMembers(ctx, updatedGroup)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(group, updatedGroup) {
+		return reconcile.Result{}, c.Status().Update(ctx, updatedGroup)
+	}
+	return reconcile.Result{}, nil
+}
+
+// createMembers is used for creating Checkpoint for each selected pod, then upgraded state to Checkpointing.
+func (c *Controller) createMembers(ctx context.Context, group *v1alpha1.CheckpointGroup) error {
+	pods, err := util.SelectRunningPods(ctx, c.Client, group.Namespace, group.Spec.OwnerRef, group.Spec.Selector)
+	if err != nil {
+		return err
+	}
+
+	if len(pods) == 0 {
+		group.Status.Phase = v1alpha1.CheckpointGroupFailed
+		util.UpdateCondition(c.clock, &group.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointGroupFailed), "PodNotSelected", "there is no running pod selected for checkpoint group")
+		return nil
+	}
+
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	members := lo.Map(pods, func(pod corev1.Pod, i int) v1alpha1.CheckpointGroupMember {
+		return v1alpha1.CheckpointGroupMember{
+			PodName:        pod.Name,
+			CheckpointName: fmt.Sprintf("%s-%d", group.Name, i),
+		}
+	})
+	memberNames := strings.Join(lo.Map(members, func(member v1alpha1.CheckpointGroupMember, _ int) string { return member.CheckpointName }), ",")
+
+	for _, member := range members {
+		ckpt := v1alpha1.Checkpoint{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      member.CheckpointName,
+				Namespace: group.Namespace,
+				Labels: map[string]string{
+					v1alpha1.CheckpointGroupLabel: group.Name,
+				},
+				Annotations: map[string]string{
+					v1alpha1.CheckpointGroupMembersAnnotation: memberNames,
+				},
+			},
+			Spec: v1alpha1.CheckpointSpec{
+				PodName:       member.PodName,
+				Mode:          group.Spec.Mode,
+				VolumeClaim:   group.Spec.VolumeClaim,
+				ObjectStorage: group.Spec.ObjectStorage,
+				Compression:   group.Spec.Compression,
+				Encryption:    group.Spec.Encryption,
+			},
+		}
+		if group.Spec.FreezeTimeoutSeconds != nil {
+			ckpt.Annotations[v1alpha1.CheckpointGroupFreezeTimeoutAnnotation] = (time.Duration(*group.Spec.FreezeTimeoutSeconds) * time.Second).String()
+		}
+		if err := controllerutil.SetControllerReference(group, &ckpt, c.Scheme()); err != nil {
+			return err
+		}
+
+		// checkpoint maybe has been created in the previous reconcile but status update failed, so adopt it.
+		if err := c.Create(ctx, &ckpt); client.IgnoreAlreadyExists(err) != nil {
+			return err
+		}
+	}
+	log.FromContext(ctx).Info("member checkpoints are created for checkpoint group", "namespace", group.Namespace, "group", group.Name, "checkpoints", memberNames)
+
+	group.Status.Members = members
+	group.Status.Phase = v1alpha1.CheckpointGroupCheckpointing
+	util.UpdateCondition(c.clock, &group.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointGroupCheckpointing), "MemberCheckpointsCreated", fmt.Sprintf("%d member checkpoints are created", len(members)))
+	return nil
+}
+
+// syncMembers is used for recording phases of member Checkpoints. CheckpointGroup fails as soon as any member fails,
+// because checkpointed data of other members can't be used for restoring the workload consistently.
+func (c *Controller) syncMembers(ctx context.Context, group *v1alpha1.CheckpointGroup) error {
+	var failedMembers []string
+	for i := range group.Status.Members {
+		member := &group.Status.Members[i]
+		var ckpt v1alpha1.Checkpoint
+		if err := c.Get(ctx, client.ObjectKey{Namespace: group.Namespace, Name: member.CheckpointName}, &ckpt); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			member.Phase = v1alpha1.CheckpointFailed
+		} else {
+			member.Phase = ckpt.Status.Phase
+		}
+
+		if member.Phase == v1alpha1.CheckpointFailed || member.Phase == v1alpha1.CheckpointCancelled {
+			failedMembers = append(failedMembers, member.CheckpointName)
+		}
+	}
+
+	if len(failedMembers) != 0 {
+		group.Status.Phase = v1alpha1.CheckpointGroupFailed
+		util.UpdateCondition(c.clock, &group.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointGroupFailed), "MemberCheckpointFailed", fmt.Sprintf("member checkpoints(%s) failed, were cancelled or were removed", strings.Join(failedMembers, ",")))
+		return nil
+	}
+
+	allCheckpointed := lo.EveryBy(group.Status.Members, func(member v1alpha1.CheckpointGroupMember) bool {
+		return member.Phase == v1alpha1.Checkpointed
+	})
+	if allCheckpointed {
+		group.Status.Phase = v1alpha1.CheckpointGroupCheckpointed
+		util.UpdateCondition(c.clock, &group.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.CheckpointGroupCheckpointed), "AllMembersCheckpointed", fmt.Sprintf("all %d member checkpoints are checkpointed", len(group.Status.Members)))
+	}
+	return nil
+}
+
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpointgroups,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpointgroups/status,verbs=update
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=list;watch;get;create
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list;watch
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("checkpointgroup.lifecycle").
+		For(&v1alpha1.CheckpointGroup{}).
+		Owns(&v1alpha1.Checkpoint{}).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 3,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,172 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpointrequest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+// Controller creates a Checkpoint for each new token of CheckpointRequestAnnotation on pod, and writes the phase and
+// data path of the Checkpoint back into pod annotations, so workflow engines which can only patch pods can drive
+// checkpoints without knowing about Checkpoint resource.
+type Controller struct {
+	client.Client
+	clock clock.Clock
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client) *Controller {
+	return &Controller{
+		Client: kubeClient,
+		clock:  clk,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, pod *corev1.Pod) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "checkpoint.request")
+
+	token := pod.Annotations[v1alpha1.CheckpointRequestAnnotation]
+	if len(token) == 0 || !pod.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	updatedPod := pod.DeepCopy()
+	if pod.Annotations[v1alpha1.CheckpointRequestObservedAnnotation] != token {
+		if err := c.createCheckpoint(ctx, updatedPod, token); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if name := pod.Annotations[v1alpha1.CheckpointRequestCheckpointAnnotation]; len(name) != 0 {
+		var ckpt v1alpha1.Checkpoint
+		if err := c.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: name}, &ckpt); client.IgnoreNotFound(err) != nil {
+			return reconcile.Result{}, err
+		} else if err == nil && len(ckpt.Status.Phase) != 0 {
+			setResult(updatedPod, token, ckpt.Name, string(ckpt.Status.Phase), ckpt.Status.StorageLocation.String())
+		}
+	}
+
+	if !maps.Equal(pod.Annotations, updatedPod.Annotations) {
+		return reconcile.Result{}, c.Patch(ctx, updatedPod, client.MergeFrom(pod))
+	}
+	return reconcile.Result{}, nil
+}
+
+func (c *Controller) createCheckpoint(ctx context.Context, pod *corev1.Pod, token string) error {
+	ckpt, err := newRequestedCheckpoint(pod, token)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to create checkpoint for request", "namespace", pod.Namespace, "pod", pod.Name, "token", token)
+		setResult(pod, token, "", string(v1alpha1.CheckpointFailed), "")
+		return nil
+	}
+
+	// checkpoint maybe has been created in the previous reconcile but pod patch failed, so adopt it.
+	if err := c.Create(ctx, ckpt); client.IgnoreAlreadyExists(err) != nil {
+		// checkpoint is rejected by webhook when pod is not running or storage in template is invalid.
+		if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) || apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "checkpoint for request is rejected", "namespace", pod.Namespace, "pod", pod.Name, "token", token)
+			setResult(pod, token, "", string(v1alpha1.CheckpointFailed), "")
+			return nil
+		}
+		return err
+	}
+	log.FromContext(ctx).Info("checkpoint is created for request", "namespace", pod.Namespace, "pod", pod.Name, "token", token, "checkpoint", ckpt.Name)
+
+	setResult(pod, token, ckpt.Name, string(v1alpha1.CheckpointCreated), "")
+	return nil
+}
+
+// newRequestedCheckpoint creates a Checkpoint for the request token of pod from the template in CheckpointTemplateAnnotation.
+// checkpoint is named by the hash of token, so the same request always maps to the same Checkpoint.
+func newRequestedCheckpoint(pod *corev1.Pod, token string) (*v1alpha1.Checkpoint, error) {
+	template, ok := pod.Annotations[v1alpha1.CheckpointTemplateAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("annotation %s is not specified", v1alpha1.CheckpointTemplateAnnotation)
+	}
+	var spec v1alpha1.CheckpointSpec
+	if err := json.Unmarshal([]byte(template), &spec); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint template in annotation %s, %w", v1alpha1.CheckpointTemplateAnnotation, err)
+	}
+	spec.PodName = pod.Name
+
+	hasher := fnv.New32a()
+	fmt.Fprintf(hasher, "%s/%s", pod.UID, token)
+	return &v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%08x", pod.Name, hasher.Sum32()),
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				v1alpha1.CheckpointRequestPodLabel: pod.Name,
+			},
+			Annotations: map[string]string{
+				v1alpha1.CheckpointRequestAnnotation: token,
+			},
+		},
+		Spec: spec,
+	}, nil
+}
+
+// setResult writes the result of checkpoint request into pod annotations.
+func setResult(pod *corev1.Pod, token, checkpointName, phase, dataPath string) {
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[v1alpha1.CheckpointRequestObservedAnnotation] = token
+	pod.Annotations[v1alpha1.CheckpointRequestCheckpointAnnotation] = checkpointName
+	pod.Annotations[v1alpha1.CheckpointRequestPhaseAnnotation] = phase
+	pod.Annotations[v1alpha1.CheckpointRequestDataPathAnnotation] = dataPath
+}
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;patch
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=get;list;watch;create
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("checkpoint.request").
+		For(&corev1.Pod{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			return len(obj.GetAnnotations()[v1alpha1.CheckpointRequestAnnotation]) != 0
+		}))).
+		Watches(&v1alpha1.Checkpoint{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			podName, ok := obj.GetLabels()[v1alpha1.CheckpointRequestPodLabel]
+			if !ok {
+				return []reconcile.Request{}
+			}
+
+			return []reconcile.Request{
+				{
+					NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: podName},
+				},
+			}
+		})).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 5,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
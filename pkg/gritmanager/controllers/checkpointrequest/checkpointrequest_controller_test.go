@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpointrequest
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+func TestNewRequestedCheckpoint(t *testing.T) {
+	newPod := func(annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "train-0", UID: "pod-uid", Annotations: annotations},
+		}
+	}
+
+	testcases := map[string]struct {
+		pod         *corev1.Pod
+		token       string
+		expectError bool
+	}{
+		"checkpoint is created from template": {
+			pod:   newPod(map[string]string{v1alpha1.CheckpointTemplateAnnotation: `{"volumeClaim":{"claimName":"checkpoint-pvc"}}`}),
+			token: "step-1000",
+		},
+		"template is not specified": {
+			pod:         newPod(nil),
+			token:       "step-1000",
+			expectError: true,
+		},
+		"template is invalid": {
+			pod:         newPod(map[string]string{v1alpha1.CheckpointTemplateAnnotation: `{"volumeClaim":`}),
+			token:       "step-1000",
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ckpt, err := newRequestedCheckpoint(tc.pod, tc.token)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("failed to create checkpoint, %v", err)
+			}
+
+			if ckpt.Spec.PodName != tc.pod.Name || ckpt.Spec.VolumeClaim == nil || ckpt.Labels[v1alpha1.CheckpointRequestPodLabel] != tc.pod.Name || ckpt.Annotations[v1alpha1.CheckpointRequestAnnotation] != tc.token {
+				t.Errorf("unexpected checkpoint %+v", ckpt)
+			}
+			// the same request maps to the same checkpoint, and a new token maps to a new checkpoint.
+			if same, _ := newRequestedCheckpoint(tc.pod, tc.token); same.Name != ckpt.Name {
+				t.Errorf("expected the same checkpoint name %s for the same token, got %s", ckpt.Name, same.Name)
+			}
+			if other, _ := newRequestedCheckpoint(tc.pod, tc.token+"-next"); other.Name == ckpt.Name {
+				t.Errorf("expected a new checkpoint name for a new token, got %s", other.Name)
+			}
+		})
+	}
+}
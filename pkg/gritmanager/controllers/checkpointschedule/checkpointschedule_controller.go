@@ -0,0 +1,236 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpointschedule
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/samber/lo"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+// maxMissedSchedules is the number of missed scheduled times which are walked through one by one, the same limit as
+// CronJob controller. the most recent scheduled time is searched backward from now when more are missed.
+const maxMissedSchedules = 100
+
+type Controller struct {
+	client.Client
+	clock    clock.Clock
+	recorder record.EventRecorder
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, recorder record.EventRecorder) *Controller {
+	return &Controller{
+		clock:    clk,
+		Client:   kubeClient,
+		recorder: recorder,
+	}
+}
+
+// Reconcile creates Checkpoint resources for CheckpointSchedule at each scheduled time. the created Checkpoint is handled by
+// checkpoint controller, and CheckpointSchedule only tracks the result of it.
+func (c *Controller) Reconcile(ctx context.Context, schedule *v1alpha1.CheckpointSchedule) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "checkpointschedule.lifecycle")
+
+	updatedSchedule := schedule.DeepCopy()
+	result, err := c.reconcileSchedule(ctx, updatedSchedule)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(schedule, updatedSchedule) {
+		return result, c.Status().Update(ctx, updatedSchedule)
+	}
+	return result, nil
+}
+
+func (c *Controller) reconcileSchedule(ctx context.Context, schedule *v1alpha1.CheckpointSchedule) (reconcile.Result, error) {
+	if err := c.syncActiveCheckpoint(ctx, schedule); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if schedule.Spec.Suspend {
+		return reconcile.Result{}, nil
+	}
+
+	sched, err := cron.ParseStandard(schedule.Spec.Schedule)
+	if err != nil {
+		util.UpdateCondition(c.clock, &schedule.Status.Conditions, metav1.ConditionFalse, v1alpha1.CheckpointScheduleScheduled, "InvalidSchedule", fmt.Sprintf("failed to parse schedule(%s), %v", schedule.Spec.Schedule, err))
+		return reconcile.Result{}, nil
+	}
+
+	now := c.clock.Now()
+	earliestTime := schedule.CreationTimestamp.Time
+	if schedule.Status.LastScheduleTime != nil {
+		earliestTime = schedule.Status.LastScheduleTime.Time
+	}
+	result := reconcile.Result{RequeueAfter: sched.Next(now).Sub(now)}
+
+	scheduledTime, missed := mostRecentScheduleTime(sched, earliestTime, now)
+	if scheduledTime == nil {
+		return result, nil
+	}
+	if missed > maxMissedSchedules {
+		log.FromContext(ctx).Error(nil, "too many scheduled times are missed", "namespace", schedule.Namespace, "schedule", schedule.Name, "limit", maxMissedSchedules)
+		c.recorder.Eventf(schedule, corev1.EventTypeWarning, "TooManyMissedSchedules", "more than %d scheduled times are missed since %s, only run at %s is started, check clock skew or whether grit manager was down", maxMissedSchedules, earliestTime.Format(time.RFC3339), scheduledTime.Format(time.RFC3339))
+	}
+	schedule.Status.LastScheduleTime = &metav1.Time{Time: *scheduledTime}
+
+	// skip this run while the previous checkpoint is still in progress
+	if len(schedule.Status.Active) != 0 {
+		util.UpdateCondition(c.clock, &schedule.Status.Conditions, metav1.ConditionFalse, v1alpha1.CheckpointScheduleScheduled, "PreviousRunInProgress", fmt.Sprintf("run at %s is skipped because checkpoint(%s) is still in progress", scheduledTime.Format(time.RFC3339), schedule.Status.Active))
+		return result, nil
+	}
+
+	pods, err := util.SelectRunningPods(ctx, c.Client, schedule.Namespace, schedule.Spec.OwnerRef, schedule.Spec.Selector)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if len(pods) == 0 {
+		util.UpdateCondition(c.clock, &schedule.Status.Conditions, metav1.ConditionFalse, v1alpha1.CheckpointScheduleScheduled, "PodNotSelected", fmt.Sprintf("there is no running pod selected for run at %s", scheduledTime.Format(time.RFC3339)))
+		return result, nil
+	} else if len(pods) > 1 {
+		util.UpdateCondition(c.clock, &schedule.Status.Conditions, metav1.ConditionFalse, v1alpha1.CheckpointScheduleScheduled, "MultiplePodsSelected", fmt.Sprintf("%d pods are selected for run at %s", len(pods), scheduledTime.Format(time.RFC3339)))
+		return result, nil
+	}
+
+	generation := schedule.Status.Generation + 1
+	ckpt := v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", schedule.Name, generation),
+			Namespace: schedule.Namespace,
+			Labels: map[string]string{
+				v1alpha1.CheckpointScheduleLabel: schedule.Name,
+			},
+		},
+		Spec: v1alpha1.CheckpointSpec{
+			PodName:         pods[0].Name,
+			VolumeClaim:     schedule.Spec.VolumeClaim,
+			ObjectStorage:   schedule.Spec.ObjectStorage,
+			Registry:        schedule.Spec.Registry,
+			Compression:     schedule.Spec.Compression,
+			Encryption:      schedule.Spec.Encryption,
+			RetentionPolicy: schedule.Spec.RetentionPolicy,
+		},
+	}
+	if err := controllerutil.SetControllerReference(schedule, &ckpt, c.Scheme()); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// checkpoint maybe has been created in the previous reconcile but status update failed, so adopt it.
+	if err := c.Create(ctx, &ckpt); client.IgnoreAlreadyExists(err) != nil {
+		return reconcile.Result{}, err
+	}
+	log.FromContext(ctx).Info("checkpoint is created by schedule", "namespace", schedule.Namespace, "schedule", schedule.Name, "checkpoint", ckpt.Name, "pod", ckpt.Spec.PodName)
+
+	schedule.Status.Generation = generation
+	schedule.Status.Active = ckpt.Name
+	util.UpdateCondition(c.clock, &schedule.Status.Conditions, metav1.ConditionTrue, v1alpha1.CheckpointScheduleScheduled, "CheckpointCreated", fmt.Sprintf("checkpoint(%s) is created for pod(%s)", ckpt.Name, ckpt.Spec.PodName))
+	return result, nil
+}
+
+// syncActiveCheckpoint is used for recording the result of in progress checkpoint. Active field will be cleared when the
+// checkpoint completed or failed, so the next run can be started.
+func (c *Controller) syncActiveCheckpoint(ctx context.Context, schedule *v1alpha1.CheckpointSchedule) error {
+	if len(schedule.Status.Active) == 0 {
+		return nil
+	}
+
+	var ckpt v1alpha1.Checkpoint
+	if err := c.Get(ctx, client.ObjectKey{Namespace: schedule.Namespace, Name: schedule.Status.Active}, &ckpt); err != nil {
+		if apierrors.IsNotFound(err) {
+			schedule.Status.Active = ""
+			return nil
+		}
+		return err
+	}
+
+	switch ckpt.Status.Phase {
+	case v1alpha1.Checkpointed:
+		schedule.Status.LastSuccessfulCheckpoint = ckpt.Name
+		schedule.Status.LastSuccessfulTime = util.CheckpointedTime(&ckpt)
+		schedule.Status.Active = ""
+	case v1alpha1.CheckpointFailed, v1alpha1.CheckpointCancelled:
+		schedule.Status.Active = ""
+	}
+	return nil
+}
+
+// mostRecentScheduleTime returns the latest scheduled time which is after earliestTime and not after now, and the number
+// of missed scheduled times. nil will be returned if there is no missed scheduled time.
+// like CronJob controller, at most maxMissedSchedules times are walked through with sched.Next, and maxMissedSchedules+1
+// is returned as the number of missed times when more are missed. then the most recent time is searched from a window
+// before now which is doubled until it covers a scheduled time, so the loop is bounded even if the schedule has not been
+// handled for a long time, and schedules with irregular intervals(like weekdays or months) are handled correctly.
+func mostRecentScheduleTime(sched cron.Schedule, earliestTime, now time.Time) (*time.Time, int) {
+	var scheduledTime *time.Time
+	missed := 0
+	for t := sched.Next(earliestTime); !t.After(now); t = sched.Next(t) {
+		if missed == maxMissedSchedules {
+			missed++
+			break
+		}
+		scheduledTime = lo.ToPtr(t)
+		missed++
+	}
+	if missed <= maxMissedSchedules {
+		return scheduledTime, missed
+	}
+
+	// the most recent time is after start, and the window is doubled until a scheduled time is found in it.
+	start := *scheduledTime
+	window := sched.Next(start).Sub(start)
+	for windowStart := now.Add(-window); windowStart.After(start); windowStart = now.Add(-window) {
+		if !sched.Next(windowStart).After(now) {
+			start = windowStart
+			break
+		}
+		window *= 2
+	}
+	for t := sched.Next(start); !t.After(now); t = sched.Next(t) {
+		scheduledTime = lo.ToPtr(t)
+	}
+	return scheduledTime, missed
+}
+
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpointschedules,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpointschedules/status,verbs=update
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=list;watch;get;create
+// +kubebuilder:rbac:groups="",resources=pods,verbs=list;watch
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("checkpointschedule.lifecycle").
+		For(&v1alpha1.CheckpointSchedule{}).
+		Owns(&v1alpha1.Checkpoint{}).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 3,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
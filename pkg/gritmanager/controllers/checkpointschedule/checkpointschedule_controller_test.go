@@ -0,0 +1,96 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpointschedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+func TestMostRecentScheduleTime(t *testing.T) {
+	sched, err := cron.ParseStandard("0 * * * *")
+	if err != nil {
+		t.Fatalf("failed to parse schedule, %v", err)
+	}
+	earliestTime := time.Date(2025, 3, 1, 10, 30, 0, 0, time.UTC)
+
+	t.Run("no scheduled time is missed", func(t *testing.T) {
+		now := time.Date(2025, 3, 1, 10, 59, 0, 0, time.UTC)
+		if scheduledTime, _ := mostRecentScheduleTime(sched, earliestTime, now); scheduledTime != nil {
+			t.Fatalf("expected no scheduled time, got %v", *scheduledTime)
+		}
+	})
+
+	t.Run("scheduled time is equal to now", func(t *testing.T) {
+		now := time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC)
+		scheduledTime, missed := mostRecentScheduleTime(sched, earliestTime, now)
+		if scheduledTime == nil || !scheduledTime.Equal(now) || missed != 1 {
+			t.Fatalf("expected scheduled time %v, got %v", now, scheduledTime)
+		}
+	})
+
+	t.Run("multiple scheduled times are missed", func(t *testing.T) {
+		now := time.Date(2025, 3, 1, 13, 20, 0, 0, time.UTC)
+		expected := time.Date(2025, 3, 1, 13, 0, 0, 0, time.UTC)
+		scheduledTime, missed := mostRecentScheduleTime(sched, earliestTime, now)
+		if scheduledTime == nil || !scheduledTime.Equal(expected) || missed != 3 {
+			t.Fatalf("expected scheduled time %v and 3 missed, got %v and %d missed", expected, scheduledTime, missed)
+		}
+	})
+
+	t.Run("too many scheduled times are missed", func(t *testing.T) {
+		now := time.Date(2025, 4, 1, 8, 20, 0, 0, time.UTC)
+		expected := time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC)
+		scheduledTime, missed := mostRecentScheduleTime(sched, earliestTime, now)
+		if scheduledTime == nil || !scheduledTime.Equal(expected) || missed <= maxMissedSchedules {
+			t.Fatalf("expected scheduled time %v and more than %d missed, got %v and %d missed", expected, maxMissedSchedules, scheduledTime, missed)
+		}
+	})
+
+	t.Run("too many scheduled times are missed with irregular intervals", func(t *testing.T) {
+		sched, err := cron.ParseStandard("30 6-16/4 * * 1-5")
+		if err != nil {
+			t.Fatalf("failed to parse schedule, %v", err)
+		}
+		now := time.Date(2025, 5, 5, 12, 0, 0, 0, time.UTC)
+		expected := time.Date(2025, 5, 5, 10, 30, 0, 0, time.UTC)
+		scheduledTime, missed := mostRecentScheduleTime(sched, earliestTime, now)
+		if scheduledTime == nil || !scheduledTime.Equal(expected) || missed <= maxMissedSchedules {
+			t.Fatalf("expected scheduled time %v and more than %d missed, got %v and %d missed", expected, maxMissedSchedules, scheduledTime, missed)
+		}
+	})
+
+	t.Run("too many scheduled times are missed with weekends and months", func(t *testing.T) {
+		testcases := map[string]struct {
+			schedule string
+			now      time.Time
+			expected time.Time
+		}{
+			"weekdays schedule on monday before the scheduled time": {
+				schedule: "0 9 * * 1-5",
+				now:      time.Date(2025, 9, 1, 8, 0, 0, 0, time.UTC),
+				expected: time.Date(2025, 8, 29, 9, 0, 0, 0, time.UTC),
+			},
+			"monthly schedule": {
+				schedule: "0 0 1 * *",
+				now:      time.Date(2035, 2, 28, 0, 0, 0, 0, time.UTC),
+				expected: time.Date(2035, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+		}
+		for name, tc := range testcases {
+			t.Run(name, func(t *testing.T) {
+				sched, err := cron.ParseStandard(tc.schedule)
+				if err != nil {
+					t.Fatalf("failed to parse schedule, %v", err)
+				}
+				scheduledTime, missed := mostRecentScheduleTime(sched, earliestTime, tc.now)
+				if scheduledTime == nil || !scheduledTime.Equal(tc.expected) || missed <= maxMissedSchedules {
+					t.Fatalf("expected scheduled time %v and more than %d missed, got %v and %d missed", tc.expected, maxMissedSchedules, scheduledTime, missed)
+				}
+			})
+		}
+	})
+}
@@ -11,7 +11,15 @@ import (
 	"github.com/kaito-project/grit/cmd/grit-manager/app/options"
 	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/checkpoint"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/checkpointgroup"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/checkpointrequest"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/checkpointschedule"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/migration"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/nodemigration"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/preemption"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/restore"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/restoregroup"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/retention"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/secret"
 )
 
@@ -22,7 +30,15 @@ func NewControllers(mgr manager.Manager, clock clock.Clock, opts *options.GritMa
 
 	return []controller.Controller{
 		secret.NewController(clock, mgr.GetClient(), opts.WorkingNamespace, opts.WebhookSecretName, opts.WebhookServiceName, opts.ExpirationDuration),
-		checkpoint.NewController(clock, mgr.GetClient(), agentManager),
-		restore.NewController(clock, mgr.GetClient(), agentManager),
+		checkpoint.NewController(clock, mgr.GetClient(), agentManager, mgr.GetEventRecorderFor("grit-manager")),
+		restore.NewController(clock, mgr.GetClient(), agentManager, mgr.GetEventRecorderFor("grit-manager")),
+		checkpointschedule.NewController(clock, mgr.GetClient(), mgr.GetEventRecorderFor("grit-manager")),
+		retention.NewController(clock, mgr.GetClient()),
+		checkpointgroup.NewController(clock, mgr.GetClient()),
+		restoregroup.NewController(clock, mgr.GetClient()),
+		migration.NewController(clock, mgr.GetClient(), mgr.GetEventRecorderFor("grit-manager")),
+		nodemigration.NewController(clock, mgr.GetClient()),
+		checkpointrequest.NewController(clock, mgr.GetClient()),
+		preemption.NewController(clock, mgr.GetClient(), opts.PreemptionTaints, opts.PreemptionConditions, opts.PreemptionCheckpointDeadline),
 	}
 }
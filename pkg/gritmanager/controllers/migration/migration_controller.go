@@ -0,0 +1,407 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package migration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+	"github.com/kaito-project/grit/pkg/gritmanager/metrics"
+)
+
+const (
+	defaultBackoffLimit = 3
+)
+
+type MigrationStateHandler func(ctx context.Context, migration *v1alpha1.Migration) error
+
+type Controller struct {
+	client.Client
+	clock         clock.Clock
+	recorder      record.EventRecorder
+	statesMachine map[v1alpha1.MigrationPhase]MigrationStateHandler
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, recorder record.EventRecorder) *Controller {
+	c := &Controller{
+		clock:    clk,
+		Client:   kubeClient,
+		recorder: recorder,
+	}
+
+	// v1alpha1.Migrated, v1alpha1.MigrationFailed,
+	// these two states, girt-manager don't need to do anything.
+	c.statesMachine = map[v1alpha1.MigrationPhase]MigrationStateHandler{
+		v1alpha1.MigrationCreated:       c.createdHandler,
+		v1alpha1.MigrationCheckpointing: c.checkpointingHandler,
+		v1alpha1.MigrationRemovingPod:   c.removingPodHandler,
+		v1alpha1.MigrationRestoring:     c.restoringHandler,
+	}
+
+	return c
+}
+
+// Reconcile drives the whole migration flow: checkpoint the pod, remove it so its owner recreates a new one, then restore
+// the new pod from checkpointed data. Checkpoint and Restore are created as children of Migration.
+func (c *Controller) Reconcile(ctx context.Context, migration *v1alpha1.Migration) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "migration.lifecycle")
+
+	updatedMigration := migration.DeepCopy()
+	phase := updatedMigration.Status.Phase
+	if phase == "" {
+		phase = v1alpha1.MigrationCreated
+	}
+	stateHandler, ok := c.statesMachine[phase]
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+
+	if err := stateHandler(ctx, updatedMigration); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(migration, updatedMigration) {
+		if err := c.Status().Update(ctx, updatedMigration); err != nil {
+			return reconcile.Result{}, err
+		}
+		metrics.RecordMigrationPhase(migration, updatedMigration, c.clock.Now())
+		if phase := updatedMigration.Status.Phase; phase != migration.Status.Phase {
+			eventType, reason, message := util.PhaseEvent(updatedMigration.Status.Conditions, string(phase), phase == v1alpha1.MigrationFailed)
+			c.recorder.Event(updatedMigration, eventType, reason, message)
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// createdHandler is used for recording source node and owner of the pod, then upgraded state to Checkpointing.
+func (c *Controller) createdHandler(ctx context.Context, migration *v1alpha1.Migration) error {
+	if migration.Status.Phase == "" {
+		migration.Status.Phase = v1alpha1.MigrationCreated
+		util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationCreated), "MigrationIsCreated", "migration resource is created")
+		return nil
+	}
+
+	var pod corev1.Pod
+	if err := c.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Spec.PodName}, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			migration.Status.Phase = v1alpha1.MigrationFailed
+			util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationFailed), "PodNotExist", fmt.Sprintf("pod(%s) for migration doesn't exist", migration.Spec.PodName))
+			return nil
+		}
+		return err
+	}
+
+	// new pod is recreated by the owner after checkpointed pod is removed, so pod without owner can't be migrated.
+	ownerRef := metav1.GetControllerOf(&pod)
+	if ownerRef == nil {
+		migration.Status.Phase = v1alpha1.MigrationFailed
+		util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationFailed), "PodHasNoOwnerReference", fmt.Sprintf("pod(%s) for migration has no owner reference", pod.Name))
+		return nil
+	}
+
+	migration.Status.SourceNodeName = pod.Spec.NodeName
+	migration.Status.OwnerRef = ownerRef
+	migration.Status.Phase = v1alpha1.MigrationCheckpointing
+	util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationCheckpointing), "CheckpointingStarted", fmt.Sprintf("start to checkpoint pod(%s) on node(%s)", pod.Name, pod.Spec.NodeName))
+	return nil
+}
+
+// checkpointingHandler is used for creating Checkpoint and waiting it checkpointed. failed Checkpoint is kept for
+// troubleshooting, and a new Checkpoint is created for retrying.
+func (c *Controller) checkpointingHandler(ctx context.Context, migration *v1alpha1.Migration) error {
+	if len(migration.Status.CheckpointName) == 0 {
+		return c.createCheckpoint(ctx, migration)
+	}
+
+	var ckpt v1alpha1.Checkpoint
+	if err := c.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Status.CheckpointName}, &ckpt); err != nil {
+		if apierrors.IsNotFound(err) {
+			c.retryStep(migration, v1alpha1.MigrationCheckpointing, "CheckpointNotExist", fmt.Sprintf("checkpoint(%s) doesn't exist", migration.Status.CheckpointName))
+			return nil
+		}
+		return err
+	}
+
+	switch ckpt.Status.Phase {
+	case v1alpha1.Checkpointed:
+		// the pod keeps running after a snapshot, and its progress since the dump would be lost if it's removed.
+		if ckpt.Spec.Mode == v1alpha1.CheckpointModeSnapshot {
+			migration.Status.Phase = v1alpha1.MigrationFailed
+			util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationFailed), "SnapshotCheckpoint", fmt.Sprintf("checkpoint(%s) is a snapshot, pod(%s) can't be migrated by it", ckpt.Name, migration.Spec.PodName))
+			return nil
+		}
+		migration.Status.Phase = v1alpha1.MigrationRemovingPod
+		util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationRemovingPod), "CheckpointCompleted", fmt.Sprintf("checkpoint(%s) is checkpointed, start to remove pod(%s)", ckpt.Name, migration.Spec.PodName))
+	case v1alpha1.CheckpointFailed:
+		c.retryStep(migration, v1alpha1.MigrationCheckpointing, "CheckpointFailed", fmt.Sprintf("checkpoint(%s) failed", ckpt.Name))
+	case v1alpha1.CheckpointCancelled:
+		// checkpoint is cancelled by user, so it's not retried and the pod keeps running on the node.
+		migration.Status.Phase = v1alpha1.MigrationFailed
+		util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationFailed), "CheckpointCancelled", fmt.Sprintf("checkpoint(%s) is cancelled", ckpt.Name))
+	}
+	return nil
+}
+
+func (c *Controller) createCheckpoint(ctx context.Context, migration *v1alpha1.Migration) error {
+	var pod corev1.Pod
+	if err := c.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Spec.PodName}, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			migration.Status.Phase = v1alpha1.MigrationFailed
+			util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationFailed), "PodNotExist", fmt.Sprintf("pod(%s) for migration doesn't exist", migration.Spec.PodName))
+			return nil
+		}
+		return err
+	}
+
+	if pod.Status.Phase != corev1.PodRunning {
+		migration.Status.Phase = v1alpha1.MigrationFailed
+		util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationFailed), "PodNotRunning", fmt.Sprintf("pod(%s) for migration is not running", pod.Name))
+		return nil
+	}
+
+	ckpt := v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      childName(migration),
+			Namespace: migration.Namespace,
+			Labels: map[string]string{
+				v1alpha1.MigrationLabel: migration.Name,
+			},
+		},
+		Spec: v1alpha1.CheckpointSpec{
+			PodName: migration.Spec.PodName,
+			// pod is removed after it's checkpointed, so it should be stopped for not losing any progress after dump.
+			Mode:          v1alpha1.CheckpointModeStop,
+			VolumeClaim:   migration.Spec.VolumeClaim,
+			ObjectStorage: migration.Spec.ObjectStorage,
+			Registry:      migration.Spec.Registry,
+			Compression:   migration.Spec.Compression,
+			Encryption:    migration.Spec.Encryption,
+		},
+	}
+	if err := controllerutil.SetControllerReference(migration, &ckpt, c.Scheme()); err != nil {
+		return err
+	}
+
+	// checkpoint maybe has been created in the previous reconcile but status update failed, so adopt it.
+	if err := c.Create(ctx, &ckpt); client.IgnoreAlreadyExists(err) != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("checkpoint is created for migration", "namespace", migration.Namespace, "migration", migration.Name, "checkpoint", ckpt.Name)
+
+	migration.Status.CheckpointName = ckpt.Name
+	return nil
+}
+
+// removingPodHandler is used for creating Restore and removing checkpointed pod. Restore is created before removing pod,
+// so the new pod created by the owner can be selected as restoration pod.
+func (c *Controller) removingPodHandler(ctx context.Context, migration *v1alpha1.Migration) error {
+	if len(migration.Status.RestoreName) == 0 {
+		if err := c.createRestore(ctx, migration); err != nil {
+			return err
+		}
+	}
+
+	var pod corev1.Pod
+	if err := c.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Spec.PodName}, &pod); client.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil && pod.DeletionTimestamp.IsZero() {
+		if migration.Spec.PodRemovalPolicy == v1alpha1.PodRemovalEvict {
+			eviction := &policyv1.Eviction{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: pod.Namespace,
+					Name:      pod.Name,
+				},
+			}
+			// eviction maybe is blocked by PodDisruptionBudget, return error for retrying later.
+			if err := c.SubResource("eviction").Create(ctx, &pod, eviction); client.IgnoreNotFound(err) != nil {
+				return fmt.Errorf("failed to evict pod(%s/%s), %w", pod.Namespace, pod.Name, err)
+			}
+			c.recorder.Eventf(&pod, corev1.EventTypeNormal, "EvictedForMigration", "pod is evicted by migration(%s), and it will be restored from checkpoint(%s)", migration.Name, migration.Status.CheckpointName)
+		} else if err := c.Delete(ctx, &pod); client.IgnoreNotFound(err) != nil {
+			return err
+		} else {
+			c.recorder.Eventf(&pod, corev1.EventTypeNormal, "DeletedForMigration", "pod is deleted by migration(%s), and it will be restored from checkpoint(%s)", migration.Name, migration.Status.CheckpointName)
+		}
+	}
+
+	migration.Status.Phase = v1alpha1.MigrationRestoring
+	util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationRestoring), "PodRemoved", fmt.Sprintf("pod(%s) is removed, wait for restoration pod restored", migration.Spec.PodName))
+	return nil
+}
+
+func (c *Controller) createRestore(ctx context.Context, migration *v1alpha1.Migration) error {
+	restore := v1alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      childName(migration),
+			Namespace: migration.Namespace,
+			Labels: map[string]string{
+				v1alpha1.MigrationLabel: migration.Name,
+			},
+		},
+		Spec: v1alpha1.RestoreSpec{
+			CheckpointName: migration.Status.CheckpointName,
+			OwnerRef:       *migration.Status.OwnerRef,
+			TargetNodeName: migration.Spec.TargetNodeName,
+			NodeSelector:   migration.Spec.NodeSelector,
+		},
+	}
+	if err := controllerutil.SetControllerReference(migration, &restore, c.Scheme()); err != nil {
+		return err
+	}
+
+	// restore maybe has been created in the previous reconcile but status update failed, so adopt it.
+	if err := c.Create(ctx, &restore); client.IgnoreAlreadyExists(err) != nil {
+		return err
+	}
+	log.FromContext(ctx).Info("restore is created for migration", "namespace", migration.Namespace, "migration", migration.Name, "restore", restore.Name)
+
+	migration.Status.RestoreName = restore.Name
+	return nil
+}
+
+// restoringHandler is used for waiting Restore restored, and recording downtime of migration. when Restore failed,
+// a new Restore is created and the failed restoration pod is removed, then the next pod recreated by the owner will be restored.
+func (c *Controller) restoringHandler(ctx context.Context, migration *v1alpha1.Migration) error {
+	if len(migration.Status.RestoreName) == 0 {
+		return c.createRestore(ctx, migration)
+	}
+
+	var restore v1alpha1.Restore
+	if err := c.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Status.RestoreName}, &restore); err != nil {
+		if apierrors.IsNotFound(err) {
+			c.retryStep(migration, v1alpha1.MigrationRestoring, "RestoreNotExist", fmt.Sprintf("restore(%s) doesn't exist", migration.Status.RestoreName))
+			return nil
+		}
+		return err
+	}
+	migration.Status.TargetPod = restore.Status.TargetPod
+	migration.Status.TargetNodeName = restore.Status.NodeName
+
+	switch restore.Status.Phase {
+	case v1alpha1.Restored:
+		downtime, err := c.resolveDowntime(ctx, migration, &restore)
+		if err != nil {
+			return err
+		}
+		migration.Status.Downtime = downtime
+		migration.Status.Phase = v1alpha1.Migrated
+		util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.Migrated), "RestoreCompleted", fmt.Sprintf("pod(%s) is restored on node(%s)", restore.Status.TargetPod, restore.Status.NodeName))
+	case v1alpha1.RestoreFailed:
+		c.retryStep(migration, v1alpha1.MigrationRestoring, "RestoreFailed", fmt.Sprintf("restore(%s) failed", restore.Name))
+		if migration.Status.Phase == v1alpha1.MigrationFailed || len(restore.Status.TargetPod) == 0 {
+			return nil
+		}
+
+		// new Restore should be created before removing failed restoration pod, otherwise the pod recreated by the owner
+		// maybe is not selected as restoration pod.
+		if err := c.createRestore(ctx, migration); err != nil {
+			return err
+		}
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: restore.Namespace,
+				Name:      restore.Status.TargetPod,
+			},
+		}
+		if err := c.Delete(ctx, &pod); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		c.recorder.Eventf(&pod, corev1.EventTypeWarning, "RestorationPodRemoved", "pod is deleted by migration(%s) because restore(%s) failed, and the next pod will be restored by restore(%s)", migration.Name, restore.Name, migration.Status.RestoreName)
+	}
+	return nil
+}
+
+// resolveDowntime returns the duration from checkpointing pod started to restoration pod running.
+func (c *Controller) resolveDowntime(ctx context.Context, migration *v1alpha1.Migration, restore *v1alpha1.Restore) (*metav1.Duration, error) {
+	var ckpt v1alpha1.Checkpoint
+	if err := c.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Status.CheckpointName}, &ckpt); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	startCond := meta.FindStatusCondition(ckpt.Status.Conditions, string(v1alpha1.Checkpointing))
+	endCond := meta.FindStatusCondition(restore.Status.Conditions, string(v1alpha1.Restored))
+	if startCond == nil || endCond == nil {
+		return nil, nil
+	}
+	return &metav1.Duration{Duration: endCond.LastTransitionTime.Sub(startCond.LastTransitionTime.Time)}, nil
+}
+
+// retryStep is used for retrying the failed step until backoff limit is reached, the condition of this step is set to false
+// for recording the failure. when backoff limit is reached, migration fails.
+func (c *Controller) retryStep(migration *v1alpha1.Migration, step v1alpha1.MigrationPhase, reason, message string) {
+	backoffLimit := int32(defaultBackoffLimit)
+	if migration.Spec.BackoffLimit != nil {
+		backoffLimit = *migration.Spec.BackoffLimit
+	}
+
+	if migration.Status.Retries >= backoffLimit {
+		migration.Status.Phase = v1alpha1.MigrationFailed
+		util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.MigrationFailed), reason, fmt.Sprintf("%s, backoff limit(%d) is reached", message, backoffLimit))
+		return
+	}
+
+	migration.Status.Retries++
+	switch step {
+	case v1alpha1.MigrationCheckpointing:
+		migration.Status.CheckpointName = ""
+	case v1alpha1.MigrationRestoring:
+		migration.Status.RestoreName = ""
+	}
+	util.UpdateCondition(c.clock, &migration.Status.Conditions, metav1.ConditionFalse, string(step), reason, fmt.Sprintf("%s, retry %d/%d", message, migration.Status.Retries, backoffLimit))
+}
+
+// childName returns name of Checkpoint or Restore for the current attempt.
+func childName(migration *v1alpha1.Migration) string {
+	if migration.Status.Retries == 0 {
+		return migration.Name
+	}
+	return fmt.Sprintf("%s-%d", migration.Name, migration.Status.Retries)
+}
+
+// +kubebuilder:rbac:groups=kaito.sh,resources=migrations,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=migrations/status,verbs=update
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=list;watch;get;create
+// +kubebuilder:rbac:groups=kaito.sh,resources=restores,verbs=list;watch;get;create
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;delete
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("migration.lifecycle").
+		For(&v1alpha1.Migration{}).
+		Owns(&v1alpha1.Checkpoint{}).
+		Owns(&v1alpha1.Restore{}).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 5,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
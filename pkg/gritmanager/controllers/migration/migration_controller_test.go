@@ -0,0 +1,155 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package migration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+func TestMigrationFlow(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "pod",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "rs-uid", Controller: lo.ToPtr(true)},
+			},
+		},
+		Spec:   corev1.PodSpec{NodeName: "node-1"},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	migration := &v1alpha1.Migration{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "migration", UID: "migration-uid"},
+		Spec:       v1alpha1.MigrationSpec{PodName: pod.Name},
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(pod, migration).
+		WithStatusSubresource(&v1alpha1.Migration{}, &v1alpha1.Checkpoint{}).
+		Build()
+	c := NewController(clock.NewFakeClock(time.Now()), kubeClient, record.NewFakeRecorder(100))
+
+	reconcileUntil := func(phase v1alpha1.MigrationPhase) {
+		t.Helper()
+		for i := 0; i < 5; i++ {
+			if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(migration), migration); err != nil {
+				t.Fatal(err)
+			}
+			if migration.Status.Phase == phase {
+				return
+			}
+			if _, err := c.Reconcile(ctx, migration); err != nil {
+				t.Fatalf("failed to reconcile migration, %v", err)
+			}
+		}
+		t.Fatalf("expected migration is %s, got %s", phase, migration.Status.Phase)
+	}
+
+	reconcileUntil(v1alpha1.MigrationCheckpointing)
+	if migration.Status.SourceNodeName != "node-1" || migration.Status.OwnerRef == nil || migration.Status.OwnerRef.Kind != "ReplicaSet" {
+		t.Fatalf("expected source node and owner of pod are recorded, got %+v", migration.Status)
+	}
+	if _, err := c.Reconcile(ctx, migration); err != nil {
+		t.Fatal(err)
+	}
+
+	var ckpt v1alpha1.Checkpoint
+	if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "migration"}, &ckpt); err != nil {
+		t.Fatalf("expected checkpoint is created, got %v", err)
+	}
+	if ckpt.Spec.Mode != v1alpha1.CheckpointModeStop || ckpt.Labels[v1alpha1.MigrationLabel] != migration.Name {
+		t.Errorf("unexpected checkpoint %+v", ckpt)
+	}
+	ckpt.Status.Phase = v1alpha1.Checkpointed
+	if err := kubeClient.Status().Update(ctx, &ckpt); err != nil {
+		t.Fatal(err)
+	}
+
+	reconcileUntil(v1alpha1.MigrationRestoring)
+	var restore v1alpha1.Restore
+	if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: migration.Status.RestoreName}, &restore); err != nil {
+		t.Fatalf("expected restore is created, got %v", err)
+	}
+	if restore.Spec.CheckpointName != ckpt.Name || restore.Spec.OwnerRef.Kind != "ReplicaSet" {
+		t.Errorf("unexpected restore %+v", restore.Spec)
+	}
+	if err := kubeClient.Get(ctx, client.ObjectKeyFromObject(pod), &corev1.Pod{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected checkpointed pod is removed, got %v", err)
+	}
+
+	// checkpoint and restore of migration have the same name, and their grit agent jobs should not collide.
+	if ckptJob, restoreJob := util.GritAgentJobName(&ckpt, nil), util.GritAgentJobName(nil, &restore); ckptJob == restoreJob {
+		t.Errorf("expected different grit agent jobs for checkpoint and restore, both are %s", ckptJob)
+	}
+}
+
+func TestRetryStep(t *testing.T) {
+	testcases := map[string]struct {
+		status          v1alpha1.MigrationStatus
+		backoffLimit    *int32
+		expectedPhase   v1alpha1.MigrationPhase
+		expectedRetries int32
+		expectedRestore string
+		expectedChild   string
+	}{
+		"failed restore is retried with a new child": {
+			status:          v1alpha1.MigrationStatus{Phase: v1alpha1.MigrationRestoring, RestoreName: "migration"},
+			expectedPhase:   v1alpha1.MigrationRestoring,
+			expectedRetries: 1,
+			expectedChild:   "migration-1",
+		},
+		"migration fails when backoff limit is reached": {
+			status:          v1alpha1.MigrationStatus{Phase: v1alpha1.MigrationRestoring, RestoreName: "migration-1", Retries: 1},
+			backoffLimit:    lo.ToPtr[int32](1),
+			expectedPhase:   v1alpha1.MigrationFailed,
+			expectedRetries: 1,
+			expectedRestore: "migration-1",
+			expectedChild:   "migration-1",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			c := NewController(clock.NewFakeClock(time.Now()), nil, record.NewFakeRecorder(10))
+			migration := &v1alpha1.Migration{
+				ObjectMeta: metav1.ObjectMeta{Name: "migration"},
+				Spec:       v1alpha1.MigrationSpec{BackoffLimit: tc.backoffLimit},
+				Status:     tc.status,
+			}
+			c.retryStep(migration, v1alpha1.MigrationRestoring, "RestoreFailed", "restore failed")
+
+			if migration.Status.Phase != tc.expectedPhase || migration.Status.Retries != tc.expectedRetries || migration.Status.RestoreName != tc.expectedRestore {
+				t.Errorf("unexpected status %+v", migration.Status)
+			}
+			if name := childName(migration); name != tc.expectedChild {
+				t.Errorf("expected child name %s, got %s", tc.expectedChild, name)
+			}
+		})
+	}
+}
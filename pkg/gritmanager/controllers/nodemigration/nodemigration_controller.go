@@ -0,0 +1,343 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package nodemigration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+const (
+	defaultRuntimeClassName = "grit"
+	defaultParallelism      = 1
+)
+
+type NodeMigrationStateHandler func(ctx context.Context, nm *v1alpha1.NodeMigration) error
+
+type Controller struct {
+	client.Client
+	clock         clock.Clock
+	statesMachine map[v1alpha1.NodeMigrationPhase]NodeMigrationStateHandler
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client) *Controller {
+	c := &Controller{
+		clock:  clk,
+		Client: kubeClient,
+	}
+
+	// v1alpha1.NodeMigrationCompleted, v1alpha1.NodeMigrationFailed,
+	// these two states, girt-manager don't need to do anything.
+	c.statesMachine = map[v1alpha1.NodeMigrationPhase]NodeMigrationStateHandler{
+		v1alpha1.NodeMigrationCreated:   c.createdHandler,
+		v1alpha1.NodeMigrationMigrating: c.migratingHandler,
+	}
+
+	return c
+}
+
+// Reconcile evacuates GRIT enabled pods from a node: cordon the node, then create a Migration for each selected pod with
+// bounded parallelism, and uncordon the node by NodePolicy after all Migrations are finished.
+func (c *Controller) Reconcile(ctx context.Context, nm *v1alpha1.NodeMigration) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "nodemigration.lifecycle")
+
+	updatedNM := nm.DeepCopy()
+	phase := updatedNM.Status.Phase
+	if phase == "" {
+		phase = v1alpha1.NodeMigrationCreated
+	}
+	stateHandler, ok := c.statesMachine[phase]
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+
+	if err := stateHandler(ctx, updatedNM); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(nm, updatedNM) {
+		return reconcile.Result{}, c.Status().Update(ctx, updatedNM)
+	}
+	return reconcile.Result{}, nil
+}
+
+// createdHandler is used for cordoning the node and selecting pods on the node, then upgraded state to Migrating.
+func (c *Controller) createdHandler(ctx context.Context, nm *v1alpha1.NodeMigration) error {
+	if nm.Status.Phase == "" {
+		nm.Status.Phase = v1alpha1.NodeMigrationCreated
+		util.UpdateCondition(c.clock, &nm.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.NodeMigrationCreated), "NodeMigrationIsCreated", "node migration resource is created")
+		return nil
+	}
+
+	var node corev1.Node
+	if err := c.Get(ctx, client.ObjectKey{Name: nm.Spec.NodeName}, &node); err != nil {
+		if apierrors.IsNotFound(err) {
+			nm.Status.Phase = v1alpha1.NodeMigrationFailed
+			util.UpdateCondition(c.clock, &nm.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.NodeMigrationFailed), "NodeNotExist", fmt.Sprintf("node(%s) for migration doesn't exist", nm.Spec.NodeName))
+			return nil
+		}
+		return err
+	}
+
+	// new pods should not be scheduled to the node, include pods recreated by owners of migrated pods.
+	if !node.Spec.Unschedulable {
+		// record it before cordoning, so node is still uncordoned by NodePolicy if status update fails after cordoning.
+		if !nm.Status.Cordoned {
+			nm.Status.Cordoned = true
+			return nil
+		}
+		if err := c.setUnschedulable(ctx, &node, true); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("node is cordoned for migration", "node", node.Name, "nodemigration", nm.Name)
+	}
+
+	var podList corev1.PodList
+	if err := c.List(ctx, &podList, client.MatchingFields{util.PodNodeNameField: nm.Spec.NodeName}); err != nil {
+		return err
+	}
+	pods, err := selectPods(nm, podList.Items)
+	if err != nil {
+		return err
+	}
+
+	nm.Status.Pods = pods
+	nm.Status.Phase = v1alpha1.NodeMigrationMigrating
+	util.UpdateCondition(c.clock, &nm.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.NodeMigrationMigrating), "NodeCordoned", fmt.Sprintf("node(%s) is cordoned, start to migrate %d pods", node.Name, len(pods)))
+	return nil
+}
+
+// migratingHandler is used for tracking Migrations of pods and creating Migrations for pending pods, the number of
+// in-flight Migrations is bounded by parallelism. when all pods are finished, node is uncordoned by NodePolicy.
+func (c *Controller) migratingHandler(ctx context.Context, nm *v1alpha1.NodeMigration) error {
+	for i := range nm.Status.Pods {
+		if nm.Status.Pods[i].Phase == v1alpha1.PodMigrationMigrating {
+			if err := c.trackMigration(ctx, &nm.Status.Pods[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	parallelism := int32(defaultParallelism)
+	if nm.Spec.Parallelism > 0 {
+		parallelism = nm.Spec.Parallelism
+	}
+	inflight := countPods(nm.Status.Pods, v1alpha1.PodMigrationMigrating)
+	for i := range nm.Status.Pods {
+		if inflight >= parallelism {
+			break
+		}
+		if nm.Status.Pods[i].Phase != v1alpha1.PodMigrationPending {
+			continue
+		}
+		if err := c.createMigration(ctx, nm, &nm.Status.Pods[i]); err != nil {
+			return err
+		}
+		if nm.Status.Pods[i].Phase == v1alpha1.PodMigrationMigrating {
+			inflight++
+		}
+	}
+
+	nm.Status.MigratedPods = countPods(nm.Status.Pods, v1alpha1.PodMigrationMigrated)
+	nm.Status.FailedPods = countPods(nm.Status.Pods, v1alpha1.PodMigrationFailed) + countPods(nm.Status.Pods, v1alpha1.PodMigrationSkipped)
+	if inflight != 0 || countPods(nm.Status.Pods, v1alpha1.PodMigrationPending) != 0 {
+		return nil
+	}
+
+	if nm.Spec.NodePolicy == v1alpha1.NodePolicyUncordon && nm.Status.Cordoned {
+		var node corev1.Node
+		if err := c.Get(ctx, client.ObjectKey{Name: nm.Spec.NodeName}, &node); client.IgnoreNotFound(err) != nil {
+			return err
+		} else if err == nil && node.Spec.Unschedulable {
+			if err := c.setUnschedulable(ctx, &node, false); err != nil {
+				return err
+			}
+			log.FromContext(ctx).Info("node is uncordoned after migration", "node", node.Name, "nodemigration", nm.Name)
+		}
+	}
+
+	nm.Status.Phase = v1alpha1.NodeMigrationCompleted
+	util.UpdateCondition(c.clock, &nm.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.NodeMigrationCompleted), "AllPodsFinished", fmt.Sprintf("%d pods are migrated, %d pods can't be migrated", nm.Status.MigratedPods, nm.Status.FailedPods))
+	return nil
+}
+
+// trackMigration syncs the phase of pod with its Migration, and the failure reason of Migration is recorded for failed pod.
+func (c *Controller) trackMigration(ctx context.Context, pod *v1alpha1.PodMigrationStatus) error {
+	var migration v1alpha1.Migration
+	if err := c.Get(ctx, client.ObjectKey{Namespace: pod.Namespace, Name: pod.MigrationName}, &migration); err != nil {
+		if apierrors.IsNotFound(err) {
+			setPodPhase(pod, v1alpha1.PodMigrationFailed, "MigrationNotExist", fmt.Sprintf("migration(%s) doesn't exist", pod.MigrationName))
+			return nil
+		}
+		return err
+	}
+
+	switch migration.Status.Phase {
+	case v1alpha1.Migrated:
+		setPodPhase(pod, v1alpha1.PodMigrationMigrated, "", "")
+	case v1alpha1.MigrationFailed:
+		reason, message := "MigrationFailed", fmt.Sprintf("migration(%s) failed", migration.Name)
+		if cond := meta.FindStatusCondition(migration.Status.Conditions, string(v1alpha1.MigrationFailed)); cond != nil {
+			reason, message = cond.Reason, cond.Message
+		}
+		setPodPhase(pod, v1alpha1.PodMigrationFailed, reason, message)
+	}
+	return nil
+}
+
+func (c *Controller) createMigration(ctx context.Context, nm *v1alpha1.NodeMigration, pod *v1alpha1.PodMigrationStatus) error {
+	migration := v1alpha1.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", nm.Name, pod.Name),
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				v1alpha1.NodeMigrationLabel: nm.Name,
+			},
+		},
+		Spec: v1alpha1.MigrationSpec{
+			PodName:          pod.Name,
+			VolumeClaim:      nm.Spec.VolumeClaim,
+			ObjectStorage:    nm.Spec.ObjectStorage,
+			Registry:         nm.Spec.Registry,
+			Compression:      nm.Spec.Compression,
+			Encryption:       nm.Spec.Encryption,
+			NodeSelector:     nm.Spec.NodeSelector,
+			PodRemovalPolicy: nm.Spec.PodRemovalPolicy,
+			BackoffLimit:     nm.Spec.BackoffLimit,
+		},
+	}
+	if err := controllerutil.SetControllerReference(nm, &migration, c.Scheme()); err != nil {
+		return err
+	}
+
+	// migration maybe has been created in the previous reconcile but status update failed, so adopt it.
+	if err := c.Create(ctx, &migration); client.IgnoreAlreadyExists(err) != nil {
+		// migration is rejected by webhook when pod is gone or not running, or storage is invalid in the namespace of pod.
+		if apierrors.IsForbidden(err) || apierrors.IsInvalid(err) || apierrors.IsNotFound(err) {
+			setPodPhase(pod, v1alpha1.PodMigrationFailed, "MigrationNotCreated", err.Error())
+			return nil
+		}
+		return err
+	}
+	log.FromContext(ctx).Info("migration is created for node migration", "namespace", pod.Namespace, "nodemigration", nm.Name, "migration", migration.Name)
+
+	pod.MigrationName = migration.Name
+	setPodPhase(pod, v1alpha1.PodMigrationMigrating, "", "")
+	return nil
+}
+
+func (c *Controller) setUnschedulable(ctx context.Context, node *corev1.Node, unschedulable bool) error {
+	patch := client.MergeFrom(node.DeepCopy())
+	node.Spec.Unschedulable = unschedulable
+	return c.Patch(ctx, node, patch)
+}
+
+// selectPods selects pods on the node by runtime class and selector of NodeMigration, and pods which can't be migrated
+// are recorded as skipped with the reason. pods are sorted by namespace and name, so they are migrated in a stable order.
+func selectPods(nm *v1alpha1.NodeMigration, pods []corev1.Pod) ([]v1alpha1.PodMigrationStatus, error) {
+	runtimeClassName := nm.Spec.RuntimeClassName
+	if len(runtimeClassName) == 0 {
+		runtimeClassName = defaultRuntimeClassName
+	}
+	selector := labels.Everything()
+	if nm.Spec.Selector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(nm.Spec.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	var selected []v1alpha1.PodMigrationStatus
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName != nm.Spec.NodeName || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed ||
+			!pod.DeletionTimestamp.IsZero() || pod.Spec.RuntimeClassName == nil || *pod.Spec.RuntimeClassName != runtimeClassName ||
+			!selector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+
+		status := v1alpha1.PodMigrationStatus{
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+			Phase:     v1alpha1.PodMigrationPending,
+		}
+		ownerRef := metav1.GetControllerOf(pod)
+		switch {
+		case ownerRef == nil:
+			setPodPhase(&status, v1alpha1.PodMigrationSkipped, "PodHasNoOwnerReference", "pod has no owner reference, so it can't be recreated on other nodes")
+		case ownerRef.Kind == "DaemonSet":
+			setPodPhase(&status, v1alpha1.PodMigrationSkipped, "PodOwnedByDaemonSet", "pod of daemonset is bound to the node")
+		case pod.Status.Phase != corev1.PodRunning:
+			setPodPhase(&status, v1alpha1.PodMigrationSkipped, "PodNotRunning", fmt.Sprintf("pod is %s", pod.Status.Phase))
+		}
+		selected = append(selected, status)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		if selected[i].Namespace != selected[j].Namespace {
+			return selected[i].Namespace < selected[j].Namespace
+		}
+		return selected[i].Name < selected[j].Name
+	})
+	return selected, nil
+}
+
+func setPodPhase(pod *v1alpha1.PodMigrationStatus, phase v1alpha1.PodMigrationPhase, reason, message string) {
+	pod.Phase = phase
+	pod.Reason = reason
+	pod.Message = message
+}
+
+func countPods(pods []v1alpha1.PodMigrationStatus, phase v1alpha1.PodMigrationPhase) int32 {
+	var count int32
+	for i := range pods {
+		if pods[i].Phase == phase {
+			count++
+		}
+	}
+	return count
+}
+
+// +kubebuilder:rbac:groups=kaito.sh,resources=nodemigrations,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=nodemigrations/status,verbs=update
+// +kubebuilder:rbac:groups=kaito.sh,resources=migrations,verbs=list;watch;get;create
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;patch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("nodemigration.lifecycle").
+		For(&v1alpha1.NodeMigration{}).
+		Owns(&v1alpha1.Migration{}).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 5,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package nodemigration
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+func TestSelectPods(t *testing.T) {
+	newPod := func(namespace, name, nodeName, runtimeClass, ownerKind string, phase corev1.PodPhase) corev1.Pod {
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: map[string]string{"app": name}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		if len(runtimeClass) != 0 {
+			pod.Spec.RuntimeClassName = ptr.To(runtimeClass)
+		}
+		if len(ownerKind) != 0 {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: "owner", Controller: ptr.To(true)}}
+		}
+		return pod
+	}
+	pods := []corev1.Pod{
+		newPod("ns-b", "running", "node1", "grit", "ReplicaSet", corev1.PodRunning),
+		newPod("ns-a", "job", "node1", "grit", "Job", corev1.PodRunning),
+		newPod("ns-a", "orphan", "node1", "grit", "", corev1.PodRunning),
+		newPod("ns-a", "daemon", "node1", "grit", "DaemonSet", corev1.PodRunning),
+		newPod("ns-a", "pending", "node1", "grit", "ReplicaSet", corev1.PodPending),
+		newPod("ns-a", "completed", "node1", "grit", "Job", corev1.PodSucceeded),
+		newPod("ns-a", "runc", "node1", "", "ReplicaSet", corev1.PodRunning),
+		newPod("ns-a", "other-node", "node2", "grit", "ReplicaSet", corev1.PodRunning),
+	}
+
+	testcases := map[string]struct {
+		spec     v1alpha1.NodeMigrationSpec
+		expected []v1alpha1.PodMigrationStatus
+	}{
+		"pods with grit runtime class are selected": {
+			spec: v1alpha1.NodeMigrationSpec{NodeName: "node1"},
+			expected: []v1alpha1.PodMigrationStatus{
+				{Namespace: "ns-a", Name: "daemon", Phase: v1alpha1.PodMigrationSkipped, Reason: "PodOwnedByDaemonSet", Message: "pod of daemonset is bound to the node"},
+				{Namespace: "ns-a", Name: "job", Phase: v1alpha1.PodMigrationPending},
+				{Namespace: "ns-a", Name: "orphan", Phase: v1alpha1.PodMigrationSkipped, Reason: "PodHasNoOwnerReference", Message: "pod has no owner reference, so it can't be recreated on other nodes"},
+				{Namespace: "ns-a", Name: "pending", Phase: v1alpha1.PodMigrationSkipped, Reason: "PodNotRunning", Message: "pod is Pending"},
+				{Namespace: "ns-b", Name: "running", Phase: v1alpha1.PodMigrationPending},
+			},
+		},
+		"pods are filtered by selector": {
+			spec: v1alpha1.NodeMigrationSpec{
+				NodeName: "node1",
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "job"}},
+			},
+			expected: []v1alpha1.PodMigrationStatus{
+				{Namespace: "ns-a", Name: "job", Phase: v1alpha1.PodMigrationPending},
+			},
+		},
+		"no pod with the runtime class": {
+			spec: v1alpha1.NodeMigrationSpec{NodeName: "node1", RuntimeClassName: "kata"},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			selected, err := selectPods(&v1alpha1.NodeMigration{Spec: tc.spec}, pods)
+			if err != nil {
+				t.Fatalf("failed to select pods, %v", err)
+			}
+			if !reflect.DeepEqual(selected, tc.expected) {
+				t.Errorf("expected pods %+v, got %+v", tc.expected, selected)
+			}
+		})
+	}
+}
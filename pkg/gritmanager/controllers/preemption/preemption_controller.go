@@ -0,0 +1,162 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package preemption
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+// Controller watches nodes for preemption notice which is exposed as a taint or condition by node termination handler
+// of cloud provider, and creates emergency checkpoints for opted-in pods on the preempted node at once. emergency
+// checkpoints are handled by Checkpoint controller, and their grit agents run with a higher priority and a deadline.
+type Controller struct {
+	client.Client
+	clock      clock.Clock
+	taints     []string
+	conditions []string
+	deadline   time.Duration
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client, taints, conditions []string, deadline time.Duration) *Controller {
+	return &Controller{
+		Client:     kubeClient,
+		clock:      clk,
+		taints:     taints,
+		conditions: conditions,
+		deadline:   deadline,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, node *corev1.Node) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "node.preemption")
+
+	noticeTime, preempted := c.preemptionNotice(node)
+	if !preempted {
+		return reconcile.Result{}, nil
+	}
+
+	var podList corev1.PodList
+	if err := c.List(ctx, &podList, client.MatchingFields{util.PodNodeNameField: node.Name}); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	deadline := metav1.NewTime(noticeTime.Add(c.deadline))
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		template, ok := pod.Annotations[v1alpha1.CheckpointOnPreemptionAnnotation]
+		if !ok || pod.Status.Phase != corev1.PodRunning || !pod.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		ckpt, err := newEmergencyCheckpoint(pod, template, deadline)
+		if err != nil {
+			// invalid template of a pod should not block emergency checkpoints of other pods.
+			log.FromContext(ctx).Error(err, "failed to create emergency checkpoint", "namespace", pod.Namespace, "pod", pod.Name)
+			continue
+		}
+
+		// emergency checkpoint is only created once for a pod, and it's not recreated when the node is reconciled again.
+		if err := c.Create(ctx, ckpt); client.IgnoreAlreadyExists(err) != nil {
+			return reconcile.Result{}, err
+		}
+		log.FromContext(ctx).Info("emergency checkpoint is created for preempted node", "node", node.Name, "namespace", pod.Namespace, "pod", pod.Name, "checkpoint", ckpt.Name)
+	}
+	return reconcile.Result{}, nil
+}
+
+// preemptionNotice returns whether the node has one of preemption taints or conditions, and the time when the notice
+// is received. the time of taint or condition is used if it's recorded, so the deadline is not extended by later reconciles.
+func (c *Controller) preemptionNotice(node *corev1.Node) (time.Time, bool) {
+	for _, taint := range node.Spec.Taints {
+		if slices.Contains(c.taints, taint.Key) {
+			if taint.TimeAdded != nil {
+				return taint.TimeAdded.Time, true
+			}
+			return c.clock.Now(), true
+		}
+	}
+	for _, cond := range node.Status.Conditions {
+		if cond.Status == corev1.ConditionTrue && slices.Contains(c.conditions, string(cond.Type)) {
+			if !cond.LastTransitionTime.IsZero() {
+				return cond.LastTransitionTime.Time, true
+			}
+			return c.clock.Now(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// newEmergencyCheckpoint creates a Checkpoint for the pod from the template, which is a json encoded CheckpointSpec without
+// podName. checkpoint is named by pod uid, so pod recreated with the same name(like StatefulSet pod) gets a new one.
+func newEmergencyCheckpoint(pod *corev1.Pod, template string, deadline metav1.Time) (*v1alpha1.Checkpoint, error) {
+	var spec v1alpha1.CheckpointSpec
+	if err := json.Unmarshal([]byte(template), &spec); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint template in annotation %s, %w", v1alpha1.CheckpointOnPreemptionAnnotation, err)
+	}
+	spec.PodName = pod.Name
+
+	uid := string(pod.UID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	return &v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-emergency-%s", pod.Name, uid),
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				v1alpha1.EmergencyCheckpointLabel: pod.Spec.NodeName,
+			},
+			Annotations: map[string]string{
+				v1alpha1.CheckpointDeadlineAnnotation: deadline.UTC().Format(time.RFC3339),
+			},
+		},
+		Spec: spec,
+	}, nil
+}
+
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=create
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("node.preemption").
+		For(&corev1.Node{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			node, ok := obj.(*corev1.Node)
+			if !ok {
+				return false
+			}
+			_, preempted := c.preemptionNotice(node)
+			return preempted
+		}))).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 30*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 5,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package preemption
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clocktesting "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+func TestPreemptionNotice(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	noticeTime := now.Add(-30 * time.Second)
+	c := NewController(clocktesting.NewFakeClock(now), nil, []string{"aws-node-termination-handler/spot-itn"}, []string{"PreemptScheduled"}, 90*time.Second)
+
+	testcases := map[string]struct {
+		node               corev1.Node
+		expectedPreempted  bool
+		expectedNoticeTime time.Time
+	}{
+		"node without notice": {
+			node: corev1.Node{
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "nvidia.com/gpu", Effect: corev1.TaintEffectNoSchedule}}},
+			},
+		},
+		"preemption taint with time added": {
+			node: corev1.Node{
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "aws-node-termination-handler/spot-itn", Effect: corev1.TaintEffectNoExecute, TimeAdded: &metav1.Time{Time: noticeTime}}}},
+			},
+			expectedPreempted:  true,
+			expectedNoticeTime: noticeTime,
+		},
+		"preemption taint without time added": {
+			node: corev1.Node{
+				Spec: corev1.NodeSpec{Taints: []corev1.Taint{{Key: "aws-node-termination-handler/spot-itn", Effect: corev1.TaintEffectNoSchedule}}},
+			},
+			expectedPreempted:  true,
+			expectedNoticeTime: now,
+		},
+		"preemption condition is true": {
+			node: corev1.Node{
+				Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "PreemptScheduled", Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: noticeTime}}}},
+			},
+			expectedPreempted:  true,
+			expectedNoticeTime: noticeTime,
+		},
+		"preemption condition is false": {
+			node: corev1.Node{
+				Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: "PreemptScheduled", Status: corev1.ConditionFalse}}},
+			},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			noticeTime, preempted := c.preemptionNotice(&tc.node)
+			if preempted != tc.expectedPreempted || !noticeTime.Equal(tc.expectedNoticeTime) {
+				t.Errorf("expected preempted %t at %v, got %t at %v", tc.expectedPreempted, tc.expectedNoticeTime, preempted, noticeTime)
+			}
+		})
+	}
+}
+
+func TestNewEmergencyCheckpoint(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "llm-0", UID: "6f1d2c3b-aaaa-bbbb-cccc-000000000000"},
+		Spec:       corev1.PodSpec{NodeName: "spot-node"},
+	}
+	deadline := metav1.NewTime(time.Date(2025, 1, 1, 0, 1, 30, 0, time.UTC))
+
+	ckpt, err := newEmergencyCheckpoint(pod, `{"volumeClaim":{"claimName":"checkpoint-pvc"}}`, deadline)
+	if err != nil {
+		t.Fatalf("failed to create emergency checkpoint, %v", err)
+	}
+	if ckpt.Name != "llm-0-emergency-6f1d2c3b" || ckpt.Spec.PodName != "llm-0" || ckpt.Spec.VolumeClaim == nil || ckpt.Spec.VolumeClaim.ClaimName != "checkpoint-pvc" {
+		t.Errorf("unexpected emergency checkpoint %s, spec %+v", ckpt.Name, ckpt.Spec)
+	}
+	if ckpt.Labels[v1alpha1.EmergencyCheckpointLabel] != "spot-node" || ckpt.Annotations[v1alpha1.CheckpointDeadlineAnnotation] != "2025-01-01T00:01:30Z" {
+		t.Errorf("unexpected labels %v and annotations %v", ckpt.Labels, ckpt.Annotations)
+	}
+
+	if _, err := newEmergencyCheckpoint(pod, `{"volumeClaim":`, deadline); err == nil {
+		t.Errorf("expected error for invalid template")
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{Taints: []corev1.Taint{{Key: "aws-node-termination-handler/spot-itn", Effect: corev1.TaintEffectNoExecute, TimeAdded: &metav1.Time{Time: now}}}},
+	}
+	pod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        name,
+				UID:         types.UID(name + "-uid"),
+				Annotations: map[string]string{v1alpha1.CheckpointOnPreemptionAnnotation: `{}`},
+			},
+			Spec:   corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	kubeClient := fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(node, pod("preempted", "node-1"), pod("other", "node-2")).
+		WithIndex(&corev1.Pod{}, util.PodNodeNameField, util.PodNodeName).
+		Build()
+	c := NewController(clocktesting.NewFakeClock(now), kubeClient, []string{"aws-node-termination-handler/spot-itn"}, nil, 90*time.Second)
+
+	if _, err := c.Reconcile(ctx, node); err != nil {
+		t.Fatalf("failed to reconcile node, %v", err)
+	}
+
+	var ckptList v1alpha1.CheckpointList
+	if err := kubeClient.List(ctx, &ckptList); err != nil {
+		t.Fatal(err)
+	}
+	if len(ckptList.Items) != 1 || ckptList.Items[0].Spec.PodName != "preempted" {
+		t.Fatalf("expected only emergency checkpoint of pod on preempted node, got %+v", ckptList.Items)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/clock"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -30,6 +31,7 @@ import (
 	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
 	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+	"github.com/kaito-project/grit/pkg/gritmanager/metrics"
 )
 
 var (
@@ -47,14 +49,16 @@ type Controller struct {
 	client.Client
 	clock         clock.Clock
 	agentManager  *agentmanager.AgentManager
+	recorder      record.EventRecorder
 	statesMachine map[v1alpha1.RestorePhase]RestoreStateHandler
 }
 
-func NewController(clk clock.Clock, kubeClient client.Client, agentManager *agentmanager.AgentManager) *Controller {
+func NewController(clk clock.Clock, kubeClient client.Client, agentManager *agentmanager.AgentManager, recorder record.EventRecorder) *Controller {
 	c := &Controller{
 		clock:        clk,
 		Client:       kubeClient,
 		agentManager: agentManager,
+		recorder:     recorder,
 	}
 
 	c.statesMachine = map[v1alpha1.RestorePhase]RestoreStateHandler{
@@ -70,6 +74,20 @@ func NewController(clk clock.Clock, kubeClient client.Client, agentManager *agen
 func (c *Controller) Reconcile(ctx context.Context, restore *v1alpha1.Restore) (reconcile.Result, error) {
 	ctx = util.WithControllerName(ctx, "restore.lifecycle")
 
+	// start the trace of restore before restoration pod is selected, so its traceparent can be passed to the pod.
+	if len(restore.Status.Phase) == 0 && len(restore.Annotations[v1alpha1.TraceParentAnnotation]) == 0 {
+		updatedRestore := restore.DeepCopy()
+		if util.StartTrace(ctx, updatedRestore, "restore", c.clock.Now()) {
+			return reconcile.Result{}, c.Patch(ctx, updatedRestore, client.MergeFrom(restore))
+		}
+	}
+
+	// restore which fails on exceeding its active deadline is not reconciled anymore, otherwise restoringHandler would
+	// overwrite the failure when restoration pod becomes ready later.
+	if util.DeadlineExceeded(restore.Status.Conditions, string(v1alpha1.RestoreFailed)) {
+		return reconcile.Result{}, nil
+	}
+
 	updatedRestore := restore.DeepCopy()
 	phase := v1alpha1.RestorePhase(util.ResolveLastPhaseFromConditions(updatedRestore.Status.Conditions, restoreConditionOrder, string(v1alpha1.RestoreCreated)))
 	log.FromContext(ctx).Info("the last pahse of restore", "namespace", restore.Namespace, "restore", restore.Name, "phase", phase)
@@ -78,6 +96,16 @@ func (c *Controller) Reconcile(ctx context.Context, restore *v1alpha1.Restore) (
 		return reconcile.Result{}, nil
 	}
 
+	// restore which is still in progress fails when active deadline is exceeded, otherwise it's reconciled again at the deadline.
+	var result reconcile.Result
+	if deadline, ok := util.ActiveDeadline(restore, restore.Spec.ActiveDeadlineSeconds); ok && phase != v1alpha1.Restored && restore.Status.Phase != v1alpha1.RestoreFailed {
+		if remaining := deadline.Sub(c.clock.Now()); remaining > 0 {
+			result.RequeueAfter = remaining
+		} else {
+			stateHandler = c.deadlineExceededHandler
+		}
+	}
+
 	if err := stateHandler(ctx, updatedRestore); err != nil {
 		return reconcile.Result{}, err
 	}
@@ -88,9 +116,16 @@ func (c *Controller) Reconcile(ctx context.Context, restore *v1alpha1.Restore) (
 	}
 
 	if !reflect.DeepEqual(restore, updatedRestore) {
-		return reconcile.Result{}, c.Status().Update(ctx, updatedRestore)
+		if err := c.Status().Update(ctx, updatedRestore); err != nil {
+			return reconcile.Result{}, err
+		}
+		metrics.RecordRestorePhase(restore, updatedRestore, c.clock.Now())
+		if updatedRestore.Status.Phase != restore.Status.Phase {
+			util.RecordPhaseSpan(ctx, updatedRestore, updatedRestore.Status.Conditions, "restore", string(restore.Status.Phase), string(updatedRestore.Status.Phase), updatedRestore.Status.Phase == v1alpha1.RestoreFailed, c.clock.Now())
+			c.recordPhaseEvent(updatedRestore)
+		}
 	}
-	return reconcile.Result{}, nil
+	return result, nil
 }
 
 // createdHandler is used for waiting to select the restoration pod, then upgraded state to RestorePending.
@@ -200,10 +235,42 @@ func (c *Controller) restoringHandler(ctx context.Context, restore *v1alpha1.Res
 		return nil
 	}
 
+	// restoration pod can not be started when grit agent failed to download or verify checkpointed data.
+	var gritAgentJob batchv1.Job
+	if err := c.Get(ctx, client.ObjectKey{Namespace: restore.Namespace, Name: util.GritAgentJobName(nil, restore)}, &gritAgentJob); client.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil {
+		if _, isFailed := util.JobCompletedOrFailed(&gritAgentJob); isFailed {
+			reason, message := "GritAgentJobFailed", fmt.Sprintf("failed to execute grit agent job(%s/%s) in restoring state after %d attempts", gritAgentJob.Namespace, gritAgentJob.Name, gritAgentJob.Status.Failed)
+			switch util.JobFailedReason(&gritAgentJob) {
+			case batchv1.JobReasonDeadlineExceeded:
+				reason, message = util.DeadlineExceededReason, fmt.Sprintf("grit agent job(%s/%s) is not completed before deadline", gritAgentJob.Namespace, gritAgentJob.Name)
+			case batchv1.JobReasonPodFailurePolicy:
+				reason, message = "GritAgentPermanentFailure", fmt.Sprintf("grit agent job(%s/%s) failed on a permanent error, it's not retried", gritAgentJob.Namespace, gritAgentJob.Name)
+			}
+			if report, err := util.GritAgentReport(ctx, c.Client, &gritAgentJob); err != nil {
+				return err
+			} else if report != nil && len(report.VerificationError) != 0 {
+				reason, message = "CheckpointDataVerificationFailed", fmt.Sprintf("checkpointed data of checkpoint(%s) is corrupted or incomplete, %s", restore.Spec.CheckpointName, report.VerificationError)
+			}
+			restore.Status.Phase = v1alpha1.RestoreFailed
+			util.UpdateCondition(c.clock, &restore.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreFailed), reason, message)
+			return nil
+		}
+	}
+
 	if restorationPod.Status.Phase == corev1.PodFailed {
 		restore.Status.Phase = v1alpha1.RestoreFailed
 		util.UpdateCondition(c.clock, &restore.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreFailed), "RestorationPodFailed", fmt.Sprintf("restoration pod(%s) for restore(%s) failed to start", restore.Status.TargetPod, restore.Name))
 	} else if restorationPod.Status.Phase == corev1.PodRunning {
+		// data size and throughput are reported by grit agent, and they're only used for observability, so missing report is ignored.
+		if len(gritAgentJob.Name) != 0 {
+			if report, err := util.GritAgentReport(ctx, c.Client, &gritAgentJob); err != nil {
+				log.FromContext(ctx).Error(err, "failed to get report of grit agent", "job", gritAgentJob.Name)
+			} else if report != nil {
+				metrics.RecordAgentReport(metrics.ActionRestore, report)
+			}
+		}
 		restore.Status.Phase = v1alpha1.Restored
 		util.UpdateCondition(c.clock, &restore.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.Restored), "RestorationPodRunning", fmt.Sprintf("restoration pod(%s) for restore(%s) is running", restore.Status.TargetPod, restore.Name))
 	}
@@ -211,6 +278,34 @@ func (c *Controller) restoringHandler(ctx context.Context, restore *v1alpha1.Res
 	return nil
 }
 
+// deadlineExceededHandler is used for failing restore which is not completed within active deadline, and grit agent
+// job is removed, so a stuck data transfer is stopped.
+func (c *Controller) deadlineExceededHandler(ctx context.Context, restore *v1alpha1.Restore) error {
+	var gritAgentJob batchv1.Job
+	if err := c.Get(ctx, client.ObjectKey{Namespace: restore.Namespace, Name: util.GritAgentJobName(nil, restore)}, &gritAgentJob); client.IgnoreNotFound(err) != nil {
+		return err
+	} else if err == nil && gritAgentJob.DeletionTimestamp.IsZero() {
+		deletePolicy := metav1.DeletePropagationForeground
+		if err := c.Delete(ctx, &gritAgentJob, &client.DeleteOptions{PropagationPolicy: &deletePolicy}); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+
+	restore.Status.Phase = v1alpha1.RestoreFailed
+	util.UpdateCondition(c.clock, &restore.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreFailed), util.DeadlineExceededReason, fmt.Sprintf("restoration pod(%s) is not restored within %d seconds", restore.Status.TargetPod, *restore.Spec.ActiveDeadlineSeconds))
+	return nil
+}
+
+// recordPhaseEvent emits events on Restore and the restoration pod when Restore moves into a new phase, so the history
+// of restore can be followed by kubectl describe.
+func (c *Controller) recordPhaseEvent(restore *v1alpha1.Restore) {
+	eventType, reason, message := util.PhaseEvent(restore.Status.Conditions, string(restore.Status.Phase), restore.Status.Phase == v1alpha1.RestoreFailed)
+	c.recorder.Event(restore, eventType, reason, message)
+	if len(restore.Status.TargetPod) != 0 {
+		c.recorder.Eventf(util.PodForEvent(restore.Namespace, restore.Status.TargetPod, ""), eventType, reason, "restore(%s): %s", restore.Name, message)
+	}
+}
+
 // restoredHandler is used for garbage collecting grit agent pod which used for restoring pod.
 func (c *Controller) restoredHandler(ctx context.Context, restore *v1alpha1.Restore) error {
 	var gritAgentJob batchv1.Job
@@ -227,7 +322,7 @@ func (c *Controller) restoredHandler(ctx context.Context, restore *v1alpha1.Rest
 	return nil
 }
 
-// +kubebuilder:rbac:groups=kaito.sh,resources=restores,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=restores,verbs=list;watch;get;patch
 // +kubebuilder:rbac:groups=kaito.sh,resources=restores/status,verbs=update
 // +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=get
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list;watch;get;create;delete
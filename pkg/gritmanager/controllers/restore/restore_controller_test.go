@@ -0,0 +1,86 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package restore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+func TestDeadlineExceeded(t *testing.T) {
+	restore := &v1alpha1.Restore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         "default",
+			Name:              "restore",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Minute)),
+		},
+		Spec: v1alpha1.RestoreSpec{CheckpointName: "ckpt", ActiveDeadlineSeconds: ptr.To[int64](60)},
+		Status: v1alpha1.RestoreStatus{
+			Phase:     v1alpha1.Restoring,
+			TargetPod: "pod",
+			Conditions: []metav1.Condition{
+				{Type: string(v1alpha1.RestorePending), Status: metav1.ConditionTrue},
+				{Type: string(v1alpha1.Restoring), Status: metav1.ConditionTrue},
+			},
+		},
+	}
+	gritAgentJob := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: restore.Namespace, Name: util.GritAgentJobName(nil, restore)}}
+	// restoration pod becomes running after the deadline.
+	restorationPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: restore.Namespace, Name: restore.Status.TargetPod},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(restore, gritAgentJob, restorationPod).WithStatusSubresource(&v1alpha1.Restore{}).Build()
+	c := NewController(clock.NewFakeClock(time.Now()), kubeClient, nil, record.NewFakeRecorder(10))
+
+	ctx := context.Background()
+	// the second reconciliation should keep the failure of the first one.
+	for i := 0; i < 2; i++ {
+		var updatedRestore v1alpha1.Restore
+		if err := c.Get(ctx, client.ObjectKeyFromObject(restore), &updatedRestore); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Reconcile(ctx, &updatedRestore); err != nil {
+			t.Fatalf("failed to reconcile restore, %v", err)
+		}
+	}
+
+	var updatedRestore v1alpha1.Restore
+	if err := c.Get(ctx, client.ObjectKeyFromObject(restore), &updatedRestore); err != nil {
+		t.Fatal(err)
+	}
+	if updatedRestore.Status.Phase != v1alpha1.RestoreFailed {
+		t.Errorf("expected restore is %s, got %s", v1alpha1.RestoreFailed, updatedRestore.Status.Phase)
+	}
+	if !util.DeadlineExceeded(updatedRestore.Status.Conditions, string(v1alpha1.RestoreFailed)) {
+		t.Errorf("expected failed condition with reason %s, got %+v", util.DeadlineExceededReason, updatedRestore.Status.Conditions)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(gritAgentJob), &batchv1.Job{}); !apierrors.IsNotFound(err) {
+		t.Errorf("expected grit agent job is removed, got %v", err)
+	}
+}
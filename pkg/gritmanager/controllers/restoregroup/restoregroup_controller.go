@@ -0,0 +1,206 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package restoregroup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type Controller struct {
+	client.Client
+	clock clock.Clock
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client) *Controller {
+	return &Controller{
+		clock:  clk,
+		Client: kubeClient,
+	}
+}
+
+// Reconcile creates a Restore for each member Checkpoint of CheckpointGroup, and RestoreGroup is restored only when
+// all member Restores are restored.
+func (c *Controller) Reconcile(ctx context.Context, restoreGroup *v1alpha1.RestoreGroup) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "restoregroup.lifecycle")
+
+	updatedRestoreGroup := restoreGroup.DeepCopy()
+	var err error
+	switch updatedRestoreGroup.Status.Phase {
+	case "":
+		updatedRestoreGroup.Status.Phase = v1alpha1.RestoreGroupCreated
+		util.UpdateCondition(c.clock, &updatedRestoreGroup.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreGroupCreated), "RestoreGroupIsCreated", "restore group resource is created")
+	case v1alpha1.RestoreGroupCreated:
+		err = c.createMembers(ctx, updatedRestoreGroup)
+	case v1alpha1.RestoreGroupRestoring:
+		err = c.syncMembers(ctx, updatedRestoreGroup)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(restoreGroup, updatedRestoreGroup) {
+		return reconcile.Result{}, c.Status().Update(ctx, updatedRestoreGroup)
+	}
+	return reconcile.Result{}, nil
+}
+
+// createMembers is used for creating Restore for each member Checkpoint after CheckpointGroup is checkpointed,
+// then upgraded state to Restoring.
+func (c *Controller) createMembers(ctx context.Context, restoreGroup *v1alpha1.RestoreGroup) error {
+	var group v1alpha1.CheckpointGroup
+	if err := c.Get(ctx, client.ObjectKey{Namespace: restoreGroup.Namespace, Name: restoreGroup.Spec.CheckpointGroupName}, &group); err != nil {
+		if apierrors.IsNotFound(err) {
+			restoreGroup.Status.Phase = v1alpha1.RestoreGroupFailed
+			util.UpdateCondition(c.clock, &restoreGroup.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreGroupFailed), "CheckpointGroupNotExist", fmt.Sprintf("checkpoint group(%s) doesn't exist", restoreGroup.Spec.CheckpointGroupName))
+			return nil
+		}
+		return err
+	}
+
+	switch group.Status.Phase {
+	case v1alpha1.CheckpointGroupFailed:
+		restoreGroup.Status.Phase = v1alpha1.RestoreGroupFailed
+		util.UpdateCondition(c.clock, &restoreGroup.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreGroupFailed), "CheckpointGroupFailed", fmt.Sprintf("checkpoint group(%s) failed", group.Name))
+		return nil
+	case v1alpha1.CheckpointGroupCheckpointed:
+	default:
+		// wait for checkpoint group checkpointed
+		return nil
+	}
+
+	members := lo.Map(group.Status.Members, func(member v1alpha1.CheckpointGroupMember, i int) v1alpha1.RestoreGroupMember {
+		return v1alpha1.RestoreGroupMember{
+			CheckpointName: member.CheckpointName,
+			RestoreName:    fmt.Sprintf("%s-%d", restoreGroup.Name, i),
+		}
+	})
+
+	for _, member := range members {
+		restore := v1alpha1.Restore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      member.RestoreName,
+				Namespace: restoreGroup.Namespace,
+				Labels: map[string]string{
+					v1alpha1.RestoreGroupLabel: restoreGroup.Name,
+				},
+			},
+			Spec: v1alpha1.RestoreSpec{
+				CheckpointName: member.CheckpointName,
+				OwnerRef:       restoreGroup.Spec.OwnerRef,
+				Selector:       restoreGroup.Spec.Selector,
+			},
+		}
+		if err := controllerutil.SetControllerReference(restoreGroup, &restore, c.Scheme()); err != nil {
+			return err
+		}
+
+		// restore maybe has been created in the previous reconcile but status update failed, so adopt it.
+		if err := c.Create(ctx, &restore); client.IgnoreAlreadyExists(err) != nil {
+			return err
+		}
+	}
+	log.FromContext(ctx).Info("member restores are created for restore group", "namespace", restoreGroup.Namespace, "restoreGroup", restoreGroup.Name, "count", len(members))
+
+	restoreGroup.Status.Members = members
+	restoreGroup.Status.Phase = v1alpha1.RestoreGroupRestoring
+	util.UpdateCondition(c.clock, &restoreGroup.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreGroupRestoring), "MemberRestoresCreated", fmt.Sprintf("%d member restores are created", len(members)))
+	return nil
+}
+
+// syncMembers is used for recording phases of member Restores. RestoreGroup fails as soon as any member fails.
+func (c *Controller) syncMembers(ctx context.Context, restoreGroup *v1alpha1.RestoreGroup) error {
+	var failedMembers []string
+	for i := range restoreGroup.Status.Members {
+		member := &restoreGroup.Status.Members[i]
+		var restore v1alpha1.Restore
+		if err := c.Get(ctx, client.ObjectKey{Namespace: restoreGroup.Namespace, Name: member.RestoreName}, &restore); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return err
+			}
+			member.Phase = v1alpha1.RestoreFailed
+		} else {
+			member.Phase = restore.Status.Phase
+			member.TargetPod = restore.Status.TargetPod
+		}
+
+		if member.Phase == v1alpha1.RestoreFailed {
+			failedMembers = append(failedMembers, member.RestoreName)
+		}
+	}
+
+	if len(failedMembers) != 0 {
+		restoreGroup.Status.Phase = v1alpha1.RestoreGroupFailed
+		util.UpdateCondition(c.clock, &restoreGroup.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreGroupFailed), "MemberRestoreFailed", fmt.Sprintf("member restores(%s) failed or were removed", strings.Join(failedMembers, ",")))
+		return nil
+	}
+
+	allRestored := lo.EveryBy(restoreGroup.Status.Members, func(member v1alpha1.RestoreGroupMember) bool {
+		return member.Phase == v1alpha1.Restored
+	})
+	if allRestored {
+		restoreGroup.Status.Phase = v1alpha1.RestoreGroupRestored
+		util.UpdateCondition(c.clock, &restoreGroup.Status.Conditions, metav1.ConditionTrue, string(v1alpha1.RestoreGroupRestored), "AllMembersRestored", fmt.Sprintf("all %d member restores are restored", len(restoreGroup.Status.Members)))
+	}
+	return nil
+}
+
+// +kubebuilder:rbac:groups=kaito.sh,resources=restoregroups,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=restoregroups/status,verbs=update
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpointgroups,verbs=list;watch;get
+// +kubebuilder:rbac:groups=kaito.sh,resources=restores,verbs=list;watch;get;create
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("restoregroup.lifecycle").
+		For(&v1alpha1.RestoreGroup{}).
+		Owns(&v1alpha1.Restore{}).
+		Watches(&v1alpha1.CheckpointGroup{}, handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+			group, ok := obj.(*v1alpha1.CheckpointGroup)
+			if !ok {
+				return []reconcile.Request{}
+			}
+
+			var restoreGroupList v1alpha1.RestoreGroupList
+			if err := m.GetClient().List(ctx, &restoreGroupList, &client.ListOptions{Namespace: group.Namespace}); err != nil {
+				return []reconcile.Request{}
+			}
+
+			return lo.FilterMap(restoreGroupList.Items, func(restoreGroup v1alpha1.RestoreGroup, _ int) (reconcile.Request, bool) {
+				return reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: restoreGroup.Namespace, Name: restoreGroup.Name},
+				}, restoreGroup.Spec.CheckpointGroupName == group.Name
+			})
+		})).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 3,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/samber/lo"
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+// Controller is used for deleting Checkpoints which are out of retention policy. checkpointed data will be removed
+// by the finalizer of Checkpoint.
+type Controller struct {
+	client.Client
+	clock clock.Clock
+}
+
+func NewController(clk clock.Clock, kubeClient client.Client) *Controller {
+	return &Controller{
+		clock:  clk,
+		Client: kubeClient,
+	}
+}
+
+func (c *Controller) Reconcile(ctx context.Context, ckpt *v1alpha1.Checkpoint) (reconcile.Result, error) {
+	ctx = util.WithControllerName(ctx, "checkpoint.retention")
+
+	if ckpt.Spec.RetentionPolicy == nil || !ckpt.DeletionTimestamp.IsZero() {
+		return reconcile.Result{}, nil
+	}
+
+	checkpointedTime := util.CheckpointedTime(ckpt)
+	if checkpointedTime == nil {
+		return reconcile.Result{}, nil
+	}
+
+	var result reconcile.Result
+	if ttl := ckpt.Spec.RetentionPolicy.TTLSecondsAfterCheckpointed; ttl != nil {
+		expireTime := checkpointedTime.Add(time.Duration(*ttl) * time.Second)
+		if now := c.clock.Now(); now.Before(expireTime) {
+			result.RequeueAfter = expireTime.Sub(now)
+		} else {
+			log.FromContext(ctx).Info("delete checkpoint because ttl expired", "namespace", ckpt.Namespace, "checkpoint", ckpt.Name, "checkpointedTime", checkpointedTime)
+			return reconcile.Result{}, client.IgnoreNotFound(c.Delete(ctx, ckpt))
+		}
+	}
+
+	if keepLast := ckpt.Spec.RetentionPolicy.KeepLast; keepLast != nil {
+		if err := c.pruneCheckpoints(ctx, ckpt, int(*keepLast)); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// pruneCheckpoints is used for deleting old checkpointed Checkpoints of the same workload, only the latest keepLast Checkpoints are kept.
+// Checkpoints without KeepLast policy are not counted and never deleted.
+func (c *Controller) pruneCheckpoints(ctx context.Context, ckpt *v1alpha1.Checkpoint, keepLast int) error {
+	var ckptList v1alpha1.CheckpointList
+	if err := c.List(ctx, &ckptList, &client.ListOptions{Namespace: ckpt.Namespace}); err != nil {
+		return err
+	}
+
+	key := retentionKey(ckpt)
+	ckpts := lo.Filter(ckptList.Items, func(item v1alpha1.Checkpoint, _ int) bool {
+		return item.DeletionTimestamp.IsZero() &&
+			item.Spec.RetentionPolicy != nil &&
+			item.Spec.RetentionPolicy.KeepLast != nil &&
+			util.CheckpointedTime(&item) != nil &&
+			retentionKey(&item) == key
+	})
+	if len(ckpts) <= keepLast {
+		return nil
+	}
+
+	// the latest checkpoint is at the front
+	sort.Slice(ckpts, func(i, j int) bool {
+		ti, tj := util.CheckpointedTime(&ckpts[i]), util.CheckpointedTime(&ckpts[j])
+		if ti.Equal(tj) {
+			return ckpts[i].Name > ckpts[j].Name
+		}
+		return tj.Before(ti)
+	})
+
+	for i := keepLast; i < len(ckpts); i++ {
+		log.FromContext(ctx).Info("delete checkpoint because it's out of retention policy", "namespace", ckpts[i].Namespace, "checkpoint", ckpts[i].Name, "keepLast", keepLast)
+		if err := c.Delete(ctx, &ckpts[i]); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retentionKey is used for grouping Checkpoints of the same workload. Checkpoints are grouped by pod owner,
+// and grouped by pod name when pod has no owner.
+func retentionKey(ckpt *v1alpha1.Checkpoint) string {
+	if len(ckpt.Status.PodOwnerUID) != 0 {
+		return fmt.Sprintf("owner/%s", ckpt.Status.PodOwnerUID)
+	}
+	return fmt.Sprintf("pod/%s", ckpt.Spec.PodName)
+}
+
+// +kubebuilder:rbac:groups=kaito.sh,resources=checkpoints,verbs=list;watch;get;delete
+
+func (c *Controller) Register(_ context.Context, m manager.Manager) error {
+	return controllerruntime.NewControllerManagedBy(m).
+		Named("checkpoint.retention").
+		For(&v1alpha1.Checkpoint{}).
+		WithOptions(controller.Options{
+			RateLimiter: workqueue.NewTypedMaxOfRateLimiter(
+				workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](time.Second, 300*time.Second),
+				&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
+			),
+			MaxConcurrentReconciles: 3,
+		}).
+		Complete(reconcile.AsReconciler(m.GetClient(), c))
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package retention
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/samber/lo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clock "k8s.io/utils/clock/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+var checkpointedAt = time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+
+func checkpointed(name, ownerUID string, minutes int, policy *v1alpha1.RetentionPolicy) *v1alpha1.Checkpoint {
+	return &v1alpha1.Checkpoint{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name},
+		Spec: v1alpha1.CheckpointSpec{
+			PodName:         name,
+			RetentionPolicy: policy,
+		},
+		Status: v1alpha1.CheckpointStatus{
+			Phase:       v1alpha1.Checkpointed,
+			PodOwnerUID: ownerUID,
+			Conditions: []metav1.Condition{
+				{
+					Type:               string(v1alpha1.Checkpointed),
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(checkpointedAt.Add(time.Duration(minutes) * time.Minute)),
+				},
+			},
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	keepLast := &v1alpha1.RetentionPolicy{KeepLast: lo.ToPtr[int32](2)}
+	ttl := &v1alpha1.RetentionPolicy{TTLSecondsAfterCheckpointed: lo.ToPtr[int32](3600)}
+
+	testcases := map[string]struct {
+		ckpt            *v1alpha1.Checkpoint
+		others          []client.Object
+		now             time.Time
+		expectedRequeue time.Duration
+		expected        []string
+	}{
+		"checkpoint without retention policy is kept": {
+			ckpt:     checkpointed("ckpt", "", 0, nil),
+			now:      checkpointedAt.Add(24 * time.Hour),
+			expected: []string{"ckpt"},
+		},
+		"checkpoint is requeued before ttl expires": {
+			ckpt:            checkpointed("ckpt", "", 0, ttl),
+			now:             checkpointedAt.Add(20 * time.Minute),
+			expectedRequeue: 40 * time.Minute,
+			expected:        []string{"ckpt"},
+		},
+		"checkpoint is deleted after ttl expires": {
+			ckpt: checkpointed("ckpt", "", 0, ttl),
+			now:  checkpointedAt.Add(time.Hour),
+		},
+		"old checkpoints of the same owner are pruned": {
+			ckpt: checkpointed("ckpt-3", "owner", 3, keepLast),
+			others: []client.Object{
+				checkpointed("ckpt-1", "owner", 1, keepLast),
+				checkpointed("ckpt-2", "owner", 2, keepLast),
+				checkpointed("other-owner", "other", 0, keepLast),
+				checkpointed("no-keep-last", "owner", 0, ttl),
+			},
+			now:      checkpointedAt.Add(5 * time.Minute),
+			expected: []string{"ckpt-2", "ckpt-3", "no-keep-last", "other-owner"},
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := v1alpha1.SchemeBuilder.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			kubeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(tc.others, tc.ckpt)...).Build()
+			c := NewController(clock.NewFakeClock(tc.now), kubeClient)
+
+			result, err := c.Reconcile(context.Background(), tc.ckpt)
+			if err != nil {
+				t.Fatalf("failed to reconcile, %v", err)
+			}
+			if result.RequeueAfter != tc.expectedRequeue {
+				t.Errorf("expected requeue after %v, got %v", tc.expectedRequeue, result.RequeueAfter)
+			}
+
+			var ckptList v1alpha1.CheckpointList
+			if err := kubeClient.List(context.Background(), &ckptList); err != nil {
+				t.Fatal(err)
+			}
+			names := lo.Map(ckptList.Items, func(ckpt v1alpha1.Checkpoint, _ int) string { return ckpt.Name })
+			sort.Strings(names)
+			if len(names) != len(tc.expected) || (len(names) != 0 && !lo.Every(names, tc.expected)) {
+				t.Errorf("expected checkpoints %v, got %v", tc.expected, names)
+			}
+		})
+	}
+}
@@ -7,11 +7,22 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
+	"path"
+	"slices"
 	"strings"
-
+	"time"
+
+	"github.com/distribution/reference"
+	"github.com/samber/lo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/dump"
 	"k8s.io/utils/clock"
@@ -22,14 +33,27 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/metadata"
+	"github.com/kaito-project/grit/pkg/util/tracing"
 )
 
 const (
-	ServerKey               = "server-key.pem"
-	ServerCert              = "server-cert.pem"
-	CACert                  = "ce-cert.pem"
-	GritAgentJobNamePrefix  = "grit-agent-"
-	KubeAPIAccessNamePrefix = "kube-api-access-"
+	ServerKey              = "server-key.pem"
+	ServerCert             = "server-cert.pem"
+	CACert                 = "ce-cert.pem"
+	GritAgentJobNamePrefix = "grit-agent-"
+	// GritAgentRestoreJobNamePrefix is used for grit agent jobs of Restores, so they don't collide with jobs of Checkpoints
+	// which have the same name, like Checkpoint and Restore of a Migration. it doesn't start with GritAgentJobNamePrefix,
+	// so it can't collide with job of any Checkpoint either.
+	GritAgentRestoreJobNamePrefix = "grit-restore-agent-"
+	KubeAPIAccessNamePrefix       = "kube-api-access-"
+	// PodNodeNameField is the field index of pods by spec.nodeName, so pods on a node are listed from the cache
+	// instead of filtering all pods of the cluster.
+	PodNodeNameField = "spec.nodeName"
+	// TracerName is the name of tracer for spans of grit manager.
+	TracerName = "github.com/kaito-project/grit/pkg/gritmanager"
+	// DeadlineExceededReason is the reason of failed condition when active deadline is exceeded.
+	DeadlineExceededReason = "DeadlineExceeded"
 )
 
 type controllerNameKeyType struct{}
@@ -93,35 +117,154 @@ var (
 			return []reconcile.Request{}
 		}
 
-		if strings.HasPrefix(job.Name, GritAgentJobNamePrefix) {
+		// cleanup job is named with hash of node name, so resolve checkpoint name from label.
+		if ckptName, ok := job.Labels[v1alpha1.CheckpointCleanupLabel]; ok {
 			return []reconcile.Request{
 				{
-					NamespacedName: types.NamespacedName{Namespace: job.Namespace, Name: GritAgentJobOwnerName(job)},
+					NamespacedName: types.NamespacedName{Namespace: job.Namespace, Name: ckptName},
 				},
 			}
 		}
-		return []reconcile.Request{}
+
+		return gritAgentJobOwnerRequests(job.Namespace, job.Name)
+	})
+
+	// GritAgentReportPodPredicate filters grit agent pods which are kept until their reports are read.
+	GritAgentReportPodPredicate = predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		return slices.Contains(obj.GetFinalizers(), v1alpha1.GritAgentReportFinalizer)
+	})
+
+	// GritAgentPodHandler enqueues the owner of grit agent job which the pod belongs to.
+	GritAgentPodHandler = handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		jobName, ok := obj.GetLabels()[batchv1.JobNameLabel]
+		if !ok {
+			return []reconcile.Request{}
+		}
+		return gritAgentJobOwnerRequests(obj.GetNamespace(), jobName)
 	})
 )
 
+// gritAgentJobOwnerRequests resolves Checkpoint or Restore from the name of grit agent job.
+func gritAgentJobOwnerRequests(namespace, jobName string) []reconcile.Request {
+	var name string
+	if strings.HasPrefix(jobName, GritAgentRestoreJobNamePrefix) {
+		name = strings.TrimPrefix(jobName, GritAgentRestoreJobNamePrefix)
+	} else if strings.HasPrefix(jobName, GritAgentJobNamePrefix) {
+		name = strings.TrimPrefix(jobName, GritAgentJobNamePrefix)
+	} else {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+}
+
 func GritAgentJobName(ckpt *v1alpha1.Checkpoint, restore *v1alpha1.Restore) string {
 	if ckpt != nil {
 		return fmt.Sprintf("%s%s", GritAgentJobNamePrefix, ckpt.Name)
 	} else if restore != nil {
-		return fmt.Sprintf("%s%s", GritAgentJobNamePrefix, restore.Name)
+		return fmt.Sprintf("%s%s", GritAgentRestoreJobNamePrefix, restore.Name)
 	}
 	return ""
 }
 
-func GritAgentJobOwnerName(job *batchv1.Job) string {
-	if job != nil {
-		if strings.HasPrefix(job.Name, GritAgentJobNamePrefix) {
-			return strings.TrimPrefix(job.Name, GritAgentJobNamePrefix)
+// GritAgentReport returns the report written by grit agent into termination message of the pod of job, nil will be
+// returned if there is no report.
+func GritAgentReport(ctx context.Context, kubeClient client.Client, job *batchv1.Job) (*metadata.AgentReport, error) {
+	var pods corev1.PodList
+	if err := kubeClient.List(ctx, &pods, client.InNamespace(job.Namespace), client.MatchingLabels{batchv1.JobNameLabel: job.Name}); err != nil {
+		return nil, err
+	}
+
+	// grit agent pod maybe has been retried, and the report of the latest pod is used.
+	slices.SortFunc(pods.Items, func(a, b corev1.Pod) int {
+		return b.CreationTimestamp.Compare(a.CreationTimestamp.Time)
+	})
+	for i := range pods.Items {
+		for _, status := range pods.Items[i].Status.ContainerStatuses {
+			if status.State.Terminated != nil && len(status.State.Terminated.Message) != 0 {
+				return metadata.ParseAgentReport(status.State.Terminated.Message)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// JobCompletedOrFailed returns whether the job is completed or failed.
+func JobCompletedOrFailed(job *batchv1.Job) (bool, bool) {
+	if job == nil {
+		return false, false
+	}
+
+	if job.Status.Succeeded > 0 {
+		return true, false
+	}
+
+	// failed pods are retried by job controller until backoff limit is exceeded, so only failed condition is terminal.
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == "True" {
+			return true, false
+		}
+
+		if cond.Type == batchv1.JobFailed && cond.Status == "True" {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// JobFailedReason returns the reason of failed condition of the job, like BackoffLimitExceeded, DeadlineExceeded or
+// PodFailurePolicy. empty string is returned if the job is not failed.
+func JobFailedReason(job *batchv1.Job) string {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue {
+			return cond.Reason
 		}
 	}
 	return ""
 }
 
+// ActiveDeadline returns the deadline of checkpoint or restore with active deadline seconds, which is counted from
+// the creation of the object.
+func ActiveDeadline(obj metav1.Object, activeDeadlineSeconds *int64) (time.Time, bool) {
+	creationTimestamp := obj.GetCreationTimestamp()
+	if activeDeadlineSeconds == nil || creationTimestamp.IsZero() {
+		return time.Time{}, false
+	}
+	return creationTimestamp.Add(time.Duration(*activeDeadlineSeconds) * time.Second), true
+}
+
+// DeadlineExceeded returns whether the failed condition(failedType) is caused by exceeding active deadline. this failure
+// is terminal, grit agent job has been removed and it should not be overwritten by the handler of the last phase.
+func DeadlineExceeded(conditions []metav1.Condition, failedType string) bool {
+	cond := meta.FindStatusCondition(conditions, failedType)
+	return cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == DeadlineExceededReason
+}
+
+// GritAgentCleanupJobName returns the name of grit agent job which is used for cleaning up checkpointed data on the node,
+// and empty nodeName is for the job which cleans up data in the storage. the name is stable for the node, and node name is
+// hashed because job name is used as label value of its pods, which is too short for node name.
+func GritAgentCleanupJobName(ckpt *v1alpha1.Checkpoint, nodeName string) string {
+	if len(nodeName) == 0 {
+		return fmt.Sprintf("%s%s-cleanup-storage", GritAgentJobNamePrefix, ckpt.Name)
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(nodeName))
+	return fmt.Sprintf("%s%s-cleanup-%08x", GritAgentJobNamePrefix, ckpt.Name, hash.Sum32())
+}
+
+// IndexPodNodeName registers PodNodeNameField index of pods, it should be registered before the cache is started.
+func IndexPodNodeName(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &corev1.Pod{}, PodNodeNameField, PodNodeName)
+}
+
+// PodNodeName extracts the value of PodNodeNameField index from pod.
+func PodNodeName(obj client.Object) []string {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok || len(pod.Spec.NodeName) == 0 {
+		return nil
+	}
+	return []string{pod.Spec.NodeName}
+}
+
 func WithControllerName(ctx context.Context, name string) context.Context {
 	return context.WithValue(ctx, controllerNameKey, name)
 }
@@ -213,6 +356,156 @@ func RemoveCondition(conditions *[]metav1.Condition, conditionType string) {
 	}
 }
 
+// CheckpointedTime returns the time when checkpoint is checkpointed, nil will be returned if checkpoint has not completed checkpoint process.
+func CheckpointedTime(ckpt *v1alpha1.Checkpoint) *metav1.Time {
+	cond := meta.FindStatusCondition(ckpt.Status.Conditions, string(v1alpha1.Checkpointed))
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return nil
+	}
+	return &cond.LastTransitionTime
+}
+
+// ValidateParentCheckpoint checks parent checkpoint can be used for incremental checkpoint of pod. parent checkpoint should
+// be checkpointed from the same pod instance and stored in the same volume, because only dirtied memory pages since parent are dumped.
+// parent should be checkpointed in Snapshot mode too, because the workload is stopped after it's dumped in Stop mode.
+func ValidateParentCheckpoint(ckpt, parent *v1alpha1.Checkpoint, pod *corev1.Pod) error {
+	if parent.Status.Phase != v1alpha1.Checkpointed {
+		return fmt.Errorf("parent checkpoint(%s) has not completed checkpoint process", parent.Name)
+	}
+
+	if parent.Spec.Mode != v1alpha1.CheckpointModeSnapshot {
+		return fmt.Errorf("parent checkpoint(%s) is not checkpointed in %s mode", parent.Name, v1alpha1.CheckpointModeSnapshot)
+	}
+
+	if parent.Status.PodUID != string(pod.UID) {
+		return fmt.Errorf("parent checkpoint(%s) is not checkpointed from pod(%s)", parent.Name, pod.Name)
+	}
+
+	if !isSameStorage(&parent.Spec, &ckpt.Spec) {
+		return fmt.Errorf("parent checkpoint(%s) is not stored in the same storage", parent.Name)
+	}
+
+	// only containers checkpointed by parent have parent images, and empty list means all containers.
+	if len(parent.Spec.Containers) != 0 &&
+		(len(ckpt.Spec.Containers) == 0 || slices.ContainsFunc(ckpt.Spec.Containers, func(name string) bool { return !slices.Contains(parent.Spec.Containers, name) })) {
+		return fmt.Errorf("containers of checkpoint(%s) are not checkpointed by parent checkpoint(%s)", ckpt.Name, parent.Name)
+	}
+
+	if (parent.Spec.Compression == nil) != (ckpt.Spec.Compression == nil) {
+		return fmt.Errorf("compression of parent checkpoint(%s) is not the same as checkpoint(%s)", parent.Name, ckpt.Name)
+	}
+
+	// parent data is decrypted by restore agent of checkpoint, so keys of parent should be in the same secret.
+	if (parent.Spec.Encryption == nil) != (ckpt.Spec.Encryption == nil) ||
+		(parent.Spec.Encryption != nil && parent.Spec.Encryption.SecretName != ckpt.Spec.Encryption.SecretName) {
+		return fmt.Errorf("encryption secret of parent checkpoint(%s) is not the same as checkpoint(%s)", parent.Name, ckpt.Name)
+	}
+	return nil
+}
+
+// ValidateContainers checks that containers specified for checkpointing exist in the pod. init containers and
+// ephemeral containers are not supported.
+func ValidateContainers(containers []string, pod *corev1.Pod) error {
+	for _, name := range containers {
+		if !slices.ContainsFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == name }) {
+			return fmt.Errorf("container(%s) is not found in pod(%s)", name, pod.Name)
+		}
+	}
+	if len(lo.Uniq(containers)) != len(containers) {
+		return fmt.Errorf("duplicated containers are specified for pod(%s)", pod.Name)
+	}
+	return nil
+}
+
+func isSameStorage(a, b *v1alpha1.CheckpointSpec) bool {
+	switch {
+	case a.VolumeClaim != nil && b.VolumeClaim != nil:
+		return a.VolumeClaim.ClaimName == b.VolumeClaim.ClaimName
+	case a.ObjectStorage != nil && b.ObjectStorage != nil:
+		return a.ObjectStorage.Endpoint == b.ObjectStorage.Endpoint && a.ObjectStorage.Bucket == b.ObjectStorage.Bucket &&
+			a.ObjectStorage.Prefix == b.ObjectStorage.Prefix
+	case a.Registry != nil && b.Registry != nil:
+		return a.Registry.Repository == b.Registry.Repository
+	}
+	return false
+}
+
+// ValidateStorage checks that exactly one of volume claim, object storage and registry is specified for storing checkpoint data.
+func ValidateStorage(volumeClaim *corev1.PersistentVolumeClaimVolumeSource, objectStorage *v1alpha1.ObjectStorageSource, registry *v1alpha1.RegistrySource) error {
+	if specified := lo.Count([]bool{volumeClaim != nil, objectStorage != nil, registry != nil}, true); specified == 0 {
+		return fmt.Errorf("none of volume claim, object storage and registry is specified")
+	} else if specified > 1 {
+		return fmt.Errorf("only one of volume claim, object storage and registry can be specified")
+	}
+
+	if volumeClaim != nil && len(volumeClaim.ClaimName) == 0 {
+		return fmt.Errorf("claim name of volume claim is not specified")
+	}
+
+	if objectStorage != nil && (len(objectStorage.Endpoint) == 0 || len(objectStorage.Bucket) == 0) {
+		return fmt.Errorf("endpoint or bucket of object storage is not specified")
+	}
+
+	if registry != nil {
+		if _, err := reference.ParseNormalizedNamed(registry.Repository); err != nil {
+			return fmt.Errorf("repository(%s) of registry is invalid, %v", registry.Repository, err)
+		}
+	}
+	return nil
+}
+
+// ValidateRetentionPolicy rejects retention policy for registry storage, because checkpoint artifacts are kept in registry
+// when Checkpoint is deleted, and pruning Checkpoints would not remove them.
+func ValidateRetentionPolicy(retention *v1alpha1.RetentionPolicy, registry *v1alpha1.RegistrySource) error {
+	if retention != nil && registry != nil {
+		return fmt.Errorf("retention policy is not supported for registry, artifacts should be pruned by the retention policy of registry")
+	}
+	return nil
+}
+
+// ObjectStorageKey returns the common prefix of object keys for storing checkpointed data of the named checkpoint.
+func ObjectStorageKey(objectStorage *v1alpha1.ObjectStorageSource, namespace, name string) string {
+	return path.Join(objectStorage.Prefix, namespace, name)
+}
+
+// RegistryRepository returns the repository of checkpoint artifact for the named checkpoint.
+func RegistryRepository(registry *v1alpha1.RegistrySource, namespace, name string) string {
+	return path.Join(registry.Repository, namespace, name)
+}
+
+// SelectRunningPods is used for listing running pods which match owner reference or label selector in the namespace.
+// no pod will be selected if neither owner reference nor selector is specified.
+func SelectRunningPods(ctx context.Context, kubeClient client.Client, namespace string, ownerRef *metav1.OwnerReference, selector *metav1.LabelSelector) ([]corev1.Pod, error) {
+	listOpts := &client.ListOptions{Namespace: namespace}
+	if selector != nil {
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		listOpts.LabelSelector = labelSelector
+	} else if ownerRef == nil {
+		listOpts.LabelSelector = labels.Nothing()
+	}
+
+	var podList corev1.PodList
+	if err := kubeClient.List(ctx, &podList, listOpts); err != nil {
+		return nil, err
+	}
+
+	return lo.Filter(podList.Items, func(pod corev1.Pod, _ int) bool {
+		if pod.Status.Phase != corev1.PodRunning || len(pod.Spec.NodeName) == 0 || !pod.DeletionTimestamp.IsZero() {
+			return false
+		}
+
+		if ownerRef != nil {
+			return lo.ContainsBy(pod.OwnerReferences, func(ref metav1.OwnerReference) bool {
+				return ref.UID == ownerRef.UID
+			})
+		}
+		return true
+	}), nil
+}
+
 // ResolveLastPhaseFromConditions is used for getting the last phase before failed, so state machine can move out of failed state if
 // errors have been fixed.
 func ResolveLastPhaseFromConditions(conditions []metav1.Condition, conditionOrders map[string]int, firstPhase string) string {
@@ -232,3 +525,76 @@ func ResolveLastPhaseFromConditions(conditions []metav1.Condition, conditionOrde
 	}
 	return phase
 }
+
+// PhaseEvent returns the type, reason and message of the event for the transition into phase, reason and message are
+// taken from the condition of the phase. transition into a failed phase is a Warning event, others are Normal events.
+func PhaseEvent(conds []metav1.Condition, phase string, failed bool) (string, string, string) {
+	eventType := corev1.EventTypeNormal
+	if failed {
+		eventType = corev1.EventTypeWarning
+	}
+	cond := meta.FindStatusCondition(conds, phase)
+	if cond == nil {
+		return eventType, phase, fmt.Sprintf("phase is changed to %s", phase)
+	}
+	return eventType, cond.Reason, cond.Message
+}
+
+// PodForEvent returns a pod which is only used as the involved object of events, so events can be recorded on the pod
+// without getting it from kube-apiserver.
+func PodForEvent(namespace, name string, uid types.UID) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			UID:       uid,
+		},
+	}
+}
+
+// StartTrace starts the trace of obj by a root span which begins at the creation of obj, and stores its traceparent into
+// TraceParentAnnotation of obj, so spans of later phases, grit agent and containerd shim are added into the same trace.
+// it returns false when tracing is disabled.
+func StartTrace(ctx context.Context, obj client.Object, spanName string, now time.Time) bool {
+	_, span := otel.Tracer(TracerName).Start(ctx, spanName,
+		trace.WithNewRoot(),
+		trace.WithTimestamp(obj.GetCreationTimestamp().Time),
+		trace.WithAttributes(attribute.String("grit.namespace", obj.GetNamespace()), attribute.String("grit.name", obj.GetName())),
+	)
+	span.End(trace.WithTimestamp(now))
+
+	traceParent := tracing.TraceParent(trace.ContextWithSpan(ctx, span))
+	if len(traceParent) == 0 {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[v1alpha1.TraceParentAnnotation] = traceParent
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// RecordPhaseSpan records a span for the time spent by obj in oldPhase into the trace of obj when obj moves to newPhase,
+// the span starts from the transition time of the condition of oldPhase, and it's marked as error when newPhase is failed.
+func RecordPhaseSpan(ctx context.Context, obj client.Object, conds []metav1.Condition, kind, oldPhase, newPhase string, failed bool, now time.Time) {
+	traceParent := obj.GetAnnotations()[v1alpha1.TraceParentAnnotation]
+	if oldPhase == newPhase || len(oldPhase) == 0 || len(traceParent) == 0 {
+		return
+	}
+	cond := meta.FindStatusCondition(conds, oldPhase)
+	if cond == nil || cond.LastTransitionTime.IsZero() {
+		return
+	}
+
+	_, span := otel.Tracer(TracerName).Start(tracing.ContextWithTraceParent(ctx, traceParent), fmt.Sprintf("%s %s", kind, oldPhase),
+		trace.WithTimestamp(cond.LastTransitionTime.Time),
+		trace.WithAttributes(attribute.String("grit.next_phase", newPhase)),
+	)
+	if failed {
+		_, _, message := PhaseEvent(conds, newPhase, failed)
+		span.SetStatus(codes.Error, message)
+	}
+	span.End(trace.WithTimestamp(now))
+}
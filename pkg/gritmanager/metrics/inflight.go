@@ -0,0 +1,80 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+var (
+	checkpointsInFlightDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "checkpoint", "in_flight"),
+		"Number of Checkpoints in Pending or Checkpointing phase on each node.",
+		[]string{"node"}, nil,
+	)
+	restoresInFlightDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "restore", "in_flight"),
+		"Number of Restores in Pending or Restoring phase on each node, node is empty before restoration pod is scheduled.",
+		[]string{"node"}, nil,
+	)
+)
+
+// InFlightCollector counts in-flight Checkpoints and Restores per node from the cache when metrics are scraped, so the
+// gauges are always consistent with the cluster state even if grit-manager restarts.
+type InFlightCollector struct {
+	reader client.Reader
+}
+
+func NewInFlightCollector(reader client.Reader) *InFlightCollector {
+	return &InFlightCollector{
+		reader: reader,
+	}
+}
+
+func (c *InFlightCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- checkpointsInFlightDesc
+	ch <- restoresInFlightDesc
+}
+
+func (c *InFlightCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var ckptList v1alpha1.CheckpointList
+	if err := c.reader.List(ctx, &ckptList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list checkpoints for metrics")
+	} else {
+		counts := make(map[string]int)
+		for i := range ckptList.Items {
+			if phase := ckptList.Items[i].Status.Phase; phase == v1alpha1.CheckpointPending || phase == v1alpha1.Checkpointing {
+				counts[ckptList.Items[i].Status.NodeName]++
+			}
+		}
+		for node, count := range counts {
+			ch <- prometheus.MustNewConstMetric(checkpointsInFlightDesc, prometheus.GaugeValue, float64(count), node)
+		}
+	}
+
+	var restoreList v1alpha1.RestoreList
+	if err := c.reader.List(ctx, &restoreList); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list restores for metrics")
+	} else {
+		counts := make(map[string]int)
+		for i := range restoreList.Items {
+			if phase := restoreList.Items[i].Status.Phase; phase == v1alpha1.RestorePending || phase == v1alpha1.Restoring {
+				counts[restoreList.Items[i].Status.NodeName]++
+			}
+		}
+		for node, count := range counts {
+			ch <- prometheus.MustNewConstMetric(restoresInFlightDesc, prometheus.GaugeValue, float64(count), node)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/metadata"
+)
+
+const (
+	namespace = "grit"
+
+	ActionCheckpoint = "checkpoint"
+	ActionRestore    = "restore"
+)
+
+var (
+	// phase durations are observed when the object leaves the phase, so time spent in criu dump and data transfer
+	// (Checkpointing), pod rescheduling(Pending of Restore, RemovingPod of Migration) can be compared.
+	CheckpointPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "checkpoint",
+		Name:      "phase_duration_seconds",
+		Help:      "Time spent by Checkpoint in each phase.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 14),
+	}, []string{"phase"})
+	RestorePhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "restore",
+		Name:      "phase_duration_seconds",
+		Help:      "Time spent by Restore in each phase.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 14),
+	}, []string{"phase"})
+	MigrationPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "migration",
+		Name:      "phase_duration_seconds",
+		Help:      "Time spent by Migration in each phase.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 14),
+	}, []string{"phase"})
+
+	// outcomes are counted when the object reaches a terminal phase, and reason is the reason of the condition of that phase.
+	CheckpointsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "checkpoint",
+		Name:      "completed_total",
+		Help:      "Number of Checkpoints which are checkpointed, failed or cancelled, broken down by reason.",
+	}, []string{"phase", "reason"})
+	RestoresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "restore",
+		Name:      "completed_total",
+		Help:      "Number of Restores which are restored or failed, broken down by reason.",
+	}, []string{"phase", "reason"})
+	MigrationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "migration",
+		Name:      "completed_total",
+		Help:      "Number of Migrations which are migrated or failed, broken down by reason.",
+	}, []string{"phase", "reason"})
+
+	// measurements of grit agent are reported through its termination message, because grit agent is a short-lived job.
+	AgentDataBytes = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "data_bytes",
+		Help:      "Size of data handled by grit agent, kind is dumped(criu images), rootfs_diff or transferred.",
+		Buckets:   prometheus.ExponentialBuckets(1<<20, 4, 10),
+	}, []string{"action", "kind"})
+	AgentStepDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "step_duration_seconds",
+		Help:      "Time spent by grit agent in each step, step is dump or transfer.",
+		Buckets:   prometheus.ExponentialBuckets(0.5, 2, 14),
+	}, []string{"action", "step"})
+	AgentTransferThroughput = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "agent",
+		Name:      "transfer_throughput_bytes_per_second",
+		Help:      "Throughput of data transfer between the node and the storage by grit agent.",
+		Buckets:   prometheus.ExponentialBuckets(1<<20, 2, 12),
+	}, []string{"action"})
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(
+		CheckpointPhaseDuration,
+		RestorePhaseDuration,
+		MigrationPhaseDuration,
+		CheckpointsTotal,
+		RestoresTotal,
+		MigrationsTotal,
+		AgentDataBytes,
+		AgentStepDuration,
+		AgentTransferThroughput,
+	)
+}
+
+// RecordCheckpointPhase records metrics when Checkpoint moves from the phase of old to the phase of updated.
+func RecordCheckpointPhase(old, updated *v1alpha1.Checkpoint, now time.Time) {
+	terminal := updated.Status.Phase == v1alpha1.Checkpointed || updated.Status.Phase == v1alpha1.CheckpointFailed || updated.Status.Phase == v1alpha1.CheckpointCancelled
+	recordPhase(CheckpointPhaseDuration, CheckpointsTotal, old.Status.Conditions, updated.Status.Conditions, string(old.Status.Phase), string(updated.Status.Phase), terminal, now)
+}
+
+// RecordRestorePhase records metrics when Restore moves from the phase of old to the phase of updated.
+func RecordRestorePhase(old, updated *v1alpha1.Restore, now time.Time) {
+	terminal := updated.Status.Phase == v1alpha1.Restored || updated.Status.Phase == v1alpha1.RestoreFailed
+	recordPhase(RestorePhaseDuration, RestoresTotal, old.Status.Conditions, updated.Status.Conditions, string(old.Status.Phase), string(updated.Status.Phase), terminal, now)
+}
+
+// RecordMigrationPhase records metrics when Migration moves from the phase of old to the phase of updated.
+func RecordMigrationPhase(old, updated *v1alpha1.Migration, now time.Time) {
+	terminal := updated.Status.Phase == v1alpha1.Migrated || updated.Status.Phase == v1alpha1.MigrationFailed
+	recordPhase(MigrationPhaseDuration, MigrationsTotal, old.Status.Conditions, updated.Status.Conditions, string(old.Status.Phase), string(updated.Status.Phase), terminal, now)
+}
+
+// recordPhase observes the time spent in old phase, which starts from the transition time of the condition with the same
+// type of phase, and counts the outcome when new phase is terminal.
+func recordPhase(durations *prometheus.HistogramVec, outcomes *prometheus.CounterVec, oldConds, newConds []metav1.Condition, oldPhase, newPhase string, terminal bool, now time.Time) {
+	if oldPhase == newPhase {
+		return
+	}
+
+	if d, ok := phaseDuration(oldConds, oldPhase, now); ok {
+		durations.WithLabelValues(oldPhase).Observe(d.Seconds())
+	}
+
+	if terminal {
+		var reason string
+		if cond := meta.FindStatusCondition(newConds, newPhase); cond != nil {
+			reason = cond.Reason
+		}
+		outcomes.WithLabelValues(newPhase, reason).Inc()
+	}
+}
+
+// phaseDuration returns the time since the condition of phase became true.
+func phaseDuration(conds []metav1.Condition, phase string, now time.Time) (time.Duration, bool) {
+	if len(phase) == 0 {
+		return 0, false
+	}
+	cond := meta.FindStatusCondition(conds, phase)
+	if cond == nil || cond.LastTransitionTime.IsZero() {
+		return 0, false
+	}
+	return max(now.Sub(cond.LastTransitionTime.Time), 0), true
+}
+
+// RecordAgentReport records data sizes, step durations and transfer throughput reported by grit agent of action.
+func RecordAgentReport(action string, report *metadata.AgentReport) {
+	if report.DumpedSize != 0 {
+		AgentDataBytes.WithLabelValues(action, "dumped").Observe(float64(report.DumpedSize))
+	}
+	if report.RootfsDiffSize != 0 {
+		AgentDataBytes.WithLabelValues(action, "rootfs_diff").Observe(float64(report.RootfsDiffSize))
+	}
+	if report.TransferredSize != 0 {
+		AgentDataBytes.WithLabelValues(action, "transferred").Observe(float64(report.TransferredSize))
+	}
+	if report.DumpSeconds != 0 {
+		AgentStepDuration.WithLabelValues(action, "dump").Observe(report.DumpSeconds)
+	}
+	if report.TransferSeconds != 0 {
+		AgentStepDuration.WithLabelValues(action, "transfer").Observe(report.TransferSeconds)
+		AgentTransferThroughput.WithLabelValues(action).Observe(float64(report.TransferredSize) / report.TransferSeconds)
+	}
+}
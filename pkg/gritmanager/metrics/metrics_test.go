@@ -0,0 +1,40 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+func TestRecordCheckpointPhase(t *testing.T) {
+	now := time.Now()
+	old := &v1alpha1.Checkpoint{
+		Status: v1alpha1.CheckpointStatus{
+			Phase: v1alpha1.Checkpointing,
+			Conditions: []metav1.Condition{
+				{Type: string(v1alpha1.Checkpointing), Status: metav1.ConditionTrue, Reason: "GritAgentIsCreated", LastTransitionTime: metav1.NewTime(now.Add(-time.Minute))},
+			},
+		},
+	}
+	updated := old.DeepCopy()
+	updated.Status.Phase = v1alpha1.CheckpointFailed
+	updated.Status.Conditions = append(updated.Status.Conditions, metav1.Condition{Type: string(v1alpha1.CheckpointFailed), Status: metav1.ConditionTrue, Reason: "DeadlineExceeded", LastTransitionTime: metav1.NewTime(now)})
+
+	RecordCheckpointPhase(old, updated, now)
+	// phase is not changed, so nothing is recorded again.
+	RecordCheckpointPhase(updated, updated, now)
+
+	if count := testutil.CollectAndCount(CheckpointPhaseDuration); count != 1 {
+		t.Errorf("expected 1 phase duration series, got %d", count)
+	}
+	if value := testutil.ToFloat64(CheckpointsTotal.WithLabelValues(string(v1alpha1.CheckpointFailed), "DeadlineExceeded")); value != 1 {
+		t.Errorf("expected 1 failed checkpoint with reason DeadlineExceeded, got %v", value)
+	}
+}
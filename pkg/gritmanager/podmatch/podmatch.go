@@ -0,0 +1,184 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+// Package podmatch provides owner-aware strategies for matching the restoration pod with the checkpointed pod. the
+// new pod recreated by the owner is matched by the owner and the identity which is kept by the owner across
+// recreations, like the ordinal of StatefulSet pod or the completion index of Indexed Job pod.
+package podmatch
+
+import (
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Strategy matches restoration pod with checkpointed pod for a kind of owner.
+type Strategy interface {
+	// Name returns the name of strategy.
+	Name() string
+	// Applies returns whether the strategy should be used for the pod.
+	Applies(pod *corev1.Pod) bool
+	// Identity returns the identity of pod within its owner, and restoration pod should have the same identity as
+	// checkpointed pod. nil means all pods of the owner are interchangeable, like pods of ReplicaSet.
+	Identity(pod *corev1.Pod) map[string]string
+	// OwnerMatches returns whether the pod is recreated by the owner of checkpointed pod which is referenced by ownerRef.
+	OwnerMatches(ownerRef *metav1.OwnerReference, pod *corev1.Pod) bool
+}
+
+var (
+	mu sync.RWMutex
+	// strategies are checked in order, and custom strategies are registered before builtin strategies.
+	strategies []Strategy
+	builtin    = []Strategy{
+		JobSetStrategy,
+		LeaderWorkerSetStrategy,
+		&statefulSetStrategy{},
+		&indexedJobStrategy{},
+	}
+)
+
+// Register registers a custom strategy, like a strategy for pods managed by a CRD owner. custom strategies take
+// precedence over builtin strategies.
+func Register(strategy Strategy) {
+	mu.Lock()
+	defer mu.Unlock()
+	strategies = append(strategies, strategy)
+}
+
+// For returns the strategy which should be used for the pod, and owner reference strategy is returned by default.
+func For(pod *corev1.Pod) Strategy {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, s := range append(append([]Strategy{}, strategies...), builtin...) {
+		if s.Applies(pod) {
+			return s
+		}
+	}
+	return &ownerStrategy{}
+}
+
+// ownerStrategy matches pod by the owner reference, and all pods of the owner are interchangeable, like pods of ReplicaSet.
+type ownerStrategy struct{}
+
+func (s *ownerStrategy) Name() string {
+	return "OwnerReference"
+}
+
+func (s *ownerStrategy) Applies(_ *corev1.Pod) bool {
+	return true
+}
+
+func (s *ownerStrategy) Identity(_ *corev1.Pod) map[string]string {
+	return nil
+}
+
+func (s *ownerStrategy) OwnerMatches(ownerRef *metav1.OwnerReference, pod *corev1.Pod) bool {
+	for _, ref := range pod.OwnerReferences {
+		if ref.UID == ownerRef.UID && ref.Kind == ownerRef.Kind && ref.APIVersion == ownerRef.APIVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// statefulSetStrategy matches pod by the pod name which is bound to the ordinal, because StatefulSet recreates pod with the same name.
+type statefulSetStrategy struct {
+	ownerStrategy
+}
+
+func (s *statefulSetStrategy) Name() string {
+	return "StatefulSet"
+}
+
+func (s *statefulSetStrategy) Applies(pod *corev1.Pod) bool {
+	return isControlledBy(pod, appsv1.SchemeGroupVersion.String(), "StatefulSet")
+}
+
+func (s *statefulSetStrategy) Identity(pod *corev1.Pod) map[string]string {
+	name := pod.Labels[appsv1.StatefulSetPodNameLabel]
+	if len(name) == 0 {
+		name = pod.Name
+	}
+	return map[string]string{appsv1.StatefulSetPodNameLabel: name}
+}
+
+// indexedJobStrategy matches pod by the completion index, because Indexed Job recreates failed pod with the same index.
+type indexedJobStrategy struct {
+	ownerStrategy
+}
+
+func (s *indexedJobStrategy) Name() string {
+	return "IndexedJob"
+}
+
+func (s *indexedJobStrategy) Applies(pod *corev1.Pod) bool {
+	_, ok := pod.Annotations[batchv1.JobCompletionIndexAnnotation]
+	return ok && isControlledBy(pod, batchv1.SchemeGroupVersion.String(), "Job")
+}
+
+func (s *indexedJobStrategy) Identity(pod *corev1.Pod) map[string]string {
+	return map[string]string{batchv1.JobCompletionIndexAnnotation: pod.Annotations[batchv1.JobCompletionIndexAnnotation]}
+}
+
+// LabelStrategy matches pods which are managed by a CRD owner through labels, like JobSet and LeaderWorkerSet. CRD owner
+// may recreate the direct owner of pod(like Job of JobSet) with a new uid, so owner is matched by OwnerLabel and the
+// kind of direct owner instead of uid, and identity is composed of IdentityLabels and IdentityAnnotations.
+type LabelStrategy struct {
+	StrategyName string
+	// OwnerLabel is the label whose value is the name of CRD owner, the strategy applies to pods with this label.
+	OwnerLabel          string
+	IdentityLabels      []string
+	IdentityAnnotations []string
+}
+
+func (s *LabelStrategy) Name() string {
+	return s.StrategyName
+}
+
+func (s *LabelStrategy) Applies(pod *corev1.Pod) bool {
+	_, ok := pod.Labels[s.OwnerLabel]
+	return ok
+}
+
+func (s *LabelStrategy) Identity(pod *corev1.Pod) map[string]string {
+	identity := map[string]string{s.OwnerLabel: pod.Labels[s.OwnerLabel]}
+	for _, key := range s.IdentityLabels {
+		if value, ok := pod.Labels[key]; ok {
+			identity[key] = value
+		}
+	}
+	for _, key := range s.IdentityAnnotations {
+		if value, ok := pod.Annotations[key]; ok {
+			identity[key] = value
+		}
+	}
+	return identity
+}
+
+func (s *LabelStrategy) OwnerMatches(ownerRef *metav1.OwnerReference, pod *corev1.Pod) bool {
+	return isControlledBy(pod, ownerRef.APIVersion, ownerRef.Kind)
+}
+
+var (
+	// JobSetStrategy matches pods of JobSet by the replicated job, job index and completion index.
+	JobSetStrategy = &LabelStrategy{
+		StrategyName:        "JobSet",
+		OwnerLabel:          "jobset.sigs.k8s.io/jobset-name",
+		IdentityLabels:      []string{"jobset.sigs.k8s.io/replicatedjob-name", "jobset.sigs.k8s.io/job-index"},
+		IdentityAnnotations: []string{batchv1.JobCompletionIndexAnnotation},
+	}
+	// LeaderWorkerSetStrategy matches pods of LeaderWorkerSet by the group index and worker index.
+	LeaderWorkerSetStrategy = &LabelStrategy{
+		StrategyName:   "LeaderWorkerSet",
+		OwnerLabel:     "leaderworkerset.sigs.k8s.io/name",
+		IdentityLabels: []string{"leaderworkerset.sigs.k8s.io/group-index", "leaderworkerset.sigs.k8s.io/worker-index"},
+	}
+)
+
+func isControlledBy(pod *corev1.Pod, apiVersion, kind string) bool {
+	ownerRef := metav1.GetControllerOf(pod)
+	return ownerRef != nil && ownerRef.APIVersion == apiVersion && ownerRef.Kind == kind
+}
@@ -0,0 +1,100 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package podmatch
+
+import (
+	"maps"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+func newPod(name, apiVersion, kind, uid string, labels, annotations map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: apiVersion, Kind: kind, UID: types.UID(uid), Controller: ptr.To(true)},
+			},
+		},
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	testcases := map[string]struct {
+		checkpointed *corev1.Pod
+		restoration  *corev1.Pod
+		strategy     string
+		expectMatch  bool
+	}{
+		"pods of replicaset are interchangeable": {
+			checkpointed: newPod("web-abc", "apps/v1", "ReplicaSet", "rs-1", nil, nil),
+			restoration:  newPod("web-def", "apps/v1", "ReplicaSet", "rs-1", nil, nil),
+			strategy:     "OwnerReference",
+			expectMatch:  true,
+		},
+		"pod of another replicaset": {
+			checkpointed: newPod("web-abc", "apps/v1", "ReplicaSet", "rs-1", nil, nil),
+			restoration:  newPod("web-def", "apps/v1", "ReplicaSet", "rs-2", nil, nil),
+			strategy:     "OwnerReference",
+			expectMatch:  false,
+		},
+		"statefulset pod with the same ordinal": {
+			checkpointed: newPod("db-1", "apps/v1", "StatefulSet", "sts-1", map[string]string{"statefulset.kubernetes.io/pod-name": "db-1"}, nil),
+			restoration:  newPod("db-1", "apps/v1", "StatefulSet", "sts-1", map[string]string{"statefulset.kubernetes.io/pod-name": "db-1"}, nil),
+			strategy:     "StatefulSet",
+			expectMatch:  true,
+		},
+		"statefulset pod with another ordinal": {
+			checkpointed: newPod("db-1", "apps/v1", "StatefulSet", "sts-1", map[string]string{"statefulset.kubernetes.io/pod-name": "db-1"}, nil),
+			restoration:  newPod("db-0", "apps/v1", "StatefulSet", "sts-1", map[string]string{"statefulset.kubernetes.io/pod-name": "db-0"}, nil),
+			strategy:     "StatefulSet",
+			expectMatch:  false,
+		},
+		"indexed job pod with another completion index": {
+			checkpointed: newPod("train-0-abc", "batch/v1", "Job", "job-1", nil, map[string]string{"batch.kubernetes.io/job-completion-index": "0"}),
+			restoration:  newPod("train-1-def", "batch/v1", "Job", "job-1", nil, map[string]string{"batch.kubernetes.io/job-completion-index": "1"}),
+			strategy:     "IndexedJob",
+			expectMatch:  false,
+		},
+		"jobset pod recreated by a new job": {
+			checkpointed: newPod("js-workers-0-0-abc", "batch/v1", "Job", "job-1",
+				map[string]string{"jobset.sigs.k8s.io/jobset-name": "js", "jobset.sigs.k8s.io/replicatedjob-name": "workers", "jobset.sigs.k8s.io/job-index": "0"},
+				map[string]string{"batch.kubernetes.io/job-completion-index": "0"}),
+			restoration: newPod("js-workers-0-0-def", "batch/v1", "Job", "job-2",
+				map[string]string{"jobset.sigs.k8s.io/jobset-name": "js", "jobset.sigs.k8s.io/replicatedjob-name": "workers", "jobset.sigs.k8s.io/job-index": "0"},
+				map[string]string{"batch.kubernetes.io/job-completion-index": "0"}),
+			strategy:    "JobSet",
+			expectMatch: true,
+		},
+		"leaderworkerset pod of another group": {
+			checkpointed: newPod("lws-0-1", "apps/v1", "StatefulSet", "sts-1",
+				map[string]string{"leaderworkerset.sigs.k8s.io/name": "lws", "leaderworkerset.sigs.k8s.io/group-index": "0", "leaderworkerset.sigs.k8s.io/worker-index": "1"}, nil),
+			restoration: newPod("lws-1-1", "apps/v1", "StatefulSet", "sts-2",
+				map[string]string{"leaderworkerset.sigs.k8s.io/name": "lws", "leaderworkerset.sigs.k8s.io/group-index": "1", "leaderworkerset.sigs.k8s.io/worker-index": "1"}, nil),
+			strategy:    "LeaderWorkerSet",
+			expectMatch: false,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			identity := For(tc.checkpointed).Identity(tc.checkpointed)
+			ownerRef := metav1.GetControllerOf(tc.checkpointed)
+
+			strategy := For(tc.restoration)
+			if strategy.Name() != tc.strategy {
+				t.Fatalf("expected strategy %s, got %s", tc.strategy, strategy.Name())
+			}
+			if match := strategy.OwnerMatches(ownerRef, tc.restoration) && maps.Equal(strategy.Identity(tc.restoration), identity); match != tc.expectMatch {
+				t.Fatalf("expected match %v, got %v", tc.expectMatch, match)
+			}
+		})
+	}
+}
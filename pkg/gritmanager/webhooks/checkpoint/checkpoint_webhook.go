@@ -52,6 +52,10 @@ func (w *CheckpointWebhook) ValidateCreate(ctx context.Context, obj runtime.Obje
 		return admission.Warnings{}, fmt.Errorf("pod(%s) referenced by chekcpoint(%s) is not running", pod.Name, ckpt.Name)
 	}
 
+	if err := util.ValidateContainers(ckpt.Spec.Containers, &pod); err != nil {
+		return admission.Warnings{}, fmt.Errorf("containers of checkpoint(%s) are invalid, %v", ckpt.Name, err)
+	}
+
 	var node corev1.Node
 	if err := w.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, &node); err != nil {
 		return admission.Warnings{}, err
@@ -62,20 +66,57 @@ func (w *CheckpointWebhook) ValidateCreate(ctx context.Context, obj runtime.Obje
 		return admission.Warnings{}, fmt.Errorf("node(%s) referenced by pod(%s) and checkpoint(%s) is not ready", node.Name, pod.Name, ckpt.Name)
 	}
 
-	//validate pvc
-	var pvc corev1.PersistentVolumeClaim
-	if err := w.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.VolumeClaim.ClaimName}, &pvc); err != nil {
-		return admission.Warnings{}, err
+	// validate storage
+	if err := util.ValidateStorage(ckpt.Spec.VolumeClaim, ckpt.Spec.ObjectStorage, ckpt.Spec.Registry); err != nil {
+		return admission.Warnings{}, fmt.Errorf("storage of checkpoint(%s) is invalid, %v", ckpt.Name, err)
 	}
+	if err := util.ValidateRetentionPolicy(ckpt.Spec.RetentionPolicy, ckpt.Spec.Registry); err != nil {
+		return admission.Warnings{}, fmt.Errorf("retention policy of checkpoint(%s) is invalid, %v", ckpt.Name, err)
+	}
+
+	if ckpt.Spec.VolumeClaim != nil {
+		var pvc corev1.PersistentVolumeClaim
+		if err := w.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.VolumeClaim.ClaimName}, &pvc); err != nil {
+			return admission.Warnings{}, err
+		}
 
-	if pvc.Status.Phase != corev1.ClaimBound {
-		return admission.Warnings{}, fmt.Errorf("pvc(%s) is not bound", ckpt.Spec.VolumeClaim.ClaimName)
+		if pvc.Status.Phase != corev1.ClaimBound {
+			return admission.Warnings{}, fmt.Errorf("pvc(%s) is not bound", ckpt.Spec.VolumeClaim.ClaimName)
+		}
 	}
 
+	// validate parent checkpoint for incremental checkpoint
+	if len(ckpt.Spec.ParentCheckpointName) != 0 {
+		var parent v1alpha1.Checkpoint
+		if err := w.Get(ctx, client.ObjectKey{Namespace: ckpt.Namespace, Name: ckpt.Spec.ParentCheckpointName}, &parent); err != nil {
+			return admission.Warnings{}, err
+		}
+
+		if err := util.ValidateParentCheckpoint(ckpt, &parent, &pod); err != nil {
+			return admission.Warnings{}, err
+		}
+	}
+
+	if ckpt.Spec.AutoMigration {
+		return admission.Warnings{"autoMigration is deprecated and ignored, create a Migration for migrating the pod instead"}, nil
+	}
 	return admission.Warnings{}, nil
 }
 
 func (w *CheckpointWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	oldCkpt, ok := oldObj.(*v1alpha1.Checkpoint)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a checkpoint object but got a different type")
+	}
+	newCkpt, ok := newObj.(*v1alpha1.Checkpoint)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a checkpoint object but got a different type")
+	}
+
+	// grit agent job has been removed for cancelled checkpoint, so it can't be resumed.
+	if oldCkpt.Spec.Cancel && !newCkpt.Spec.Cancel {
+		return admission.Warnings{}, fmt.Errorf("cancel of checkpoint(%s) can't be reverted", newCkpt.Name)
+	}
 	return admission.Warnings{}, nil
 }
 
@@ -93,7 +134,7 @@ func isNodeReady(node *corev1.Node) bool {
 	return false
 }
 
-// +kubebuilder:webhook:path=/validate-kaito-sh-v1alpha1-checkpoint,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1,groups="kaito.sh",resources=checkpoints,verbs=create,versions=v1alpha1,name=validating.checkpoints.kaito.sh
+// +kubebuilder:webhook:path=/validate-kaito-sh-v1alpha1-checkpoint,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1,groups="kaito.sh",resources=checkpoints,verbs=create;update,versions=v1alpha1,name=validating.checkpoints.kaito.sh
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch
 
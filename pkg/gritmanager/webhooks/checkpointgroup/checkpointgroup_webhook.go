@@ -0,0 +1,87 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpointgroup
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type CheckpointGroupWebhook struct {
+	client.Client
+	clk clock.Clock
+}
+
+func NewCheckpointGroupWebhook(clk clock.Clock, client client.Client) *CheckpointGroupWebhook {
+	return &CheckpointGroupWebhook{
+		Client: client,
+		clk:    clk,
+	}
+}
+
+func (w *CheckpointGroupWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	ctx = util.WithWebhookName(ctx, "checkpointgroup.validate")
+	group, ok := obj.(*v1alpha1.CheckpointGroup)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a checkpoint group object but got a different type")
+	}
+
+	if group.Spec.OwnerRef == nil && group.Spec.Selector == nil {
+		return admission.Warnings{}, fmt.Errorf("neither owner reference nor selector is specified in checkpoint group(%s)", group.Name)
+	} else if group.Spec.OwnerRef != nil && group.Spec.Selector != nil {
+		return admission.Warnings{}, fmt.Errorf("only one of owner reference and selector can be specified in checkpoint group(%s)", group.Name)
+	}
+
+	if group.Spec.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(group.Spec.Selector); err != nil {
+			return admission.Warnings{}, fmt.Errorf("selector of checkpoint group(%s) is invalid, %v", group.Name, err)
+		}
+	}
+
+	if err := util.ValidateStorage(group.Spec.VolumeClaim, group.Spec.ObjectStorage, nil); err != nil {
+		return admission.Warnings{}, fmt.Errorf("storage of checkpoint group(%s) is invalid, %v", group.Name, err)
+	}
+
+	if group.Spec.VolumeClaim != nil {
+		var pvc corev1.PersistentVolumeClaim
+		if err := w.Get(ctx, client.ObjectKey{Namespace: group.Namespace, Name: group.Spec.VolumeClaim.ClaimName}, &pvc); err != nil {
+			return admission.Warnings{}, err
+		}
+
+		if pvc.Status.Phase != corev1.ClaimBound {
+			return admission.Warnings{}, fmt.Errorf("pvc(%s) is not bound", group.Spec.VolumeClaim.ClaimName)
+		}
+	}
+
+	return admission.Warnings{}, nil
+}
+
+func (w *CheckpointGroupWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+func (w *CheckpointGroupWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+// +kubebuilder:webhook:path=/validate-kaito-sh-v1alpha1-checkpointgroup,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1,groups="kaito.sh",resources=checkpointgroups,verbs=create,versions=v1alpha1,name=validating.checkpointgroups.kaito.sh
+
+func (w *CheckpointGroupWebhook) Register(_ context.Context, mgr manager.Manager) error {
+	return controllerruntime.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.CheckpointGroup{}).
+		WithValidator(w).
+		Complete()
+}
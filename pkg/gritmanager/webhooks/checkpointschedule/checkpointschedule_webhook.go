@@ -0,0 +1,91 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package checkpointschedule
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type CheckpointScheduleWebhook struct {
+	client.Client
+	clk clock.Clock
+}
+
+func NewCheckpointScheduleWebhook(clk clock.Clock, client client.Client) *CheckpointScheduleWebhook {
+	return &CheckpointScheduleWebhook{
+		Client: client,
+		clk:    clk,
+	}
+}
+
+func (w *CheckpointScheduleWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	schedule, ok := obj.(*v1alpha1.CheckpointSchedule)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a checkpoint schedule object but got a different type")
+	}
+
+	return admission.Warnings{}, validateCheckpointSchedule(schedule)
+}
+
+func (w *CheckpointScheduleWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	schedule, ok := newObj.(*v1alpha1.CheckpointSchedule)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a checkpoint schedule object but got a different type")
+	}
+
+	return admission.Warnings{}, validateCheckpointSchedule(schedule)
+}
+
+func (w *CheckpointScheduleWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+func validateCheckpointSchedule(schedule *v1alpha1.CheckpointSchedule) error {
+	if _, err := cron.ParseStandard(schedule.Spec.Schedule); err != nil {
+		return fmt.Errorf("schedule(%s) of checkpoint schedule(%s) is invalid, %v", schedule.Spec.Schedule, schedule.Name, err)
+	}
+
+	if schedule.Spec.OwnerRef == nil && schedule.Spec.Selector == nil {
+		return fmt.Errorf("neither owner reference nor selector is specified in checkpoint schedule(%s)", schedule.Name)
+	} else if schedule.Spec.OwnerRef != nil && schedule.Spec.Selector != nil {
+		return fmt.Errorf("only one of owner reference and selector can be specified in checkpoint schedule(%s)", schedule.Name)
+	}
+
+	if schedule.Spec.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(schedule.Spec.Selector); err != nil {
+			return fmt.Errorf("selector of checkpoint schedule(%s) is invalid, %v", schedule.Name, err)
+		}
+	}
+
+	if err := util.ValidateStorage(schedule.Spec.VolumeClaim, schedule.Spec.ObjectStorage, schedule.Spec.Registry); err != nil {
+		return fmt.Errorf("storage of checkpoint schedule(%s) is invalid, %v", schedule.Name, err)
+	}
+	if err := util.ValidateRetentionPolicy(schedule.Spec.RetentionPolicy, schedule.Spec.Registry); err != nil {
+		return fmt.Errorf("retention policy of checkpoint schedule(%s) is invalid, %v", schedule.Name, err)
+	}
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-kaito-sh-v1alpha1-checkpointschedule,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1,groups="kaito.sh",resources=checkpointschedules,verbs=create;update,versions=v1alpha1,name=validating.checkpointschedules.kaito.sh
+
+func (w *CheckpointScheduleWebhook) Register(_ context.Context, mgr manager.Manager) error {
+	return controllerruntime.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.CheckpointSchedule{}).
+		WithValidator(w).
+		Complete()
+}
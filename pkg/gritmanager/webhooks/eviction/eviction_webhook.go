@@ -0,0 +1,141 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package eviction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+const webhookPath = "/validate-core-v1-pod-eviction"
+
+// EvictionWebhook checkpoints pods before they are evicted(like kubectl drain and cluster-autoscaler scale-down). for
+// pods which opt in by CheckpointBeforeEvictionAnnotation, the first eviction is denied and a Migration is created for
+// the pod, then evictions are allowed after the pod is checkpointed, and the new pod is restored from the checkpoint.
+type EvictionWebhook struct {
+	client.Client
+}
+
+func NewEvictionWebhook(client client.Client) *EvictionWebhook {
+	return &EvictionWebhook{
+		Client: client,
+	}
+}
+
+func (w *EvictionWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	ctx = util.WithWebhookName(ctx, "pod.eviction")
+	var pod corev1.Pod
+	if err := w.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: req.Name}, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	template, ok := pod.Annotations[v1alpha1.CheckpointBeforeEvictionAnnotation]
+	if !ok {
+		return admission.Allowed("")
+	}
+
+	// only running pod with owner can be migrated, so other pods are evicted directly.
+	if pod.Status.Phase != corev1.PodRunning || metav1.GetControllerOf(&pod) == nil {
+		return admission.Allowed("").WithWarnings(fmt.Sprintf("pod(%s) can not be checkpointed before eviction, because it's not running or has no owner", pod.Name))
+	}
+
+	var migrationList v1alpha1.MigrationList
+	if err := w.List(ctx, &migrationList, client.InNamespace(pod.Namespace), client.MatchingLabels{v1alpha1.EvictedPodUIDLabel: string(pod.UID)}); err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if len(migrationList.Items) != 0 {
+		latest := slices.MaxFunc(migrationList.Items, func(a, b v1alpha1.Migration) int {
+			return a.CreationTimestamp.Compare(b.CreationTimestamp.Time)
+		})
+		return evictionResponse(&latest)
+	}
+
+	if req.DryRun != nil && *req.DryRun {
+		return tooManyRequests(fmt.Sprintf("pod(%s) will be checkpointed before eviction", pod.Name))
+	}
+
+	migration, err := newMigration(&pod, template)
+	if err == nil {
+		err = w.Create(ctx, migration)
+	}
+	if err != nil {
+		// eviction should not be blocked by invalid template or storage, otherwise node can't be drained.
+		log.FromContext(ctx).Error(err, "failed to create migration before eviction", "namespace", pod.Namespace, "pod", pod.Name)
+		return admission.Allowed("").WithWarnings(fmt.Sprintf("pod(%s) is evicted without checkpoint, failed to create migration, %v", pod.Name, err))
+	}
+
+	log.FromContext(ctx).Info("migration is created before eviction", "namespace", pod.Namespace, "pod", pod.Name, "migration", migration.Name)
+	return tooManyRequests(fmt.Sprintf("pod(%s) is being checkpointed by migration(%s) before eviction, retry later", pod.Name, migration.Name))
+}
+
+// newMigration creates a Migration for the pod from the template, which is a json encoded MigrationSpec without podName.
+// pod is evicted instead of deleted by default, so PodDisruptionBudget is still respected.
+func newMigration(pod *corev1.Pod, template string) (*v1alpha1.Migration, error) {
+	var spec v1alpha1.MigrationSpec
+	if err := json.Unmarshal([]byte(template), &spec); err != nil {
+		return nil, fmt.Errorf("invalid migration template in annotation %s, %w", v1alpha1.CheckpointBeforeEvictionAnnotation, err)
+	}
+	spec.PodName = pod.Name
+	if len(spec.PodRemovalPolicy) == 0 {
+		spec.PodRemovalPolicy = v1alpha1.PodRemovalEvict
+	}
+
+	return &v1alpha1.Migration{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-", pod.Name),
+			Namespace:    pod.Namespace,
+			Labels: map[string]string{
+				v1alpha1.EvictedPodUIDLabel: string(pod.UID),
+			},
+		},
+		Spec: spec,
+	}, nil
+}
+
+// evictionResponse allows eviction after the pod has been checkpointed by migration, and failed migration doesn't block eviction.
+func evictionResponse(migration *v1alpha1.Migration) admission.Response {
+	switch migration.Status.Phase {
+	case v1alpha1.MigrationRemovingPod, v1alpha1.MigrationRestoring, v1alpha1.Migrated:
+		return admission.Allowed(fmt.Sprintf("pod is checkpointed by migration(%s)", migration.Name))
+	case v1alpha1.MigrationFailed:
+		return admission.Allowed("").WithWarnings(fmt.Sprintf("pod(%s) is evicted without checkpoint, migration(%s) failed", migration.Spec.PodName, migration.Name))
+	default:
+		return tooManyRequests(fmt.Sprintf("pod(%s) is being checkpointed by migration(%s) before eviction, retry later", migration.Spec.PodName, migration.Name))
+	}
+}
+
+// tooManyRequests denies eviction with 429 as PodDisruptionBudget does, so kubectl drain and cluster-autoscaler retry eviction later.
+func tooManyRequests(message string) admission.Response {
+	resp := admission.Denied(message)
+	resp.Result.Code = http.StatusTooManyRequests
+	resp.Result.Reason = metav1.StatusReasonTooManyRequests
+	return resp
+}
+
+// +kubebuilder:webhook:path=/validate-core-v1-pod-eviction,mutating=false,failurePolicy=ignore,sideEffects=NoneOnDryRun,admissionReviewVersions=v1,groups="",resources=pods/eviction,verbs=create,versions=v1,name=validating.pods-eviction.kaito.sh
+// +kubebuilder:rbac:groups=kaito.sh,resources=migrations,verbs=create;list;watch;get
+
+func (w *EvictionWebhook) Register(_ context.Context, mgr manager.Manager) error {
+	mgr.GetWebhookServer().Register(webhookPath, &webhook.Admission{Handler: w})
+	return nil
+}
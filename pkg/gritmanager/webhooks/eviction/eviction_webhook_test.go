@@ -0,0 +1,58 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package eviction
+
+import (
+	"net/http"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+func TestEvictionResponse(t *testing.T) {
+	testcases := map[v1alpha1.MigrationPhase]bool{
+		"":                              false,
+		v1alpha1.MigrationCheckpointing: false,
+		v1alpha1.MigrationRemovingPod:   true,
+		v1alpha1.Migrated:               true,
+		v1alpha1.MigrationFailed:        true,
+	}
+
+	for phase, expectAllowed := range testcases {
+		t.Run(string(phase), func(t *testing.T) {
+			migration := &v1alpha1.Migration{Status: v1alpha1.MigrationStatus{Phase: phase}}
+			resp := evictionResponse(migration)
+			if resp.Allowed != expectAllowed {
+				t.Fatalf("expected allowed %v, got %v", expectAllowed, resp.Allowed)
+			}
+			if !resp.Allowed && resp.Result.Code != http.StatusTooManyRequests {
+				t.Fatalf("expected denied with code 429, got %d", resp.Result.Code)
+			}
+		})
+	}
+}
+
+func TestNewMigration(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "train-0", Namespace: "default", UID: "uid-1"}}
+
+	t.Run("pod is evicted by default", func(t *testing.T) {
+		migration, err := newMigration(pod, `{"volumeClaim":{"claimName":"checkpoint-pvc"},"compression":{"level":3}}`)
+		if err != nil {
+			t.Fatalf("failed to create migration, %v", err)
+		}
+		if migration.Spec.PodName != "train-0" || migration.Spec.PodRemovalPolicy != v1alpha1.PodRemovalEvict ||
+			migration.Spec.VolumeClaim.ClaimName != "checkpoint-pvc" || migration.Labels[v1alpha1.EvictedPodUIDLabel] != "uid-1" {
+			t.Fatalf("unexpected migration %v", migration)
+		}
+	})
+
+	t.Run("invalid template", func(t *testing.T) {
+		if _, err := newMigration(pod, "checkpoint-pvc"); err == nil {
+			t.Fatalf("expected error for invalid template")
+		}
+	})
+}
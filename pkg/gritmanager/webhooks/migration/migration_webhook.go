@@ -0,0 +1,101 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type MigrationWebhook struct {
+	client.Client
+	clk clock.Clock
+}
+
+func NewMigrationWebhook(clk clock.Clock, client client.Client) *MigrationWebhook {
+	return &MigrationWebhook{
+		Client: client,
+		clk:    clk,
+	}
+}
+
+func (w *MigrationWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	ctx = util.WithWebhookName(ctx, "migration.validate")
+	migration, ok := obj.(*v1alpha1.Migration)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a migration object but got a different type")
+	}
+
+	if len(migration.Spec.PodName) == 0 {
+		return admission.Warnings{}, fmt.Errorf("pod is not specified in migration(%s)", migration.Name)
+	}
+
+	var pod corev1.Pod
+	if err := w.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Spec.PodName}, &pod); err != nil {
+		return admission.Warnings{}, err
+	}
+
+	// related pod resource should be running
+	if pod.Status.Phase != corev1.PodRunning || len(pod.Spec.NodeName) == 0 {
+		return admission.Warnings{}, fmt.Errorf("pod(%s) referenced by migration(%s) is not running", pod.Name, migration.Name)
+	}
+
+	// new pod is recreated by the owner after checkpointed pod is removed
+	if metav1.GetControllerOf(&pod) == nil {
+		return admission.Warnings{}, fmt.Errorf("pod(%s) referenced by migration(%s) has no owner reference", pod.Name, migration.Name)
+	}
+
+	if err := util.ValidateStorage(migration.Spec.VolumeClaim, migration.Spec.ObjectStorage, migration.Spec.Registry); err != nil {
+		return admission.Warnings{}, fmt.Errorf("storage of migration(%s) is invalid, %v", migration.Name, err)
+	}
+
+	if migration.Spec.VolumeClaim != nil {
+		var pvc corev1.PersistentVolumeClaim
+		if err := w.Get(ctx, client.ObjectKey{Namespace: migration.Namespace, Name: migration.Spec.VolumeClaim.ClaimName}, &pvc); err != nil {
+			return admission.Warnings{}, err
+		}
+
+		if pvc.Status.Phase != corev1.ClaimBound {
+			return admission.Warnings{}, fmt.Errorf("pvc(%s) is not bound", migration.Spec.VolumeClaim.ClaimName)
+		}
+	}
+
+	if len(migration.Spec.TargetNodeName) != 0 {
+		var node corev1.Node
+		if err := w.Get(ctx, client.ObjectKey{Name: migration.Spec.TargetNodeName}, &node); err != nil {
+			return admission.Warnings{}, err
+		}
+	}
+
+	return admission.Warnings{}, nil
+}
+
+func (w *MigrationWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+func (w *MigrationWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+// +kubebuilder:webhook:path=/validate-kaito-sh-v1alpha1-migration,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1,groups="kaito.sh",resources=migrations,verbs=create,versions=v1alpha1,name=validating.migrations.kaito.sh
+
+func (w *MigrationWebhook) Register(_ context.Context, mgr manager.Manager) error {
+	return controllerruntime.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.Migration{}).
+		WithValidator(w).
+		Complete()
+}
@@ -0,0 +1,94 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package nodemigration
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type NodeMigrationWebhook struct {
+	client.Client
+	clk clock.Clock
+}
+
+func NewNodeMigrationWebhook(clk clock.Clock, client client.Client) *NodeMigrationWebhook {
+	return &NodeMigrationWebhook{
+		Client: client,
+		clk:    clk,
+	}
+}
+
+func (w *NodeMigrationWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	ctx = util.WithWebhookName(ctx, "nodemigration.validate")
+	nm, ok := obj.(*v1alpha1.NodeMigration)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a node migration object but got a different type")
+	}
+
+	if len(nm.Spec.NodeName) == 0 {
+		return admission.Warnings{}, fmt.Errorf("node is not specified in node migration(%s)", nm.Name)
+	}
+
+	var node corev1.Node
+	if err := w.Get(ctx, client.ObjectKey{Name: nm.Spec.NodeName}, &node); err != nil {
+		return admission.Warnings{}, err
+	}
+
+	if nm.Spec.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(nm.Spec.Selector); err != nil {
+			return admission.Warnings{}, fmt.Errorf("selector of node migration(%s) is invalid, %v", nm.Name, err)
+		}
+	}
+
+	// pvc is validated by Migration in the namespace of each pod.
+	if err := util.ValidateStorage(nm.Spec.VolumeClaim, nm.Spec.ObjectStorage, nm.Spec.Registry); err != nil {
+		return admission.Warnings{}, fmt.Errorf("storage of node migration(%s) is invalid, %v", nm.Name, err)
+	}
+
+	// only one node migration should be in progress for a node, otherwise pods are migrated twice.
+	var nmList v1alpha1.NodeMigrationList
+	if err := w.List(ctx, &nmList); err != nil {
+		return admission.Warnings{}, err
+	}
+	for i := range nmList.Items {
+		if nmList.Items[i].Spec.NodeName == nm.Spec.NodeName && nmList.Items[i].Status.Phase != v1alpha1.NodeMigrationCompleted &&
+			nmList.Items[i].Status.Phase != v1alpha1.NodeMigrationFailed {
+			return admission.Warnings{}, fmt.Errorf("node(%s) is being migrated by node migration(%s)", nm.Spec.NodeName, nmList.Items[i].Name)
+		}
+	}
+
+	return admission.Warnings{}, nil
+}
+
+func (w *NodeMigrationWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+func (w *NodeMigrationWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+// +kubebuilder:webhook:path=/validate-kaito-sh-v1alpha1-nodemigration,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1,groups="kaito.sh",resources=nodemigrations,verbs=create,versions=v1alpha1,name=validating.nodemigrations.kaito.sh
+// +kubebuilder:rbac:groups=kaito.sh,resources=nodemigrations,verbs=list;watch;get
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+
+func (w *NodeMigrationWebhook) Register(_ context.Context, mgr manager.Manager) error {
+	return controllerruntime.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.NodeMigration{}).
+		WithValidator(w).
+		Complete()
+}
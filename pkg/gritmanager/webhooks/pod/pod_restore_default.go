@@ -5,12 +5,16 @@ package pod
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"path/filepath"
 
 	"github.com/samber/lo"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	controllerruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -19,17 +23,20 @@ import (
 	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
 	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
 	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+	"github.com/kaito-project/grit/pkg/gritmanager/podmatch"
 )
 
 type PodRestoreWebhook struct {
 	client.Client
 	agentManager *agentmanager.AgentManager
+	recorder     record.EventRecorder
 }
 
-func NewWebook(client client.Client, agentManager *agentmanager.AgentManager) *PodRestoreWebhook {
+func NewWebook(client client.Client, agentManager *agentmanager.AgentManager, recorder record.EventRecorder) *PodRestoreWebhook {
 	return &PodRestoreWebhook{
 		Client:       client,
 		agentManager: agentManager,
+		recorder:     recorder,
 	}
 }
 
@@ -66,29 +73,7 @@ func (w *PodRestoreWebhook) Default(ctx context.Context, obj runtime.Object) err
 		return nil
 	}
 
-	// check there is any Restore can matchi the pod(PodSpecHash and Owner Reference)
-	var selectedRestore *v1alpha1.Restore
-	podSpecHash := util.ComputeHash(&pod.Spec)
-	for i := range restores {
-		ownerRefIsMatch := false
-		for _, ownerRef := range pod.OwnerReferences {
-			if ownerRef.UID == restores[i].Spec.OwnerRef.UID &&
-				ownerRef.Kind == restores[i].Spec.OwnerRef.Kind &&
-				ownerRef.APIVersion == restores[i].Spec.OwnerRef.APIVersion {
-				ownerRefIsMatch = true
-				break
-			}
-		}
-		if !ownerRefIsMatch {
-			continue
-		}
-
-		log.FromContext(ctx).Info("select pod for restore(owner reference is equal)", "name", pod.Name, "spec", pod.Spec, "restore name", restores[i].Name, "old pod spec hash", restores[i].Annotations[v1alpha1.PodSpecHashLabel], "new pod spec hash", podSpecHash)
-		if restores[i].Annotations[v1alpha1.PodSpecHashLabel] == podSpecHash {
-			selectedRestore = &restores[i]
-			break
-		}
-	}
+	selectedRestore := selectRestore(ctx, pod, restores)
 
 	if selectedRestore == nil {
 		return nil
@@ -111,12 +96,99 @@ func (w *PodRestoreWebhook) Default(ctx context.Context, obj runtime.Object) err
 	}
 	pod.Annotations[v1alpha1.CheckpointDataPathLabel] = filepath.Join(w.agentManager.GetHostPath(), selectedRestore.Namespace, selectedRestore.Spec.CheckpointName)
 	pod.Annotations[v1alpha1.RestoreNameLabel] = selectedRestore.Name
+	if containers, ok := selectedRestore.Annotations[v1alpha1.CheckpointContainersAnnotation]; ok {
+		pod.Annotations[v1alpha1.CheckpointContainersAnnotation] = containers
+	}
+	// containerd shim adds spans of restoring containers into the trace of restore.
+	if traceParent, ok := selectedRestore.Annotations[v1alpha1.TraceParentAnnotation]; ok {
+		pod.Annotations[v1alpha1.TraceParentAnnotation] = traceParent
+	}
+	applyNodePlacement(pod, selectedRestore)
 	log.FromContext(ctx).Info("selected pod for restore successfully", "namespace", pod.Namespace, "pod name", pod.Name, "restore name", selectedRestore.Name)
+	// pod name maybe is empty in the pod create webhook, so the event is only recorded on restore.
+	w.recorder.Eventf(selectedRestore, corev1.EventTypeNormal, "RestorationPodMatched", "new pod(%s) is matched by owner and identity, it will be restored from checkpoint(%s)", lo.CoalesceOrEmpty(pod.Name, pod.GenerateName), selectedRestore.Spec.CheckpointName)
 
 	return nil
 }
 
-// +kubebuilder:webhook:path=/mutate-core-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,admissionReviewVersions=v1,groups="",resources=pods,verbs=create,versions=v1,name=mutating.pods.k8s.io
+// selectRestore returns the Restore which matches the pod(owner, identity within owner and PodSpecHash). owner and
+// identity are matched by the strategy for the owner of pod, like the ordinal for StatefulSet pod.
+func selectRestore(ctx context.Context, pod *corev1.Pod, restores []v1alpha1.Restore) *v1alpha1.Restore {
+	strategy := podmatch.For(pod)
+	podSpecHash := util.ComputeHash(&pod.Spec)
+	for i := range restores {
+		var identity map[string]string
+		if data, ok := restores[i].Annotations[v1alpha1.PodIdentityAnnotation]; ok {
+			if err := json.Unmarshal([]byte(data), &identity); err != nil {
+				log.FromContext(ctx).Error(err, "invalid pod identity of restore", "restore name", restores[i].Name)
+				continue
+			}
+		}
+		if !strategy.OwnerMatches(&restores[i].Spec.OwnerRef, pod) || !maps.Equal(strategy.Identity(pod), identity) {
+			continue
+		}
+
+		log.FromContext(ctx).Info("select pod for restore(owner and identity are equal)", "name", pod.Name, "strategy", strategy.Name(), "restore name", restores[i].Name, "old pod spec hash", restores[i].Annotations[v1alpha1.PodSpecHashLabel], "new pod spec hash", podSpecHash)
+		if restores[i].Annotations[v1alpha1.PodSpecHashLabel] == podSpecHash {
+			return &restores[i]
+		}
+	}
+	return nil
+}
+
+// applyNodePlacement constrains restoration pod to the target node or nodes specified by Restore.
+func applyNodePlacement(pod *corev1.Pod, restore *v1alpha1.Restore) {
+	if len(restore.Spec.NodeSelector) != 0 {
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = make(map[string]string)
+		}
+		for k, v := range restore.Spec.NodeSelector {
+			pod.Spec.NodeSelector[k] = v
+		}
+	}
+
+	if len(restore.Spec.TargetNodeName) == 0 {
+		return
+	}
+
+	// node affinity with metadata.name field is used as daemonset does, so pod is still scheduled by the scheduler.
+	nodeSelectorTerm := corev1.NodeSelectorTerm{
+		MatchFields: []corev1.NodeSelectorRequirement{
+			{
+				Key:      metav1.ObjectNameField,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{restore.Spec.TargetNodeName},
+			},
+		},
+	}
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	nodeAffinity := pod.Spec.Affinity.NodeAffinity
+	if nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{nodeSelectorTerm},
+		}
+		return
+	}
+
+	// node selector terms are ORed, so the requirement should be added into each term.
+	terms := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) == 0 {
+		nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = []corev1.NodeSelectorTerm{nodeSelectorTerm}
+		return
+	}
+	for i := range terms {
+		terms[i].MatchFields = append(terms[i].MatchFields, nodeSelectorTerm.MatchFields...)
+	}
+}
+
+// labels of pods managed by CRD owners(like LeaderWorkerSet) may be added by their own webhooks, so this webhook is
+// reinvoked for matching restoration pod with these labels.
+// +kubebuilder:webhook:path=/mutate-core-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,admissionReviewVersions=v1,groups="",resources=pods,verbs=create,versions=v1,name=mutating.pods.k8s.io,reinvocationPolicy=IfNeeded
 // +kubebuilder:rbac:groups=kaito.sh,resources=restores,verbs=patch
 
 func (w *PodRestoreWebhook) Register(_ context.Context, mgr manager.Manager) error {
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package pod
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+)
+
+func TestApplyNodePlacement(t *testing.T) {
+	t.Run("node selector is merged", func(t *testing.T) {
+		pod := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{"gpu": "a100"}}}
+		restore := &v1alpha1.Restore{Spec: v1alpha1.RestoreSpec{NodeSelector: map[string]string{"zone": "1"}}}
+
+		applyNodePlacement(pod, restore)
+		if len(pod.Spec.NodeSelector) != 2 || pod.Spec.NodeSelector["zone"] != "1" || pod.Spec.NodeSelector["gpu"] != "a100" {
+			t.Fatalf("expected merged node selector, got %v", pod.Spec.NodeSelector)
+		}
+		if pod.Spec.Affinity != nil {
+			t.Fatalf("expected no affinity, got %v", pod.Spec.Affinity)
+		}
+	})
+
+	t.Run("target node is added into pod without affinity", func(t *testing.T) {
+		pod := &corev1.Pod{}
+		restore := &v1alpha1.Restore{Spec: v1alpha1.RestoreSpec{TargetNodeName: "node-1"}}
+
+		applyNodePlacement(pod, restore)
+		terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		if len(terms) != 1 || len(terms[0].MatchFields) != 1 || terms[0].MatchFields[0].Values[0] != "node-1" {
+			t.Fatalf("expected one term for target node, got %v", terms)
+		}
+	})
+
+	t.Run("target node is added into each existing term", func(t *testing.T) {
+		pod := &corev1.Pod{
+			Spec: corev1.PodSpec{
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpu", Operator: corev1.NodeSelectorOpExists}}},
+								{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "tpu", Operator: corev1.NodeSelectorOpExists}}},
+							},
+						},
+					},
+				},
+			},
+		}
+		restore := &v1alpha1.Restore{Spec: v1alpha1.RestoreSpec{TargetNodeName: "node-1"}}
+
+		applyNodePlacement(pod, restore)
+		terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		for i := range terms {
+			if len(terms[i].MatchExpressions) != 1 || len(terms[i].MatchFields) != 1 || terms[i].MatchFields[0].Values[0] != "node-1" {
+				t.Fatalf("expected target node in term %d, got %v", i, terms[i])
+			}
+		}
+	})
+}
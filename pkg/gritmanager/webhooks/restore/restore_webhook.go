@@ -5,7 +5,9 @@ package restore
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/clock"
@@ -47,6 +49,16 @@ func (w *RestoreWebhook) Default(ctx context.Context, obj runtime.Object) error
 	}
 
 	restore.Annotations[v1alpha1.PodSpecHashLabel] = ckpt.Status.PodSpecHash
+	if len(ckpt.Status.PodIdentity) != 0 {
+		identity, err := json.Marshal(ckpt.Status.PodIdentity)
+		if err != nil {
+			return err
+		}
+		restore.Annotations[v1alpha1.PodIdentityAnnotation] = string(identity)
+	}
+	if len(ckpt.Spec.Containers) != 0 {
+		restore.Annotations[v1alpha1.CheckpointContainersAnnotation] = strings.Join(ckpt.Spec.Containers, ",")
+	}
 	return nil
 }
 
@@ -67,9 +79,7 @@ func (w *RestoreWebhook) ValidateCreate(ctx context.Context, obj runtime.Object)
 	}
 
 	// related checkpoint resource should has completed checkpoint process
-	if ckpt.Status.Phase != v1alpha1.Checkpointed &&
-		ckpt.Status.Phase != v1alpha1.AutoMigrationSubmitting &&
-		ckpt.Status.Phase != v1alpha1.AutoMigrationSubmitted {
+	if ckpt.Status.Phase != v1alpha1.Checkpointed {
 		return admission.Warnings{}, fmt.Errorf("restore(%s) referenced checkpoint(%s) has not completed checkpoint process", restore.Name, ckpt.Name)
 	}
 
@@ -0,0 +1,72 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package restoregroup
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/clock"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/kaito-project/grit/pkg/apis/v1alpha1"
+	"github.com/kaito-project/grit/pkg/gritmanager/controllers/util"
+)
+
+type RestoreGroupWebhook struct {
+	client.Client
+	clk clock.Clock
+}
+
+func NewRestoreGroupWebhook(clk clock.Clock, client client.Client) *RestoreGroupWebhook {
+	return &RestoreGroupWebhook{
+		Client: client,
+		clk:    clk,
+	}
+}
+
+func (w *RestoreGroupWebhook) ValidateCreate(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	ctx = util.WithWebhookName(ctx, "restoregroup.validate")
+	restoreGroup, ok := obj.(*v1alpha1.RestoreGroup)
+	if !ok {
+		return admission.Warnings{}, fmt.Errorf("expected a restore group object but got a different type")
+	}
+
+	if len(restoreGroup.Spec.CheckpointGroupName) == 0 {
+		return admission.Warnings{}, fmt.Errorf("checkpoint group is not specified in restore group(%s)", restoreGroup.Name)
+	}
+
+	var group v1alpha1.CheckpointGroup
+	if err := w.Get(ctx, client.ObjectKey{Namespace: restoreGroup.Namespace, Name: restoreGroup.Spec.CheckpointGroupName}, &group); err != nil {
+		return admission.Warnings{}, err
+	}
+
+	// related checkpoint group should has completed checkpoint process
+	if group.Status.Phase != v1alpha1.CheckpointGroupCheckpointed {
+		return admission.Warnings{}, fmt.Errorf("restore group(%s) referenced checkpoint group(%s) has not completed checkpoint process", restoreGroup.Name, group.Name)
+	}
+
+	return admission.Warnings{}, nil
+}
+
+func (w *RestoreGroupWebhook) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+func (w *RestoreGroupWebhook) ValidateDelete(ctx context.Context, obj runtime.Object) (warnings admission.Warnings, err error) {
+	return admission.Warnings{}, nil
+}
+
+// +kubebuilder:webhook:path=/validate-kaito-sh-v1alpha1-restoregroup,mutating=false,failurePolicy=fail,sideEffects=None,admissionReviewVersions=v1,groups="kaito.sh",resources=restoregroups,verbs=create,versions=v1alpha1,name=validating.restoregroups.kaito.sh
+
+func (w *RestoreGroupWebhook) Register(_ context.Context, mgr manager.Manager) error {
+	return controllerruntime.NewWebhookManagedBy(mgr).
+		For(&v1alpha1.RestoreGroup{}).
+		WithValidator(w).
+		Complete()
+}
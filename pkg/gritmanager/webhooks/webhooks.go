@@ -10,15 +10,27 @@ import (
 
 	"github.com/kaito-project/grit/pkg/gritmanager/agentmanager"
 	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/checkpoint"
+	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/checkpointgroup"
+	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/checkpointschedule"
+	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/eviction"
+	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/migration"
+	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/nodemigration"
 	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/pod"
 	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/restore"
+	"github.com/kaito-project/grit/pkg/gritmanager/webhooks/restoregroup"
 )
 
 func NewWebhooks(mgr manager.Manager, clk clock.Clock, agentManager *agentmanager.AgentManager) []controller.Controller {
 
 	return []controller.Controller{
-		pod.NewWebook(mgr.GetClient(), agentManager),
+		pod.NewWebook(mgr.GetClient(), agentManager, mgr.GetEventRecorderFor("grit-manager")),
 		checkpoint.NewCheckpointWebhook(clk, mgr.GetClient()),
 		restore.NewRestoreWebhook(clk, mgr.GetClient()),
+		checkpointschedule.NewCheckpointScheduleWebhook(clk, mgr.GetClient()),
+		checkpointgroup.NewCheckpointGroupWebhook(clk, mgr.GetClient()),
+		restoregroup.NewRestoreGroupWebhook(clk, mgr.GetClient()),
+		migration.NewMigrationWebhook(clk, mgr.GetClient()),
+		eviction.NewEvictionWebhook(mgr.GetClient()),
+		nodemigration.NewNodeMigrationWebhook(clk, mgr.GetClient()),
 	}
 }
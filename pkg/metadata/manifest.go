@@ -0,0 +1,191 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFile records size and digest of all checkpointed data files, it's generated by checkpoint agent in the root
+// of checkpointed data dir, and checkpointed data is verified against it by restore agent after downloading.
+const ManifestFile = "manifest.json"
+
+// Manifest is the integrity manifest of checkpointed data.
+type Manifest struct {
+	Files []ManifestEntry `json:"files"`
+}
+
+// ManifestEntry describes a regular file or a symlink in checkpointed data.
+type ManifestEntry struct {
+	// Path is the slash separated path relative to the root of checkpointed data dir.
+	Path string `json:"path"`
+	// Size is the size in bytes of regular file.
+	Size int64 `json:"size,omitempty"`
+	// Digest is the sha256 digest of regular file.
+	Digest string `json:"digest,omitempty"`
+	// LinkTarget is the target of symlink, like the link to parent images of incremental checkpoint.
+	LinkTarget string `json:"linkTarget,omitempty"`
+}
+
+// manifestExcludedFiles are not part of checkpointed data, they are only used for coordinating agents.
+var manifestExcludedFiles = map[string]bool{
+	ManifestFile:            true,
+	DownloadSentinelFile:    true,
+	GroupFrozenSentinelFile: true,
+}
+
+// GenerateManifest walks through dir and computes the integrity manifest of all files in it.
+func GenerateManifest(dir string) (*Manifest, error) {
+	manifest := &Manifest{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if manifestExcludedFiles[relPath] {
+			return nil
+		}
+
+		entry := ManifestEntry{Path: filepath.ToSlash(relPath)}
+		switch {
+		case d.Type()&os.ModeSymlink != 0:
+			if entry.LinkTarget, err = os.Readlink(path); err != nil {
+				return err
+			}
+		case d.Type().IsRegular():
+			if entry.Size, entry.Digest, err = fileDigest(path); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+		manifest.Files = append(manifest.Files, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		return manifest.Files[i].Path < manifest.Files[j].Path
+	})
+	return manifest, nil
+}
+
+// WriteManifest generates the integrity manifest of dir and writes it into ManifestFile in dir.
+func WriteManifest(dir string) error {
+	manifest, err := GenerateManifest(dir)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, ManifestFile), data, 0644)
+}
+
+// VerifyManifest verifies all files in dir against ManifestFile in dir, error is returned if the manifest is missing,
+// or any file is missing, truncated or corrupted.
+func VerifyManifest(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return fmt.Errorf("failed to read manifest of %s: %w", dir, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("invalid manifest of %s: %w", dir, err)
+	}
+
+	for _, entry := range manifest.Files {
+		path := filepath.Join(dir, filepath.FromSlash(entry.Path))
+		if len(entry.LinkTarget) != 0 {
+			if target, err := os.Readlink(path); err != nil {
+				return fmt.Errorf("failed to read symlink %s: %w", entry.Path, err)
+			} else if target != entry.LinkTarget {
+				return fmt.Errorf("symlink %s points to %s, expected %s", entry.Path, target, entry.LinkTarget)
+			}
+			continue
+		}
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat file %s: %w", entry.Path, err)
+		} else if !info.Mode().IsRegular() {
+			return fmt.Errorf("file %s is not a regular file", entry.Path)
+		} else if info.Size() != entry.Size {
+			return fmt.Errorf("size of file %s is %d, expected %d", entry.Path, info.Size(), entry.Size)
+		}
+
+		if _, digest, err := fileDigest(path); err != nil {
+			return fmt.Errorf("failed to compute digest of file %s: %w", entry.Path, err)
+		} else if digest != entry.Digest {
+			return fmt.Errorf("digest of file %s is %s, expected %s", entry.Path, digest, entry.Digest)
+		}
+	}
+	return nil
+}
+
+// WriteDownloadState marks checkpointed data in dir as verified, the marker holds the digest of the manifest which the
+// data has been verified against, so the marker of previously downloaded data is not mistaken for the current data.
+func WriteDownloadState(dir string) error {
+	_, digest, err := fileDigest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, DownloadSentinelFile), []byte(digest+"\n"), 0644)
+}
+
+// RemoveDownloadState removes the verified marker of dir, it's called before downloading checkpointed data again.
+func RemoveDownloadState(dir string) error {
+	if err := os.Remove(filepath.Join(dir, DownloadSentinelFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// ValidateDownloadState checks that checkpointed data in dir has been verified against its current manifest.
+func ValidateDownloadState(dir string) error {
+	data, err := os.ReadFile(filepath.Join(dir, DownloadSentinelFile))
+	if err != nil {
+		return fmt.Errorf("checkpointed data in %s has not been verified: %w", dir, err)
+	}
+	_, digest, err := fileDigest(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return fmt.Errorf("failed to compute digest of manifest in %s: %w", dir, err)
+	}
+	if verified := strings.TrimSpace(string(data)); verified != digest {
+		return fmt.Errorf("checkpointed data in %s is verified against manifest %s, but current manifest is %s", dir, verified, digest)
+	}
+	return nil
+}
+
+func fileDigest(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyManifest(t *testing.T) {
+	testcases := map[string]struct {
+		corrupt     func(dir string)
+		expectError bool
+	}{
+		"intact data": {
+			corrupt:     func(dir string) {},
+			expectError: false,
+		},
+		"truncated file": {
+			corrupt: func(dir string) {
+				os.WriteFile(filepath.Join(dir, "container", "checkpoint", "pages-1.img"), []byte("pag"), 0644)
+			},
+			expectError: true,
+		},
+		"corrupted file": {
+			corrupt: func(dir string) {
+				os.WriteFile(filepath.Join(dir, "container", "checkpoint", "pages-1.img"), []byte("PAGES"), 0644)
+			},
+			expectError: true,
+		},
+		"missing file": {
+			corrupt: func(dir string) {
+				os.Remove(filepath.Join(dir, "container", ContainerLogFile))
+			},
+			expectError: true,
+		},
+		"changed symlink": {
+			corrupt: func(dir string) {
+				os.Remove(filepath.Join(dir, "container", "checkpoint", "parent"))
+				os.Symlink("../../other/container/checkpoint", filepath.Join(dir, "container", "checkpoint", "parent"))
+			},
+			expectError: true,
+		},
+		"missing manifest": {
+			corrupt: func(dir string) {
+				os.Remove(filepath.Join(dir, ManifestFile))
+			},
+			expectError: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			os.MkdirAll(filepath.Join(dir, "container", "checkpoint"), 0755)
+			os.WriteFile(filepath.Join(dir, "container", "checkpoint", "pages-1.img"), []byte("pages"), 0644)
+			os.WriteFile(filepath.Join(dir, "container", ContainerLogFile), []byte("log"), 0644)
+			os.Symlink("../../parent/container/checkpoint", filepath.Join(dir, "container", "checkpoint", "parent"))
+			if err := WriteManifest(dir); err != nil {
+				t.Fatalf("failed to write manifest, %v", err)
+			}
+			// sentinel files are not part of checkpointed data
+			os.WriteFile(filepath.Join(dir, DownloadSentinelFile), []byte("Transfer Completed\n"), 0644)
+
+			tc.corrupt(dir)
+			if err := VerifyManifest(dir); (err != nil) != tc.expectError {
+				t.Fatalf("expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestDownloadState(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "pages-1.img"), []byte("pages"), 0644)
+	if err := WriteManifest(dir); err != nil {
+		t.Fatalf("failed to write manifest, %v", err)
+	}
+
+	if err := ValidateDownloadState(dir); err == nil {
+		t.Fatalf("expected error for data which has not been verified")
+	}
+	if err := WriteDownloadState(dir); err != nil {
+		t.Fatalf("failed to write download state, %v", err)
+	}
+	if err := ValidateDownloadState(dir); err != nil {
+		t.Fatalf("expected verified data, got %v", err)
+	}
+
+	// data of another checkpoint is downloaded into the same dir, the marker of previous data is stale.
+	os.WriteFile(filepath.Join(dir, "pages-2.img"), []byte("pages"), 0644)
+	if err := WriteManifest(dir); err != nil {
+		t.Fatalf("failed to write manifest, %v", err)
+	}
+	if err := ValidateDownloadState(dir); err == nil {
+		t.Fatalf("expected error for stale download state")
+	}
+
+	if err := RemoveDownloadState(dir); err != nil {
+		t.Fatalf("failed to remove download state, %v", err)
+	}
+	if err := RemoveDownloadState(dir); err != nil {
+		t.Fatalf("expected no error when download state doesn't exist, got %v", err)
+	}
+}
@@ -5,6 +5,30 @@
 package metadata
 
 const (
-	ContainerLogFile     = "container.log"
+	ContainerLogFile = "container.log"
+	// DownloadSentinelFile is created by restore agent only after downloaded data has been verified against the
+	// integrity manifest, it holds the digest of the manifest, and the shim refuses to restore container from checkpointed
+	// data without it or with a different manifest.
 	DownloadSentinelFile = "download-state"
+	// GroupFrozenSentinelFile is written in the storage volume by each member of checkpoint group, it holds the current
+	// attempt of the member when it's frozen, and the attempts of other members which have been seen frozen by it.
+	GroupFrozenSentinelFile = "group-frozen"
+	// PackedStreamFile is the tar stream of all checkpointed data, it's stored in the storage instead of separate files
+	// when compression or encryption is enabled, and the file name is suffixed by each applied transformation in order,
+	// like checkpoint.tar.zst.enc for the stream which is compressed and then encrypted.
+	PackedStreamFile       = "checkpoint.tar"
+	CompressedStreamSuffix = ".zst"
+	EncryptedStreamSuffix  = ".enc"
+
+	// CheckpointArtifactType is the artifact type of OCI artifact which contains checkpointed data, and each top level
+	// entry(like the checkpoint directory of a container) of checkpointed data is packed as a tar layer.
+	CheckpointArtifactType = "application/vnd.grit.checkpoint.v1"
+	// CheckpointLayerMediaType is the media type of each layer in checkpoint artifact.
+	CheckpointLayerMediaType = "application/vnd.grit.checkpoint.layer.v1.tar"
+	// CheckpointLayerCompressedSuffix and CheckpointLayerEncryptedSuffix are appended to the media type of layer which
+	// is zstd compressed or encrypted, like application/vnd.grit.checkpoint.layer.v1.tar+zstd+encrypted.
+	CheckpointLayerCompressedSuffix = "+zstd"
+	CheckpointLayerEncryptedSuffix  = "+encrypted"
+	// CheckpointArtifactTag is the tag of checkpoint artifact, each checkpoint is pushed into its own repository.
+	CheckpointArtifactTag = "latest"
 )
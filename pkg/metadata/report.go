@@ -0,0 +1,95 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package metadata
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+const (
+	// TerminationMessagePath is the default termination message path of container, grit agent writes AgentReport into it,
+	// and grit manager reads the report from the status of grit agent pod because grit agent has no access to kube-apiserver.
+	TerminationMessagePath = "/dev/termination-log"
+	// PermanentFailureExitCode is the exit code of grit agent when it fails on a PermanentError, grit agent job is failed
+	// at once by its pod failure policy instead of retrying.
+	PermanentFailureExitCode = 42
+)
+
+// PermanentError is an error which can't be recovered by retrying grit agent, like criu dump failures or corrupted
+// checkpointed data.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks err as a PermanentError.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent returns whether err or any error wrapped by it is a PermanentError.
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+// AgentReport is reported by grit agent when the action is completed, or when restore fails on data verification, or
+// when checkpoint fails. sizes and durations are only used for metrics of grit manager.
+type AgentReport struct {
+	// UncompressedSize is the total size in bytes of checkpointed data files.
+	UncompressedSize int64 `json:"uncompressedSize,omitempty"`
+	// CompressedSize is the size in bytes of compressed data in the storage, it's zero when compression is disabled.
+	CompressedSize int64 `json:"compressedSize,omitempty"`
+	// DumpedSize is the total size in bytes of criu images, and RootfsDiffSize is the total size in bytes of rootfs diff
+	// tarballs of checkpointed containers.
+	DumpedSize     int64 `json:"dumpedSize,omitempty"`
+	RootfsDiffSize int64 `json:"rootfsDiffSize,omitempty"`
+	// TransferredSize is the size in bytes of data uploaded into the storage by checkpoint agent, or downloaded from the
+	// storage by restore agent.
+	TransferredSize int64 `json:"transferredSize,omitempty"`
+	// DumpSeconds is the time spent on checkpointing containers, and TransferSeconds is the time spent on data transfer.
+	DumpSeconds     float64 `json:"dumpSeconds,omitempty"`
+	TransferSeconds float64 `json:"transferSeconds,omitempty"`
+	// VerificationError is reported by restore agent when downloaded data doesn't match the integrity manifest.
+	VerificationError string `json:"verificationError,omitempty"`
+	// DeadlineExceeded is reported by checkpoint agent when checkpoint is not completed before the deadline.
+	DeadlineExceeded bool `json:"deadlineExceeded,omitempty"`
+	// RolledBack is reported by checkpoint agent when checkpoint fails and all side effects applied on the pod(like
+	// locked cuda state and private mounts) have been undone, otherwise RollbackError reports the steps which failed.
+	RolledBack    bool   `json:"rolledBack,omitempty"`
+	RollbackError string `json:"rollbackError,omitempty"`
+	// StoppedContainers is reported by checkpoint agent when checkpoint fails after some containers have been dumped and
+	// stopped by criu, these containers can't be rolled back, so the pod is only partially rolled back.
+	StoppedContainers []string `json:"stoppedContainers,omitempty"`
+}
+
+// WriteAgentReport writes report into the termination message file.
+func WriteAgentReport(file string, report *AgentReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// ParseAgentReport parses report from the termination message of grit agent container.
+func ParseAgentReport(message string) (*AgentReport, error) {
+	var report AgentReport
+	if err := json.Unmarshal([]byte(message), &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
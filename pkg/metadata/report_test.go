@@ -0,0 +1,42 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package metadata
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsPermanent(t *testing.T) {
+	testcases := map[string]struct {
+		err      error
+		expected bool
+	}{
+		"nil error": {
+			err:      nil,
+			expected: false,
+		},
+		"transient error": {
+			err:      errors.New("connection reset by peer"),
+			expected: false,
+		},
+		"permanent error": {
+			err:      Permanent(errors.New("criu dump failed")),
+			expected: true,
+		},
+		"wrapped permanent error": {
+			err:      fmt.Errorf("failed to checkpoint container: %w", Permanent(errors.New("criu dump failed"))),
+			expected: true,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			if got := IsPermanent(tc.err); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
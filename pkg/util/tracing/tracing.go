@@ -0,0 +1,76 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// TraceParentEnv is the W3C traceparent of Checkpoint or Restore for grit agent job, so spans of grit agent are
+	// added into the trace which is started by grit manager.
+	TraceParentEnv = "TRACEPARENT"
+	// EndpointEnv is the OTLP gRPC endpoint which grit agent exports spans to.
+	EndpointEnv = "OTEL_EXPORTER_OTLP_ENDPOINT"
+)
+
+var propagator = propagation.TraceContext{}
+
+// Setup sets the global tracer provider which exports spans of service to OTLP gRPC endpoint, like
+// http://otel-collector.observability:4317. tracing is disabled when endpoint is empty. the returned function flushes
+// spans which are not exported yet, and it should be called before the process exits.
+func Setup(ctx context.Context, service, endpoint string) (func(context.Context) error, error) {
+	if len(endpoint) == 0 {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(service))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return provider.Shutdown, nil
+}
+
+// ContextWithTraceParent returns a context whose remote parent span is the W3C traceparent, ctx is returned as it is
+// when traceParent is invalid.
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if len(traceParent) == 0 {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{"traceparent": traceParent})
+}
+
+// TraceParent returns the W3C traceparent of the span in ctx, it's empty when ctx has no valid span.
+func TraceParent(ctx context.Context) string {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	return carrier.Get("traceparent")
+}
+
+// EndSpan ends span, and span is marked as error when err is not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,38 @@
+// Copyright (c) Microsoft Corporation.
+// Licensed under the MIT license.
+
+package tracing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTraceParent(t *testing.T) {
+	testcases := map[string]struct {
+		traceParent string
+		expected    string
+	}{
+		"valid trace parent": {
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expected:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		"empty trace parent": {
+			traceParent: "",
+			expected:    "",
+		},
+		"invalid trace parent": {
+			traceParent: "00-invalid",
+			expected:    "",
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			ctx := ContextWithTraceParent(context.Background(), tc.traceParent)
+			if got := TraceParent(ctx); got != tc.expected {
+				t.Errorf("expected trace parent %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}